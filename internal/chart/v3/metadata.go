@@ -44,6 +44,32 @@ func (m *Maintainer) Validate() error {
 	return nil
 }
 
+// Plugin describes a plugin a chart requires to be rendered.
+type Plugin struct {
+	// Name is the name of the plugin
+	Name string `json:"name"`
+	// Version is an optional SemVer constraint on the version of the plugin
+	Version string `json:"version,omitempty"`
+}
+
+// Validate checks valid data and sanitizes string characters.
+func (p *Plugin) Validate() error {
+	if p == nil {
+		return ValidationError("plugins must not contain empty or null nodes")
+	}
+	p.Name = sanitizeString(p.Name)
+	p.Version = sanitizeString(p.Version)
+	if p.Name == "" {
+		return ValidationError("plugins must have a name")
+	}
+	if p.Version != "" {
+		if _, err := semver.NewConstraint(p.Version); err != nil {
+			return ValidationErrorf("plugin %q version %q is invalid", p.Name, p.Version)
+		}
+	}
+	return nil
+}
+
 // Metadata for a Chart file. This models the structure of a Chart.yaml file.
 type Metadata struct {
 	// The name of the chart. Required.
@@ -79,6 +105,9 @@ type Metadata struct {
 	KubeVersion string `json:"kubeVersion,omitempty"`
 	// Dependencies are a list of dependencies for a chart.
 	Dependencies []*Dependency `json:"dependencies,omitempty"`
+	// Plugins are the templatefuncs/v1 plugins providing template functions
+	// used by the templates of the chart.
+	Plugins []*Plugin `json:"plugins,omitempty"`
 	// Specifies the chart type: application or library
 	Type string `json:"type,omitempty"`
 }
@@ -148,6 +177,17 @@ func (md *Metadata) Validate() error {
 		}
 		dependencies[key] = dependency
 	}
+
+	plugins := map[string]bool{}
+	for _, plugin := range md.Plugins {
+		if err := plugin.Validate(); err != nil {
+			return err
+		}
+		if plugins[plugin.Name] {
+			return ValidationErrorf("more than one plugin with name %q", plugin.Name)
+		}
+		plugins[plugin.Name] = true
+	}
 	return nil
 }
 
@@ -17,6 +17,7 @@ package plugin
 
 import (
 	"fmt"
+	"regexp"
 
 	"go.yaml.in/yaml/v3"
 )
@@ -50,6 +51,16 @@ type ConfigGetter struct {
 // there are no runtime-independent configurations for postrenderer/v1 plugin type
 type ConfigPostrenderer struct{}
 
+// ConfigTemplateFuncs represents the configuration for template function plugins
+type ConfigTemplateFuncs struct {
+	// Functions are the names of the template functions provided by the plugin
+	Functions []string `yaml:"functions"`
+}
+
+// ConfigLint represents the configuration for lint plugins
+// there are no runtime-independent configurations for lint/v1 plugin type
+type ConfigLint struct{}
+
 func (c *ConfigCLI) Validate() error {
 	// Config validation for CLI plugins
 	return nil
@@ -72,6 +83,31 @@ func (c *ConfigPostrenderer) Validate() error {
 	return nil
 }
 
+func (c *ConfigLint) Validate() error {
+	// Config validation for lint plugins
+	return nil
+}
+
+func (c *ConfigTemplateFuncs) Validate() error {
+	if len(c.Functions) == 0 {
+		return fmt.Errorf("templatefuncs has no functions")
+	}
+	seen := map[string]bool{}
+	for i, function := range c.Functions {
+		if !validFunctionName.MatchString(function) {
+			return fmt.Errorf("templatefuncs has invalid function name %q at index %d", function, i)
+		}
+		if seen[function] {
+			return fmt.Errorf("templatefuncs has duplicate function %q", function)
+		}
+		seen[function] = true
+	}
+	return nil
+}
+
+// validFunctionName matches the names Go templates accept for functions
+var validFunctionName = regexp.MustCompile(`^[\p{L}_][\p{L}\p{N}_]*$`)
+
 func remarshalConfig[T Config](configData map[string]any) (Config, error) {
 	data, err := yaml.Marshal(configData)
 	if err != nil {
@@ -31,7 +31,7 @@ type Metadata struct {
 	// Name is the name of the plugin
 	Name string
 
-	// Type of plugin (eg, cli/v1, getter/v1, postrenderer/v1)
+	// Type of plugin (eg, cli/v1, getter/v1, postrenderer/v1, lint/v1)
 	Type string
 
 	// Runtime specifies the runtime type (subprocess, wasm)
@@ -69,6 +69,11 @@ func (m Metadata) Validate() error {
 		errs = append(errs, fmt.Errorf("empty runtime field"))
 	}
 
+	// Template functions are run in the Wasm sandbox only
+	if m.Type == "templatefuncs/v1" && m.Runtime != "extism/v1" {
+		errs = append(errs, fmt.Errorf("templatefuncs/v1 plugins require the extism/v1 runtime"))
+	}
+
 	if m.Config == nil {
 		errs = append(errs, fmt.Errorf("missing config field"))
 	}
@@ -193,6 +198,10 @@ func convertMetadataConfig(pluginType string, configRaw map[string]any) (Config,
 		config, err = remarshalConfig[*ConfigGetter](configRaw)
 	case "postrenderer/v1":
 		config, err = remarshalConfig[*ConfigPostrenderer](configRaw)
+	case "templatefuncs/v1":
+		config, err = remarshalConfig[*ConfigTemplateFuncs](configRaw)
+	case "lint/v1":
+		config, err = remarshalConfig[*ConfigLint](configRaw)
 	default:
 		return nil, fmt.Errorf("unsupported plugin type: %s", pluginType)
 	}
@@ -139,3 +139,40 @@ func TestMetadataValidateMultipleErrors(t *testing.T) {
 		t.Errorf("expected %d errors, but only found %d in: %v", len(expectedErrors), errorCount, errStr)
 	}
 }
+
+func TestValidateTemplateFuncsMetadata(t *testing.T) {
+	metadata := func(runtime string, functions ...string) Metadata {
+		return Metadata{
+			Name:          "netfuncs",
+			APIVersion:    "v1",
+			Type:          "templatefuncs/v1",
+			Runtime:       runtime,
+			Config:        &ConfigTemplateFuncs{Functions: functions},
+			RuntimeConfig: &RuntimeConfigExtismV1{},
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		metadata  Metadata
+		errString string
+	}{
+		"valid":              {metadata("extism/v1", "cidrHost", "dns_label"), ""},
+		"subprocess runtime": {metadata("subprocess", "cidrHost"), "templatefuncs/v1 plugins require the extism/v1 runtime"},
+		"no functions":       {metadata("extism/v1"), "config validation failed: templatefuncs has no functions"},
+		"invalid function":   {metadata("extism/v1", "cidr-host"), `config validation failed: templatefuncs has invalid function name "cidr-host" at index 0`},
+		"duplicate function": {metadata("extism/v1", "cidrHost", "cidrHost"), `config validation failed: templatefuncs has duplicate function "cidrHost"`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.metadata.Validate()
+			if tc.errString == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errString) {
+				t.Fatalf("expected error %q, got: %v", tc.errString, err)
+			}
+		})
+	}
+}
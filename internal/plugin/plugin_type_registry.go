@@ -89,6 +89,18 @@ var pluginTypes = []pluginTypeMeta{
 		outputType: reflect.TypeOf(schema.OutputMessageGetterV1{}),
 		configType: reflect.TypeOf(ConfigGetter{}),
 	},
+	{
+		pluginType: "templatefuncs/v1",
+		inputType:  reflect.TypeOf(schema.InputMessageTemplateFuncsV1{}),
+		outputType: reflect.TypeOf(schema.OutputMessageTemplateFuncsV1{}),
+		configType: reflect.TypeOf(ConfigTemplateFuncs{}),
+	},
+	{
+		pluginType: "lint/v1",
+		inputType:  reflect.TypeOf(schema.InputMessageLintV1{}),
+		outputType: reflect.TypeOf(schema.OutputMessageLintV1{}),
+		configType: reflect.TypeOf(ConfigLint{}),
+	},
 }
 
 var pluginTypesIndex = func() map[string]*pluginTypeMeta {
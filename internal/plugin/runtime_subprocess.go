@@ -101,7 +101,7 @@ func (r *SubprocessPluginRuntime) Metadata() Metadata {
 	return r.metadata
 }
 
-func (r *SubprocessPluginRuntime) Invoke(_ context.Context, input *Input) (*Output, error) {
+func (r *SubprocessPluginRuntime) Invoke(ctx context.Context, input *Input) (*Output, error) {
 	switch input.Message.(type) {
 	case schema.InputMessageCLIV1:
 		return r.runCLI(input)
@@ -109,6 +109,8 @@ func (r *SubprocessPluginRuntime) Invoke(_ context.Context, input *Input) (*Outp
 		return r.runGetter(input)
 	case schema.InputMessagePostRendererV1:
 		return r.runPostrenderer(input)
+	case schema.InputMessageLintV1:
+		return r.runLint(ctx, input)
 	default:
 		return nil, fmt.Errorf("unsupported subprocess plugin type %q", r.metadata.Type)
 	}
@@ -0,0 +1,114 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"helm.sh/helm/v4/internal/plugin/schema"
+)
+
+// runLint runs a lint plugin command, which reads the input message as JSON
+// from stdin and writes the output message as JSON to stdout. The command is
+// killed when the context is done.
+func (r *SubprocessPluginRuntime) runLint(ctx context.Context, input *Input) (*Output, error) {
+	msg, ok := input.Message.(schema.InputMessageLintV1)
+	if !ok {
+		return nil, fmt.Errorf("plugin %q input message does not implement InputMessageLintV1", r.metadata.Name)
+	}
+
+	// Like SetupPluginEnv, without changing the environment of Helm, which
+	// may run several plugins at the same time.
+	pluginEnv := map[string]string{
+		"HELM_PLUGIN_NAME": r.metadata.Name,
+		"HELM_PLUGIN_DIR":  r.pluginDir,
+	}
+	env := os.Environ()
+	for k, v := range pluginEnv {
+		env = append(env, k+"="+v)
+	}
+
+	cmds := r.RuntimeConfig.PlatformCommands
+	if len(cmds) == 0 && len(r.RuntimeConfig.Command) > 0 {
+		cmds = []PlatformCommand{{Command: r.RuntimeConfig.Command}}
+	}
+	cmds = expandPluginEnv(cmds, pluginEnv)
+
+	command, args, err := PrepareCommands(cmds, true, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare plugin command: %w", err)
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the input of plugin %q: %w", r.metadata.Name, err)
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Env = env
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := executeCmd(cmd, r.metadata.Name); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("plugin %q was stopped: %w", r.metadata.Name, ctx.Err())
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	var out schema.OutputMessageLintV1
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, fmt.Errorf("failed to decode the output of plugin %q: %w", r.metadata.Name, err)
+	}
+	return &Output{
+		Message: out,
+	}, nil
+}
+
+// expandPluginEnv returns a copy of the commands in which the variables of the
+// plugin environment are expanded, leaving the other variables to
+// PrepareCommands.
+func expandPluginEnv(cmds []PlatformCommand, pluginEnv map[string]string) []PlatformCommand {
+	mapping := func(name string) string {
+		if v, ok := pluginEnv[name]; ok {
+			return v
+		}
+		return "${" + name + "}"
+	}
+	out := make([]PlatformCommand, len(cmds))
+	for i, c := range cmds {
+		out[i] = c
+		out[i].Command = os.Expand(c.Command, mapping)
+		out[i].Args = make([]string, len(c.Args))
+		for j, arg := range c.Args {
+			out[i].Args[j] = os.Expand(arg, mapping)
+		}
+	}
+	return out
+}
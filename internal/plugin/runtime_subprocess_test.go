@@ -62,3 +62,25 @@ func TestSetupEnvWithSpace(t *testing.T) {
 		}
 	}
 }
+
+func TestExpandPluginEnv(t *testing.T) {
+	t.Setenv("HELM_PLUGIN_DIR", "elsewhere")
+	t.Setenv("HOME", "/home/ahab")
+
+	cmds := []PlatformCommand{{Command: "$HELM_PLUGIN_DIR/lint.sh", Args: []string{"${HELM_PLUGIN_NAME}", "$HOME"}}}
+	got := expandPluginEnv(cmds, map[string]string{"HELM_PLUGIN_NAME": "pequod", "HELM_PLUGIN_DIR": "plugins/pequod"})
+
+	main, args, err := PrepareCommands(got, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if main != "plugins/pequod/lint.sh" {
+		t.Errorf("Expected the command %q, got %q", "plugins/pequod/lint.sh", main)
+	}
+	if len(args) != 2 || args[0] != "pequod" || args[1] != "/home/ahab" {
+		t.Errorf("Expected the args [pequod /home/ahab], got %q", args)
+	}
+	if cmds[0].Command != "$HELM_PLUGIN_DIR/lint.sh" {
+		t.Error("Expected the commands not to be changed")
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// InputMessageLintV1 implements Input.Message
+type InputMessageLintV1 struct {
+	// Chart is the metadata of the linted chart
+	Chart *chart.Metadata `json:"chart"`
+	// Manifests are the rendered templates of the chart and its subcharts
+	Manifests []LintManifestV1 `json:"manifests"`
+}
+
+// LintManifestV1 is a rendered template
+type LintManifestV1 struct {
+	// Path is the path of the template in the chart, e.g. "templates/deployment.yaml"
+	Path string `json:"path"`
+	// Content is the rendered template
+	Content string `json:"content"`
+}
+
+type OutputMessageLintV1 struct {
+	// Messages are the findings of the plugin
+	Messages []LintMessageV1 `json:"messages"`
+}
+
+// LintMessageV1 is a finding of a lint plugin
+type LintMessageV1 struct {
+	// Severity is one of "info", "warning" or "error"
+	Severity string `json:"severity"`
+	// Path is the path of the file in the chart the finding is about
+	Path string `json:"path,omitempty"`
+	// Line is the line of the finding in the file, starting at 1
+	Line int `json:"line,omitempty"`
+	// Column is the column of the finding in the line, starting at 1
+	Column int `json:"column,omitempty"`
+	// Message describes the finding
+	Message string `json:"message"`
+	// Rule identifies the rule of the plugin that raised the finding. The
+	// rule identifier of the message is "<plugin>/<rule>", or the name of the
+	// plugin when it is empty.
+	Rule string `json:"rule,omitempty"`
+}
@@ -0,0 +1,32 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+// InputMessageTemplateFuncsV1 implements Input.Message
+type InputMessageTemplateFuncsV1 struct {
+	// Function is the name of the template function called
+	Function string `json:"function"`
+	// Args are the arguments the template function is called with
+	Args []any `json:"args"`
+}
+
+type OutputMessageTemplateFuncsV1 struct {
+	// Result is the value returned by the template function
+	Result any `json:"result"`
+	// Error, when set, fails the rendering with this message
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,205 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package sops decrypts values files encrypted with SOPS (https://getsops.io),
+as well as files encrypted with age as a whole.
+
+Decryption happens in memory, the plaintext is never written to disk. SOPS
+documents are decrypted by the SOPS library with the keys it finds the way the
+sops binary does, e.g. the age identities of SOPS_AGE_KEY or
+SOPS_AGE_KEY_FILE, the PGP keyring or a cloud KMS. Files encrypted with age
+as a whole are decrypted with the age identities of the same places, see
+LoadIdentities.
+*/
+package sops
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/getsops/sops/v3/decrypt"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	// AgeKeyEnv is the environment variable holding age identities.
+	AgeKeyEnv = "SOPS_AGE_KEY"
+	// AgeKeyFileEnv is the environment variable pointing to a file of age
+	// identities.
+	AgeKeyFileEnv = "SOPS_AGE_KEY_FILE"
+	// AgeKeyUserConfigPath is the location of the age identities relative to
+	// the user configuration directory, used when neither environment
+	// variable is set.
+	AgeKeyUserConfigPath = "sops/age/keys.txt"
+
+	// metadataKey is the top level key SOPS keeps its metadata under.
+	metadataKey = "sops"
+	// ageMagic starts binary age files.
+	ageMagic = "age-encryption.org/v1\n"
+)
+
+// IsEncrypted reports whether data is a SOPS encrypted YAML or JSON document,
+// or a file encrypted with age.
+func IsEncrypted(data []byte) bool {
+	if isAge(data) {
+		return true
+	}
+	if !bytes.Contains(data, []byte(metadataKey)) {
+		return false
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return false
+	}
+	meta := lookup(doc.Content[0], metadataKey)
+	if meta == nil || meta.Kind != yaml.MappingNode {
+		return false
+	}
+	return lookup(meta, "mac") != nil
+}
+
+// Decrypt decrypts data in memory.
+//
+// Files encrypted with age as a whole are returned as they were before being
+// encrypted. SOPS documents are returned without their metadata, after
+// verifying their MAC, as YAML, or as JSON for JSON documents.
+func Decrypt(data []byte) ([]byte, error) {
+	if isAge(data) {
+		identities, err := LoadIdentities()
+		if err != nil {
+			return nil, err
+		}
+		return DecryptAge(data, identities)
+	}
+
+	format := "yaml"
+	if bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("{")) {
+		format = "json"
+	}
+	out, err := decrypt.Data(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("sops: %w", err)
+	}
+	return out, nil
+}
+
+// DecryptAge decrypts a file encrypted with age as a whole, armored or not,
+// with the given identities.
+func DecryptAge(data []byte, identities []age.Identity) ([]byte, error) {
+	var src io.Reader = bytes.NewReader(bytes.TrimLeft(data, " \t\r\n"))
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte(ageMagic)) {
+		src = armor.NewReader(src)
+	}
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt with age: %w", err)
+	}
+	return io.ReadAll(r)
+}
+
+// LoadIdentities loads the age identities from the SOPS_AGE_KEY and
+// SOPS_AGE_KEY_FILE environment variables and from the sops/age/keys.txt
+// file in the user configuration directory, the same places SOPS looks.
+func LoadIdentities() ([]age.Identity, error) {
+	var identities []age.Identity
+
+	if key, ok := os.LookupEnv(AgeKeyEnv); ok {
+		ids, err := age.ParseIdentities(strings.NewReader(key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", AgeKeyEnv, err)
+		}
+		identities = append(identities, ids...)
+	}
+
+	if file, ok := os.LookupEnv(AgeKeyFileEnv); ok {
+		ids, err := parseIdentitiesFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", AgeKeyFileEnv, err)
+		}
+		identities = append(identities, ids...)
+	}
+
+	defaultFile := ""
+	if dir, err := userConfigDir(); err == nil {
+		defaultFile = filepath.Join(dir, filepath.FromSlash(AgeKeyUserConfigPath))
+		ids, err := parseIdentitiesFile(defaultFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		identities = append(identities, ids...)
+	}
+
+	if len(identities) == 0 {
+		msg := fmt.Sprintf("no age identity found: set %s or %s", AgeKeyEnv, AgeKeyFileEnv)
+		if defaultFile != "" {
+			msg += ", or create " + defaultFile
+		}
+		return nil, errors.New(msg)
+	}
+	return identities, nil
+}
+
+func parseIdentitiesFile(name string) ([]age.Identity, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ids, err := age.ParseIdentities(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return ids, nil
+}
+
+// userConfigDir follows SOPS, which honors XDG_CONFIG_HOME on macOS as well.
+func userConfigDir() (string, error) {
+	if runtime.GOOS == "darwin" {
+		if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+			return dir, nil
+		}
+	}
+	return os.UserConfigDir()
+}
+
+// isAge reports whether data is a file encrypted with age, armored or not.
+func isAge(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return bytes.HasPrefix(trimmed, []byte(armor.Header)) || bytes.HasPrefix(trimmed, []byte(ageMagic))
+}
+
+// lookup returns the value of a key in a mapping node.
+func lookup(n *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
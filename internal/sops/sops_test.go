@@ -0,0 +1,149 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sops
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v3"
+
+	"helm.sh/helm/v4/internal/sops/sopstest"
+)
+
+const secrets = `database:
+    user: admin
+    password: s3cr3t
+    port: 5432
+    ratio: 1.5
+    tls: true
+    empty: ""
+    nothing: null
+hosts:
+    - a.example.com
+    - b.example.com
+`
+
+func TestIsEncrypted(t *testing.T) {
+	recipient := sopstest.GenerateKey(t).Recipient()
+
+	assert.True(t, IsEncrypted(sopstest.Encrypt(t, []byte(secrets), recipient, "")))
+	assert.True(t, IsEncrypted(sopstest.Encrypt(t, []byte(secrets), recipient, "^password$")))
+	assert.True(t, IsEncrypted(sopstest.EncryptAge(t, []byte(secrets), recipient, true)))
+	assert.True(t, IsEncrypted(sopstest.EncryptAge(t, []byte(secrets), recipient, false)))
+	assert.True(t, IsEncrypted([]byte(`{"password": "ENC[...]", "sops": {"mac": "ENC[...]"}}`)))
+	assert.False(t, IsEncrypted([]byte("name: value\n")))
+	assert.False(t, IsEncrypted([]byte("sops: just a value\n")))
+	assert.False(t, IsEncrypted([]byte("- a\n- b\n")))
+	assert.False(t, IsEncrypted(nil))
+}
+
+func TestDecrypt(t *testing.T) {
+	id := sopstest.SetupKey(t)
+
+	out, err := Decrypt(sopstest.Encrypt(t, []byte(secrets), id.Recipient(), ""))
+	require.NoError(t, err)
+
+	var vals map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(out, &vals))
+	assert.Equal(t, map[string]interface{}{
+		"database": map[string]interface{}{
+			"user":     "admin",
+			"password": "s3cr3t",
+			"port":     5432,
+			"ratio":    1.5,
+			"tls":      true,
+			"empty":    "",
+			"nothing":  nil,
+		},
+		"hosts": []interface{}{"a.example.com", "b.example.com"},
+	}, vals)
+}
+
+func TestDecryptMACOnlyEncrypted(t *testing.T) {
+	id := sopstest.SetupKey(t)
+
+	plain := "image:\n    tag: 1.2.3\n    token: abc\n"
+	encrypted := sopstest.Encrypt(t, []byte(plain), id.Recipient(), "^token$")
+	assert.Contains(t, string(encrypted), "tag: 1.2.3")
+	assert.NotContains(t, string(encrypted), "token: abc")
+
+	out, err := Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, plain, string(out))
+}
+
+func TestDecryptAge(t *testing.T) {
+	id := sopstest.SetupKey(t)
+
+	for _, armored := range []bool{true, false} {
+		out, err := Decrypt(sopstest.EncryptAge(t, []byte("greeting: hello\n"), id.Recipient(), armored))
+		require.NoError(t, err)
+		assert.Equal(t, "greeting: hello\n", string(out))
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	id := sopstest.SetupKey(t)
+	encrypted := string(sopstest.Encrypt(t, []byte("name: app\npassword: s3cr3t\n"), id.Recipient(), "^password$"))
+
+	// Moving an encrypted value breaks its authentication.
+	_, err := Decrypt([]byte(strings.Replace(encrypted, "password:", "secret:", 1)))
+	assert.ErrorContains(t, err, "Failed to verify data integrity")
+}
+
+func TestDecryptWrongKey(t *testing.T) {
+	other := sopstest.GenerateKey(t)
+	sopstest.SetupKey(t)
+
+	_, err := Decrypt(sopstest.Encrypt(t, []byte(secrets), other.Recipient(), ""))
+	assert.ErrorContains(t, err, "Error getting data key")
+
+	_, err = Decrypt(sopstest.EncryptAge(t, []byte(secrets), other.Recipient(), true))
+	assert.ErrorContains(t, err, "failed to decrypt with age")
+}
+
+func TestLoadIdentities(t *testing.T) {
+	id := sopstest.SetupKey(t)
+	os.Unsetenv(AgeKeyEnv)
+
+	_, err := LoadIdentities()
+	assert.ErrorContains(t, err, "no age identity found")
+
+	t.Setenv(AgeKeyFileEnv, sopstest.WriteKeyFile(t, id))
+	out, err := Decrypt(sopstest.EncryptAge(t, []byte("greeting: hello\n"), id.Recipient(), true))
+	require.NoError(t, err)
+	assert.Equal(t, "greeting: hello\n", string(out))
+
+	out, err = Decrypt(sopstest.Encrypt(t, []byte("greeting: hello\n"), id.Recipient(), ""))
+	require.NoError(t, err)
+	assert.Equal(t, "greeting: hello\n", string(out))
+
+	t.Setenv(AgeKeyFileEnv, "testdata/missing.txt")
+	_, err = LoadIdentities()
+	assert.ErrorContains(t, err, "failed to read SOPS_AGE_KEY_FILE")
+	os.Unsetenv(AgeKeyFileEnv)
+
+	t.Setenv(AgeKeyEnv, id.String())
+	ids, err := LoadIdentities()
+	require.NoError(t, err)
+	assert.Equal(t, []age.Identity{id}, ids)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package sopstest provides utilities for testing code reading encrypted files.
+
+The keys are generated when the tests run, so no private key is kept in the
+repository.
+*/
+package sopstest
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/aes"
+	sopsage "github.com/getsops/sops/v3/age"
+	"github.com/getsops/sops/v3/config"
+	"github.com/getsops/sops/v3/keyservice"
+	sopsyaml "github.com/getsops/sops/v3/stores/yaml"
+	"github.com/stretchr/testify/require"
+)
+
+// SetupKey generates an age identity and makes it the only one available
+// for the rest of the test, through the SOPS_AGE_KEY environment variable.
+func SetupKey(t *testing.T) *age.X25519Identity {
+	t.Helper()
+	id := GenerateKey(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SOPS_AGE_KEY", id.String())
+	unsetenv(t, "SOPS_AGE_KEY_FILE")
+	return id
+}
+
+// GenerateKey generates an age identity.
+func GenerateKey(t *testing.T) *age.X25519Identity {
+	t.Helper()
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	return id
+}
+
+// WriteKeyFile writes the identity to a key file in a temporary directory,
+// and returns its path.
+func WriteKeyFile(t *testing.T, id *age.X25519Identity) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "keys.txt")
+	require.NoError(t, os.WriteFile(name, []byte(id.String()+"\n"), 0600))
+	return name
+}
+
+// Encrypt encrypts the YAML document with SOPS for the given age recipient.
+// Only the values of the keys matching encryptedRegex are encrypted, all of
+// them when it is empty.
+func Encrypt(t *testing.T, plain []byte, recipient *age.X25519Recipient, encryptedRegex string) []byte {
+	t.Helper()
+	store := sopsyaml.NewStore(&config.YAMLStoreConfig{})
+	branches, err := store.LoadPlainFile(plain)
+	require.NoError(t, err)
+
+	key, err := sopsage.MasterKeyFromRecipient(recipient.String())
+	require.NoError(t, err)
+	tree := sops.Tree{
+		Branches: branches,
+		Metadata: sops.Metadata{
+			KeyGroups:      []sops.KeyGroup{{key}},
+			EncryptedRegex: encryptedRegex,
+			Version:        "3.10.2",
+		},
+	}
+	if encryptedRegex != "" {
+		tree.Metadata.MACOnlyEncrypted = true
+	}
+	dataKey, errs := tree.GenerateDataKeyWithKeyServices([]keyservice.KeyServiceClient{keyservice.NewLocalClient()})
+	require.Empty(t, errs)
+
+	cipher := aes.NewCipher()
+	mac, err := tree.Encrypt(dataKey, cipher)
+	require.NoError(t, err)
+	tree.Metadata.LastModified = time.Now().UTC()
+	tree.Metadata.MessageAuthenticationCode, err = cipher.Encrypt(mac, dataKey, tree.Metadata.LastModified.Format(time.RFC3339))
+	require.NoError(t, err)
+
+	out, err := store.EmitEncryptedFile(tree)
+	require.NoError(t, err)
+	return out
+}
+
+// EncryptAge encrypts the whole file with age for the given recipient,
+// armored or not.
+func EncryptAge(t *testing.T, plain []byte, recipient *age.X25519Recipient, armored bool) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var out io.WriteCloser = nopCloser{&buf}
+	if armored {
+		out = armor.NewWriter(&buf)
+	}
+	w, err := age.Encrypt(out, recipient)
+	require.NoError(t, err)
+	_, err = w.Write(plain)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, out.Close())
+	return buf.Bytes()
+}
+
+// unsetenv unsets the environment variable for the rest of the test.
+func unsetenv(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv(key, "")
+	os.Unsetenv(key)
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
@@ -89,6 +89,29 @@ type Configuration struct {
 	// CustomTemplateFuncs is defined by users to provide custom template funcs
 	CustomTemplateFuncs template.FuncMap
 
+	// RenderProfile, when set, records the time spent rendering the templates.
+	RenderProfile *engine.Profile
+
+	// RenderParseCache, when set, keeps the parse trees of the templates
+	// across renders, for the commands rendering the same chart repeatedly.
+	RenderParseCache *engine.ParseCache
+
+	// LookupProvider, when set, serves the 'lookup' template function in place
+	// of the cluster, e.g. from fixtures.
+	LookupProvider engine.ClientProvider
+
+	// PluginDirs are the directories the template function plugins required
+	// by charts are loaded from.
+	PluginDirs []string
+
+	// RenderConcurrency is the maximum number of templates rendered at the
+	// same time, see engine.Engine.Concurrency.
+	RenderConcurrency int
+
+	// RenderSourceMap, when set, maps the rendered manifests back to the
+	// template lines, so that errors building them point at the templates.
+	RenderSourceMap bool
+
 	// HookOutputFunc called with container name and returns and expects writer that will receive the log output.
 	HookOutputFunc func(namespace, pod, container string) io.Writer
 
@@ -176,47 +199,58 @@ func splitAndDeannotate(postrendered string) (map[string]string, error) {
 // TODO: As part of the refactor the duplicate code in cmd/helm/template.go should be removed
 //
 //	This code has to do with writing files to disk.
-func (cfg *Configuration) renderResources(ch *chart.Chart, values chartutil.Values, releaseName, outputDir string, subNotes, useReleaseName, includeCrds bool, pr postrenderer.PostRenderer, interactWithRemote, enableDNS, hideSecret bool) ([]*release.Hook, *bytes.Buffer, string, error) {
+//
+// The returned sources map the lines of the manifest back to the template lines
+// that produced them when cfg.RenderSourceMap is set. They are nil otherwise,
+// and when a post-renderer is used.
+func (cfg *Configuration) renderResources(ch *chart.Chart, values chartutil.Values, releaseName, outputDir string, subNotes, useReleaseName, includeCrds bool, pr postrenderer.PostRenderer, interactWithRemote, enableDNS, hideSecret bool) ([]*release.Hook, *bytes.Buffer, string, manifestSources, error) {
 	var hs []*release.Hook
 	b := bytes.NewBuffer(nil)
 
 	caps, err := cfg.getCapabilities()
 	if err != nil {
-		return hs, b, "", err
+		return hs, b, "", nil, err
 	}
 
 	if ch.Metadata.KubeVersion != "" {
 		if !chartutil.IsCompatibleRange(ch.Metadata.KubeVersion, caps.KubeVersion.String()) {
-			return hs, b, "", fmt.Errorf("chart requires kubeVersion: %s which is incompatible with Kubernetes %s", ch.Metadata.KubeVersion, caps.KubeVersion.String())
+			return hs, b, "", nil, fmt.Errorf("chart requires kubeVersion: %s which is incompatible with Kubernetes %s", ch.Metadata.KubeVersion, caps.KubeVersion.String())
 		}
 	}
 
 	var files map[string]string
 	var err2 error
+	var sourceMap engine.SourceMap
+	if cfg.RenderSourceMap {
+		sourceMap = engine.SourceMap{}
+	}
 
 	// A `helm template` should not talk to the remote cluster. However, commands with the flag
 	// `--dry-run` with the value of `false`, `none`, or `server` should try to interact with the cluster.
 	// It may break in interesting and exotic ways because other data (e.g. discovery) is mocked.
-	if interactWithRemote && cfg.RESTClientGetter != nil {
+	// Lookup fixtures take the place of the cluster either way.
+	var e engine.Engine
+	if cfg.LookupProvider != nil {
+		e = engine.NewWithClientProvider(cfg.LookupProvider)
+	} else if interactWithRemote && cfg.RESTClientGetter != nil {
 		restConfig, err := cfg.RESTClientGetter.ToRESTConfig()
 		if err != nil {
-			return hs, b, "", err
+			return hs, b, "", nil, err
 		}
-		e := engine.New(restConfig)
-		e.EnableDNS = enableDNS
-		e.CustomTemplateFuncs = cfg.CustomTemplateFuncs
-
-		files, err2 = e.Render(ch, values)
-	} else {
-		var e engine.Engine
-		e.EnableDNS = enableDNS
-		e.CustomTemplateFuncs = cfg.CustomTemplateFuncs
-
-		files, err2 = e.Render(ch, values)
+		e = engine.New(restConfig)
 	}
+	e.EnableDNS = enableDNS
+	e.CustomTemplateFuncs = cfg.CustomTemplateFuncs
+	e.SourceMap = sourceMap
+	e.Profile = cfg.RenderProfile
+	e.ParseCache = cfg.RenderParseCache
+	e.PluginDirs = cfg.PluginDirs
+	e.Concurrency = cfg.RenderConcurrency
+
+	files, err2 = e.Render(ch, values)
 
 	if err2 != nil {
-		return hs, b, "", err2
+		return hs, b, "", nil, err2
 	}
 
 	// NOTES.txt gets rendered like all the other files, but because it's not a hook nor a resource,
@@ -250,20 +284,22 @@ func (cfg *Configuration) renderResources(ch *chart.Chart, values chartutil.Valu
 		// Merge files as stream of documents for sending to post renderer
 		merged, err := annotateAndMerge(files)
 		if err != nil {
-			return hs, b, notes, fmt.Errorf("error merging manifests: %w", err)
+			return hs, b, notes, nil, fmt.Errorf("error merging manifests: %w", err)
 		}
 
 		// Run the post renderer
 		postRendered, err := pr.Run(bytes.NewBufferString(merged))
 		if err != nil {
-			return hs, b, notes, fmt.Errorf("error while running post render on files: %w", err)
+			return hs, b, notes, nil, fmt.Errorf("error while running post render on files: %w", err)
 		}
 
 		// Use the file list and contents received from the post renderer
 		files, err = splitAndDeannotate(postRendered.String())
 		if err != nil {
-			return hs, b, notes, fmt.Errorf("error while parsing post rendered output: %w", err)
+			return hs, b, notes, nil, fmt.Errorf("error while parsing post rendered output: %w", err)
 		}
+		// The lines of the post-rendered files no longer match the templates.
+		sourceMap = nil
 	}
 
 	// Sort hooks, manifests, and partials. Only hooks and manifests are returned,
@@ -282,20 +318,24 @@ func (cfg *Configuration) renderResources(ch *chart.Chart, values chartutil.Valu
 			}
 			fmt.Fprintf(b, "---\n# Source: %s\n%s\n", name, content)
 		}
-		return hs, b, "", err
+		return hs, b, "", nil, err
 	}
 
 	// Aggregate all valid manifests into one big doc.
 	fileWritten := make(map[string]bool)
+	var sources manifestSources
 
 	if includeCrds {
 		for _, crd := range ch.CRDObjects() {
 			if outputDir == "" {
 				fmt.Fprintf(b, "---\n# Source: %s\n%s\n", crd.Filename, string(crd.File.Data[:]))
+				if sourceMap != nil {
+					sources.addCRD(crd)
+				}
 			} else {
 				err = writeToFile(outputDir, crd.Filename, string(crd.File.Data[:]), fileWritten[crd.Filename])
 				if err != nil {
-					return hs, b, "", err
+					return hs, b, "", nil, err
 				}
 				fileWritten[crd.Filename] = true
 			}
@@ -306,8 +346,14 @@ func (cfg *Configuration) renderResources(ch *chart.Chart, values chartutil.Valu
 		if outputDir == "" {
 			if hideSecret && m.Head.Kind == "Secret" && m.Head.Version == "v1" {
 				fmt.Fprintf(b, "---\n# Source: %s\n# HIDDEN: The Secret output has been suppressed\n", m.Name)
+				if sourceMap != nil {
+					sources.addUnknown(3)
+				}
 			} else {
 				fmt.Fprintf(b, "---\n# Source: %s\n%s\n", m.Name, m.Content)
+				if sourceMap != nil {
+					sources.addManifest(m, files[m.Name], sourceMap)
+				}
 			}
 		} else {
 			newDir := outputDir
@@ -320,13 +366,13 @@ func (cfg *Configuration) renderResources(ch *chart.Chart, values chartutil.Valu
 			// used by install or upgrade
 			err = writeToFile(newDir, m.Name, m.Content, fileWritten[m.Name])
 			if err != nil {
-				return hs, b, "", err
+				return hs, b, "", nil, err
 			}
 			fileWritten[m.Name] = true
 		}
 	}
 
-	return hs, b, notes, nil
+	return hs, b, notes, sources, nil
 }
 
 // RESTClientGetter gets the rest client
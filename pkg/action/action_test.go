@@ -786,7 +786,7 @@ func TestRenderResources_PostRenderer_Success(t *testing.T) {
 	ch := buildChart(withSampleTemplates())
 	values := map[string]interface{}{}
 
-	hooks, buf, notes, err := cfg.renderResources(
+	hooks, buf, notes, _, err := cfg.renderResources(
 		ch, values, "test-release", "", false, false, false,
 		mockPR, false, false, false,
 	)
@@ -829,7 +829,7 @@ func TestRenderResources_PostRenderer_Error(t *testing.T) {
 	ch := buildChart(withSampleTemplates())
 	values := map[string]interface{}{}
 
-	_, _, _, err := cfg.renderResources(
+	_, _, _, _, err := cfg.renderResources(
 		ch, values, "test-release", "", false, false, false,
 		mockPR, false, false, false,
 	)
@@ -857,7 +857,7 @@ func TestRenderResources_PostRenderer_MergeError(t *testing.T) {
 	}
 	values := map[string]interface{}{}
 
-	_, _, _, err := cfg.renderResources(
+	_, _, _, _, err := cfg.renderResources(
 		ch, values, "test-release", "", false, false, false,
 		mockPR, false, false, false,
 	)
@@ -879,7 +879,7 @@ func TestRenderResources_PostRenderer_SplitError(t *testing.T) {
 	ch := buildChart(withSampleTemplates())
 	values := map[string]interface{}{}
 
-	_, _, _, err := cfg.renderResources(
+	_, _, _, _, err := cfg.renderResources(
 		ch, values, "test-release", "", false, false, false,
 		mockPR, false, false, false,
 	)
@@ -900,7 +900,7 @@ func TestRenderResources_PostRenderer_Integration(t *testing.T) {
 	ch := buildChart(withSampleTemplates())
 	values := map[string]interface{}{}
 
-	hooks, buf, notes, err := cfg.renderResources(
+	hooks, buf, notes, _, err := cfg.renderResources(
 		ch, values, "test-release", "", false, false, false,
 		mockPR, false, false, false,
 	)
@@ -936,7 +936,7 @@ func TestRenderResources_NoPostRenderer(t *testing.T) {
 	ch := buildChart(withSampleTemplates())
 	values := map[string]interface{}{}
 
-	hooks, buf, notes, err := cfg.renderResources(
+	hooks, buf, notes, _, err := cfg.renderResources(
 		ch, values, "test-release", "", false, false, false,
 		nil, false, false, false,
 	)
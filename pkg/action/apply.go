@@ -0,0 +1,526 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chart/loader"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/cli"
+	clivalues "helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/kube"
+	release "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// ReleaseSetLabel is the release label used to record which release set a
+// release was applied from. It is used to find releases that were removed
+// from the set.
+const ReleaseSetLabel = "helm.sh/release-set"
+
+// ReleaseSet declares a set of releases that are applied together.
+type ReleaseSet struct {
+	// Name identifies the set. Releases applied from a named set are labeled
+	// with it, which is required for pruning.
+	Name string `json:"name,omitempty"`
+	// Releases are the releases of the set.
+	Releases []*ReleaseSpec `json:"releases"`
+}
+
+// ReleaseSpec declares a single release of a ReleaseSet.
+type ReleaseSpec struct {
+	// Name is the name of the release.
+	Name string `json:"name"`
+	// Namespace is the namespace of the release. It defaults to the namespace
+	// Helm is run against.
+	Namespace string `json:"namespace,omitempty"`
+	// Chart is a chart reference, as accepted by 'helm install'. Paths
+	// starting with "./" or "../" are resolved against the directory of the
+	// release set file.
+	Chart string `json:"chart"`
+	// Version is the chart version constraint.
+	Version string `json:"version,omitempty"`
+	// Values are values files. Relative paths are resolved against the
+	// directory of the release set file.
+	Values []string `json:"values,omitempty"`
+	// Needs lists the releases that have to be applied before this one,
+	// either as "name" for a release in the same namespace or as
+	// "namespace/name".
+	Needs []string `json:"needs,omitempty"`
+}
+
+// key returns the namespace/name identifier of the release.
+func (r *ReleaseSpec) key() string {
+	return r.Namespace + "/" + r.Name
+}
+
+// LoadReleaseSet reads a release set from a file.
+//
+// Releases without a namespace are assigned the given default namespace, and
+// the set is validated: release names must be unique per namespace, and the
+// releases listed in 'needs' must exist and must not form a cycle.
+func LoadReleaseSet(filename, namespace string) (*ReleaseSet, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &ReleaseSet{}
+	if err := yaml.UnmarshalStrict(data, set); err != nil {
+		return nil, fmt.Errorf("unable to parse release set %s: %w", filename, err)
+	}
+
+	dir := filepath.Dir(filename)
+	for _, r := range set.Releases {
+		if r.Namespace == "" {
+			r.Namespace = namespace
+		}
+		if strings.HasPrefix(r.Chart, "./") || strings.HasPrefix(r.Chart, "../") {
+			r.Chart = filepath.Join(dir, r.Chart)
+		}
+		for i, v := range r.Values {
+			if !filepath.IsAbs(v) && !strings.Contains(v, "://") {
+				r.Values[i] = filepath.Join(dir, v)
+			}
+		}
+	}
+
+	if err := set.validate(); err != nil {
+		return nil, fmt.Errorf("invalid release set %s: %w", filename, err)
+	}
+	return set, nil
+}
+
+// validate checks that the releases of the set are well-formed and that their
+// dependencies form a directed acyclic graph.
+func (s *ReleaseSet) validate() error {
+	byKey := map[string]*ReleaseSpec{}
+	for i, r := range s.Releases {
+		if r.Name == "" {
+			return fmt.Errorf("release %d has no name", i)
+		}
+		if err := chartutil.ValidateReleaseName(r.Name); err != nil {
+			return fmt.Errorf("release name is invalid: %s", r.Name)
+		}
+		if r.Chart == "" {
+			return fmt.Errorf("release %s has no chart", r.key())
+		}
+		if _, ok := byKey[r.key()]; ok {
+			return fmt.Errorf("release %s is declared more than once", r.key())
+		}
+		byKey[r.key()] = r
+	}
+
+	for _, r := range s.Releases {
+		for _, n := range r.Needs {
+			if _, ok := byKey[r.need(n)]; !ok {
+				return fmt.Errorf("release %s needs unknown release %s", r.key(), n)
+			}
+		}
+	}
+
+	// Depth-first search for cycles
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var visit func(r *ReleaseSpec, path []string) error
+	visit = func(r *ReleaseSpec, path []string) error {
+		path = append(path, r.key())
+		switch state[r.key()] {
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(path, " -> "))
+		case visited:
+			return nil
+		}
+		state[r.key()] = visiting
+		for _, n := range r.Needs {
+			if err := visit(byKey[r.need(n)], path); err != nil {
+				return err
+			}
+		}
+		state[r.key()] = visited
+		return nil
+	}
+	for _, r := range s.Releases {
+		if err := visit(r, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// need returns the namespace/name key of a release listed in 'needs'.
+func (r *ReleaseSpec) need(n string) string {
+	if strings.Contains(n, "/") {
+		return n
+	}
+	return r.Namespace + "/" + n
+}
+
+// ApplyOperation is the operation performed on a release by Apply.
+type ApplyOperation string
+
+const (
+	ApplyInstall   ApplyOperation = "install"
+	ApplyUpgrade   ApplyOperation = "upgrade"
+	ApplyUnchanged ApplyOperation = "unchanged"
+	ApplyUninstall ApplyOperation = "uninstall"
+	// ApplySkipped is reported for releases that were not applied because a
+	// release they need failed.
+	ApplySkipped ApplyOperation = "skipped"
+)
+
+// ApplyResult is the outcome of applying a single release.
+type ApplyResult struct {
+	Name      string         `json:"name"`
+	Namespace string         `json:"namespace"`
+	Operation ApplyOperation `json:"operation"`
+	// Release is the resulting release. It is nil for dry runs, skipped
+	// releases and failures that happen before the release is recorded.
+	Release *release.Release `json:"-"`
+	Error   error            `json:"-"`
+}
+
+// Apply is the action for applying a declarative set of releases.
+//
+// It provides the implementation of 'helm apply'.
+type Apply struct {
+	// ActionConfig returns the configuration used for the releases of the
+	// given namespace. An empty namespace is used to list releases across all
+	// namespaces when pruning.
+	ActionConfig func(namespace string) (*Configuration, error)
+	// Settings are used to locate charts and read values files.
+	Settings *cli.EnvSettings
+
+	// Concurrency is the maximum number of releases applied at the same
+	// time. Values below 1 apply one release at a time.
+	Concurrency int
+	// Prune uninstalls releases labeled with the set name that are no longer
+	// declared in the set.
+	Prune bool
+	// DryRun computes the operations that would be performed without
+	// performing them.
+	DryRun            bool
+	Timeout           time.Duration
+	WaitStrategy      kube.WaitStrategy
+	WaitForJobs       bool
+	RollbackOnFailure bool
+	CreateNamespace   bool
+}
+
+// NewApply creates a new Apply object that takes the configuration for each
+// namespace from the given function.
+func NewApply(actionConfig func(namespace string) (*Configuration, error), settings *cli.EnvSettings) *Apply {
+	return &Apply{
+		ActionConfig: actionConfig,
+		Settings:     settings,
+		Concurrency:  1,
+	}
+}
+
+// Run applies the given release set.
+//
+// Releases are applied in dependency order. A release is only applied once
+// all the releases it needs have been applied successfully; the releases that
+// need a failed release are skipped. The results are returned in the order
+// the releases are declared, followed by the pruned releases.
+func (a *Apply) Run(ctx context.Context, set *ReleaseSet) ([]*ApplyResult, error) {
+	if a.Prune && set.Name == "" {
+		return nil, errors.New("pruning requires the release set to have a name")
+	}
+
+	results := make(map[string]*ApplyResult, len(set.Releases))
+	done := make(map[string]chan struct{}, len(set.Releases))
+	for _, r := range set.Releases {
+		results[r.key()] = &ApplyResult{Name: r.Name, Namespace: r.Namespace}
+		done[r.key()] = make(chan struct{})
+	}
+
+	sem := make(chan struct{}, max(a.Concurrency, 1))
+	var wg sync.WaitGroup
+	for _, r := range set.Releases {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[r.key()])
+			res := results[r.key()]
+
+			for _, n := range r.Needs {
+				<-done[r.need(n)]
+				if dep := results[r.need(n)]; dep.Error != nil || dep.Operation == ApplySkipped {
+					slog.Debug("skipping release, a release it needs was not applied", "release", r.key(), "needs", n)
+					res.Operation = ApplySkipped
+					return
+				}
+			}
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if err := ctx.Err(); err != nil {
+				res.Error = err
+				return
+			}
+			res.Operation, res.Release, res.Error = a.apply(ctx, set, r)
+		}()
+	}
+	wg.Wait()
+
+	ordered := make([]*ApplyResult, 0, len(set.Releases))
+	var errs []error
+	for _, r := range set.Releases {
+		res := results[r.key()]
+		if res.Error != nil {
+			errs = append(errs, fmt.Errorf("release %s: %w", r.key(), res.Error))
+		}
+		ordered = append(ordered, res)
+	}
+	if len(errs) > 0 {
+		return ordered, errors.Join(errs...)
+	}
+
+	if a.Prune {
+		pruned, err := a.prune(set)
+		ordered = append(ordered, pruned...)
+		if err != nil {
+			return ordered, err
+		}
+	}
+	return ordered, nil
+}
+
+// apply installs or upgrades a single release of the set.
+func (a *Apply) apply(ctx context.Context, set *ReleaseSet, r *ReleaseSpec) (ApplyOperation, *release.Release, error) {
+	cfg, err := a.ActionConfig(r.Namespace)
+	if err != nil {
+		return "", nil, err
+	}
+
+	chartPath := ChartPathOptions{Version: r.Version, registryClient: cfg.RegistryClient}
+	cp, err := chartPath.LocateChart(r.Chart, a.Settings)
+	if err != nil {
+		return "", nil, err
+	}
+	ch, err := loader.Load(cp)
+	if err != nil {
+		return "", nil, err
+	}
+	if req := ch.Metadata.Dependencies; req != nil {
+		if err := CheckDependencies(ch, req); err != nil {
+			return "", nil, fmt.Errorf("an error occurred while checking for chart dependencies. You may need to run `helm dependency build` to fetch missing dependencies: %w", err)
+		}
+	}
+
+	valueOpts := &clivalues.Options{ValueFiles: r.Values}
+	vals, err := valueOpts.MergeValues(getter.All(a.Settings))
+	if err != nil {
+		return "", nil, err
+	}
+
+	var labels map[string]string
+	if set.Name != "" {
+		labels = map[string]string{ReleaseSetLabel: set.Name}
+	}
+
+	versions, err := cfg.Releases.History(r.Name)
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return "", nil, err
+	}
+	var last *release.Release
+	for _, v := range versions {
+		if last == nil || v.Version > last.Version {
+			last = v
+		}
+	}
+
+	if last == nil || last.Info.Status == release.StatusUninstalled {
+		if a.DryRun {
+			return ApplyInstall, nil, nil
+		}
+		slog.Debug("installing release", "release", r.key())
+		install := NewInstall(cfg)
+		install.ChartPathOptions = chartPath
+		install.ReleaseName = r.Name
+		install.Namespace = r.Namespace
+		install.Replace = last != nil
+		install.CreateNamespace = a.CreateNamespace
+		install.Timeout = a.Timeout
+		install.WaitStrategy = a.WaitStrategy
+		install.WaitForJobs = a.WaitForJobs
+		install.RollbackOnFailure = a.RollbackOnFailure
+		install.Labels = labels
+		rel, err := install.RunWithContext(ctx, ch, vals)
+		return ApplyInstall, rel, err
+	}
+
+	upgrade := NewUpgrade(cfg)
+	upgrade.ChartPathOptions = chartPath
+	upgrade.Namespace = r.Namespace
+	upgrade.Install = true
+	upgrade.Timeout = a.Timeout
+	upgrade.WaitStrategy = a.WaitStrategy
+	upgrade.WaitForJobs = a.WaitForJobs
+	upgrade.RollbackOnFailure = a.RollbackOnFailure
+	upgrade.Labels = labels
+
+	if unchanged(last, ch.Metadata.Name, ch.Metadata.Version, vals, labels) {
+		// The templates of a chart can be edited without bumping its
+		// version, so the release is rendered again and compared to the
+		// deployed one.
+		upgrade.DryRunOption = "server"
+		rendered, err := upgrade.RunWithContext(ctx, r.Name, ch, vals)
+		upgrade.DryRunOption = ""
+		if err != nil {
+			return "", nil, err
+		}
+		if sameManifests(last, rendered) {
+			slog.Debug("release is unchanged", "release", r.key())
+			return ApplyUnchanged, last, nil
+		}
+	}
+	if a.DryRun {
+		return ApplyUpgrade, nil, nil
+	}
+
+	slog.Debug("upgrading release", "release", r.key())
+	rel, err := upgrade.RunWithContext(ctx, r.Name, ch, vals)
+	return ApplyUpgrade, rel, err
+}
+
+// unchanged reports whether the release is deployed from the given chart
+// version with the given values and labels.
+func unchanged(rel *release.Release, chartName, chartVersion string, vals map[string]interface{}, labels map[string]string) bool {
+	if rel.Info == nil || rel.Info.Status != release.StatusDeployed {
+		return false
+	}
+	if rel.Chart == nil || rel.Chart.Metadata == nil ||
+		rel.Chart.Metadata.Name != chartName || rel.Chart.Metadata.Version != chartVersion {
+		return false
+	}
+	for k, v := range labels {
+		if rel.Labels[k] != v {
+			return false
+		}
+	}
+	return equalValues(rel.Config, vals)
+}
+
+// sameManifests reports whether two releases have the same rendered manifest
+// and hooks.
+func sameManifests(a, b *release.Release) bool {
+	if a.Manifest != b.Manifest || len(a.Hooks) != len(b.Hooks) {
+		return false
+	}
+	hooks := func(rel *release.Release) []string {
+		var manifests []string
+		for _, h := range rel.Hooks {
+			manifests = append(manifests, h.Path+"\n"+h.Manifest)
+		}
+		sort.Strings(manifests)
+		return manifests
+	}
+	return slices.Equal(hooks(a), hooks(b))
+}
+
+// equalValues compares two sets of values after normalizing them through
+// JSON, the way they are stored in a release.
+func equalValues(a, b map[string]interface{}) bool {
+	normalize := func(v map[string]interface{}) (map[string]interface{}, error) {
+		out := map[string]interface{}{}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return out, json.Unmarshal(data, &out)
+	}
+	na, err := normalize(a)
+	if err != nil {
+		return false
+	}
+	nb, err := normalize(b)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(na, nb)
+}
+
+// prune uninstalls the releases labeled with the set name that are not
+// declared in the set.
+func (a *Apply) prune(set *ReleaseSet) ([]*ApplyResult, error) {
+	cfg, err := a.ActionConfig("")
+	if err != nil {
+		return nil, err
+	}
+
+	list := NewList(cfg)
+	list.AllNamespaces = true
+	list.Selector = ReleaseSetLabel + "=" + set.Name
+	rels, err := list.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*ApplyResult
+	var errs []error
+	for _, rel := range rels {
+		declared := slices.ContainsFunc(set.Releases, func(r *ReleaseSpec) bool {
+			return r.Name == rel.Name && r.Namespace == rel.Namespace
+		})
+		if declared {
+			continue
+		}
+
+		res := &ApplyResult{Name: rel.Name, Namespace: rel.Namespace, Operation: ApplyUninstall, Release: rel}
+		results = append(results, res)
+		if a.DryRun {
+			continue
+		}
+
+		slog.Debug("uninstalling release removed from the set", "release", rel.Namespace+"/"+rel.Name)
+		cfg, err := a.ActionConfig(rel.Namespace)
+		if err != nil {
+			res.Error = err
+			errs = append(errs, err)
+			continue
+		}
+		uninstall := NewUninstall(cfg)
+		uninstall.Timeout = a.Timeout
+		uninstall.WaitStrategy = a.WaitStrategy
+		if _, err := uninstall.Run(rel.Name); err != nil {
+			res.Error = err
+			errs = append(errs, fmt.Errorf("release %s/%s: %w", rel.Namespace, rel.Name, err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,250 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/cli"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func writeReleaseSet(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	chartPath, err := filepath.Abs("testdata/charts/decompressedchart")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db.yaml"), []byte("replicas: 2\n"), 0644))
+
+	filename := filepath.Join(dir, "releases.yaml")
+	content = os.Expand(content, func(k string) string {
+		if k == "CHART" {
+			return chartPath
+		}
+		return "$" + k
+	})
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0644))
+	return filename
+}
+
+const testReleaseSet = `name: prod
+releases:
+- name: app
+  chart: ${CHART}
+  needs: [db]
+- name: db
+  chart: ${CHART}
+  values: [db.yaml]
+- name: cache
+  chart: ${CHART}
+`
+
+func applyAction(t *testing.T) *Apply {
+	t.Helper()
+	config := actionConfigFixture(t)
+	return NewApply(func(string) (*Configuration, error) { return config, nil }, cli.New())
+}
+
+func TestLoadReleaseSet(t *testing.T) {
+	set, err := LoadReleaseSet(writeReleaseSet(t, testReleaseSet), "default")
+	require.NoError(t, err)
+
+	assert.Equal(t, "prod", set.Name)
+	require.Len(t, set.Releases, 3)
+	assert.Equal(t, "default", set.Releases[0].Namespace)
+	assert.True(t, filepath.IsAbs(set.Releases[1].Values[0]), "values files should be resolved relative to the set file")
+}
+
+func TestLoadReleaseSetErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{
+			name:    "unknown field",
+			content: "releases:\n- name: app\n  chart: ${CHART}\n  valuez: [a.yaml]\n",
+			errMsg:  "unknown field",
+		},
+		{
+			name:    "missing chart",
+			content: "releases:\n- name: app\n",
+			errMsg:  "release default/app has no chart",
+		},
+		{
+			name:    "duplicate release",
+			content: "releases:\n- name: app\n  chart: ${CHART}\n- name: app\n  chart: ${CHART}\n",
+			errMsg:  "release default/app is declared more than once",
+		},
+		{
+			name:    "unknown need",
+			content: "releases:\n- name: app\n  chart: ${CHART}\n  needs: [db]\n",
+			errMsg:  "release default/app needs unknown release db",
+		},
+		{
+			name: "cycle",
+			content: `releases:
+- name: a
+  chart: ${CHART}
+  needs: [b]
+- name: b
+  chart: ${CHART}
+  needs: [other/c]
+- name: c
+  namespace: other
+  chart: ${CHART}
+  needs: [default/a]
+`,
+			errMsg: "dependency cycle: default/a -> default/b -> other/c -> default/a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadReleaseSet(writeReleaseSet(t, tt.content), "default")
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func operations(results []*ApplyResult) map[string]ApplyOperation {
+	ops := map[string]ApplyOperation{}
+	for _, r := range results {
+		ops[r.Name] = r.Operation
+	}
+	return ops
+}
+
+func TestApply(t *testing.T) {
+	client := applyAction(t)
+	client.Concurrency = 2
+	filename := writeReleaseSet(t, testReleaseSet)
+	set, err := LoadReleaseSet(filename, "default")
+	require.NoError(t, err)
+
+	results, err := client.Run(context.Background(), set)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]ApplyOperation{"app": ApplyInstall, "db": ApplyInstall, "cache": ApplyInstall}, operations(results))
+
+	cfg, _ := client.ActionConfig("default")
+	app, err := cfg.Releases.Last("app")
+	require.NoError(t, err)
+	db, err := cfg.Releases.Last("db")
+	require.NoError(t, err)
+	assert.Equal(t, "prod", app.Labels[ReleaseSetLabel])
+	assert.Equal(t, map[string]interface{}{"replicas": float64(2)}, db.Config)
+	assert.False(t, app.Info.FirstDeployed.Before(db.Info.FirstDeployed), "app should be installed after db")
+
+	// Applying the same set again leaves every release alone
+	set, err = LoadReleaseSet(filename, "default")
+	require.NoError(t, err)
+	results, err = client.Run(context.Background(), set)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]ApplyOperation{"app": ApplyUnchanged, "db": ApplyUnchanged, "cache": ApplyUnchanged}, operations(results))
+
+	// Changing values upgrades only the affected release
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(filename), "db.yaml"), []byte("replicas: 3\n"), 0644))
+	set, err = LoadReleaseSet(filename, "default")
+	require.NoError(t, err)
+	results, err = client.Run(context.Background(), set)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]ApplyOperation{"app": ApplyUnchanged, "db": ApplyUpgrade, "cache": ApplyUnchanged}, operations(results))
+	db, err = cfg.Releases.Last("db")
+	require.NoError(t, err)
+	assert.Equal(t, 2, db.Version)
+}
+
+func TestApplyPrune(t *testing.T) {
+	client := applyAction(t)
+	set, err := LoadReleaseSet(writeReleaseSet(t, testReleaseSet), "default")
+	require.NoError(t, err)
+	_, err = client.Run(context.Background(), set)
+	require.NoError(t, err)
+
+	// Drop "cache" from the set
+	set.Releases = set.Releases[:2]
+
+	client.Prune = true
+	client.DryRun = true
+	results, err := client.Run(context.Background(), set)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]ApplyOperation{"app": ApplyUnchanged, "db": ApplyUnchanged, "cache": ApplyUninstall}, operations(results))
+	cfg, _ := client.ActionConfig("default")
+	cache, err := cfg.Releases.Last("cache")
+	require.NoError(t, err)
+	assert.Equal(t, release.StatusDeployed, cache.Info.Status, "dry run should not uninstall")
+
+	client.DryRun = false
+	_, err = client.Run(context.Background(), set)
+	require.NoError(t, err)
+	_, err = cfg.Releases.Last("cache")
+	assert.Error(t, err, "cache should have been uninstalled")
+
+	set.Name = ""
+	_, err = client.Run(context.Background(), set)
+	assert.ErrorContains(t, err, "pruning requires the release set to have a name")
+}
+
+func TestApplySkipsDependents(t *testing.T) {
+	client := applyAction(t)
+	set, err := LoadReleaseSet(writeReleaseSet(t, `releases:
+- name: app
+  chart: ${CHART}
+  needs: [db]
+- name: db
+  chart: does-not-exist
+`), "default")
+	require.NoError(t, err)
+
+	results, err := client.Run(context.Background(), set)
+	require.ErrorContains(t, err, "release default/db")
+	assert.Equal(t, ApplySkipped, operations(results)["app"])
+}
+
+func TestApplyUpgradesEditedTemplates(t *testing.T) {
+	client := applyAction(t)
+	dir := t.TempDir()
+	chartPath := filepath.Join(dir, "chart")
+	require.NoError(t, os.CopyFS(chartPath, os.DirFS("testdata/charts/decompressedchart")))
+	template := filepath.Join(chartPath, "templates", "configmap.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(template), 0755))
+	require.NoError(t, os.WriteFile(template, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  key: one\n"), 0644))
+	filename := filepath.Join(dir, "releases.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("releases:\n- name: app\n  chart: "+chartPath+"\n"), 0644))
+
+	apply := func() ApplyOperation {
+		t.Helper()
+		set, err := LoadReleaseSet(filename, "default")
+		require.NoError(t, err)
+		results, err := client.Run(context.Background(), set)
+		require.NoError(t, err)
+		return results[0].Operation
+	}
+	assert.Equal(t, ApplyInstall, apply())
+	assert.Equal(t, ApplyUnchanged, apply())
+
+	// Editing a template without bumping the chart version upgrades the release
+	require.NoError(t, os.WriteFile(template, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  key: two\n"), 0644))
+	assert.Equal(t, ApplyUpgrade, apply())
+	assert.Equal(t, ApplyUnchanged, apply())
+}
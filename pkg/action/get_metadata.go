@@ -48,6 +48,10 @@ type Metadata struct {
 	Status       string              `json:"status" yaml:"status"`
 	DeployedAt   string              `json:"deployedAt" yaml:"deployedAt"`
 	ApplyMethod  string              `json:"applyMethod,omitempty" yaml:"applyMethod,omitempty"`
+	// ChartAPIVersion is the apiVersion of the released chart
+	ChartAPIVersion string `json:"chartAPIVersion,omitempty" yaml:"chartAPIVersion,omitempty"`
+	// ValuesFrom are the Secrets and ConfigMaps values were read from
+	ValuesFrom []string `json:"valuesFrom,omitempty" yaml:"valuesFrom,omitempty"`
 }
 
 // NewGetMetadata creates a new GetMetadata object with the given configuration.
@@ -69,18 +73,20 @@ func (g *GetMetadata) Run(name string) (*Metadata, error) {
 	}
 
 	return &Metadata{
-		Name:         rel.Name,
-		Chart:        rel.Chart.Metadata.Name,
-		Version:      rel.Chart.Metadata.Version,
-		AppVersion:   rel.Chart.Metadata.AppVersion,
-		Dependencies: rel.Chart.Metadata.Dependencies,
-		Annotations:  rel.Chart.Metadata.Annotations,
-		Labels:       rel.Labels,
-		Namespace:    rel.Namespace,
-		Revision:     rel.Version,
-		Status:       rel.Info.Status.String(),
-		DeployedAt:   rel.Info.LastDeployed.Format(time.RFC3339),
-		ApplyMethod:  rel.ApplyMethod,
+		Name:            rel.Name,
+		Chart:           rel.Chart.Metadata.Name,
+		Version:         rel.Chart.Metadata.Version,
+		AppVersion:      rel.Chart.Metadata.AppVersion,
+		Dependencies:    rel.Chart.Metadata.Dependencies,
+		Annotations:     rel.Chart.Metadata.Annotations,
+		Labels:          rel.Labels,
+		Namespace:       rel.Namespace,
+		Revision:        rel.Version,
+		Status:          rel.Info.Status.String(),
+		DeployedAt:      rel.Info.LastDeployed.Format(time.RFC3339),
+		ApplyMethod:     rel.ApplyMethod,
+		ValuesFrom:      rel.ValuesFrom,
+		ChartAPIVersion: rel.ChartAPIVersion,
 	}, nil
 }
 
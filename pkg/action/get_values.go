@@ -58,3 +58,25 @@ func (g *GetValues) Run(name string) (map[string]interface{}, error) {
 	}
 	return rel.Config, nil
 }
+
+// Explain explains the computed values of the given release leaf by leaf.
+//
+// Releases do not record the files the values were read from, so the values
+// supplied by the user are reported as a single source.
+func (g *GetValues) Explain(name string) ([]chartutil.ValueExplanation, error) {
+	if err := g.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	rel, err := g.cfg.releaseContent(name, g.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	computed, err := chartutil.CoalesceValues(rel.Chart, rel.Config)
+	if err != nil {
+		return nil, err
+	}
+	sources := []chartutil.ValuesSource{{Name: "user-supplied values", Values: rel.Config}}
+	return chartutil.ExplainValues(rel.Chart, computed, sources), nil
+}
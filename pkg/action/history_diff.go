@@ -0,0 +1,272 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/yaml"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	releaseutil "helm.sh/helm/v4/pkg/release/util"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// ChangeType describes how an item differs between two revisions.
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeRemoved  ChangeType = "removed"
+	ChangeModified ChangeType = "modified"
+)
+
+// ValueChange is a change of a single leaf value between two revisions.
+type ValueChange struct {
+	// Path is the dotted path of the value, e.g. "image.tag".
+	Path   string      `json:"path"`
+	Change ChangeType  `json:"change"`
+	From   interface{} `json:"from,omitempty"`
+	To     interface{} `json:"to,omitempty"`
+}
+
+// ResourceChange is a change of a single resource of the manifest between two
+// revisions.
+type ResourceChange struct {
+	// Resource identifies the resource by group/version, kind, namespace and
+	// name, e.g. "apps/v1/Deployment/default/web".
+	Resource string     `json:"resource"`
+	Change   ChangeType `json:"change"`
+	// Diff is a unified diff of the resource's YAML.
+	Diff string `json:"diff"`
+}
+
+// RevisionDiff is the difference between two revisions of a release.
+type RevisionDiff struct {
+	Release string `json:"release"`
+	From    int    `json:"from"`
+	To      int    `json:"to"`
+	// FromChart and ToChart are the chart name and version of each revision.
+	FromChart string `json:"from_chart"`
+	ToChart   string `json:"to_chart"`
+	// UserValues are the changes of the user-supplied values.
+	UserValues []ValueChange `json:"user_values"`
+	// ComputedValues are the changes of the values after coalescing them
+	// with the chart defaults.
+	ComputedValues []ValueChange    `json:"computed_values"`
+	Resources      []ResourceChange `json:"resources"`
+}
+
+// HistoryDiff is the action for comparing two revisions of a release.
+//
+// It provides the implementation of 'helm history --diff'.
+type HistoryDiff struct {
+	cfg *Configuration
+}
+
+// NewHistoryDiff creates a new HistoryDiff object with the given configuration.
+func NewHistoryDiff(cfg *Configuration) *HistoryDiff {
+	return &HistoryDiff{
+		cfg: cfg,
+	}
+}
+
+// Run compares the revisions from and to of the given release.
+func (h *HistoryDiff) Run(name string, from, to int) (*RevisionDiff, error) {
+	if err := h.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, fmt.Errorf("release name is invalid: %s", name)
+	}
+	if from <= 0 || to <= 0 {
+		return nil, errInvalidRevision
+	}
+
+	slog.Debug("comparing release revisions", "release", name, "from", from, "to", to)
+	fromRel, err := h.cfg.Releases.Get(name, from)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get revision %d of release %s: %w", from, name, err)
+	}
+	toRel, err := h.cfg.Releases.Get(name, to)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get revision %d of release %s: %w", to, name, err)
+	}
+	return DiffReleases(fromRel, toRel)
+}
+
+// DiffReleases computes the differences between two releases.
+func DiffReleases(from, to *release.Release) (*RevisionDiff, error) {
+	d := &RevisionDiff{
+		Release:   to.Name,
+		From:      from.Version,
+		To:        to.Version,
+		FromChart: chartNameVersion(from),
+		ToChart:   chartNameVersion(to),
+	}
+
+	d.UserValues = DiffValues(from.Config, to.Config)
+
+	fromComputed, err := chartutil.CoalesceValues(from.Chart, from.Config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute values of revision %d: %w", from.Version, err)
+	}
+	toComputed, err := chartutil.CoalesceValues(to.Chart, to.Config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute values of revision %d: %w", to.Version, err)
+	}
+	d.ComputedValues = DiffValues(fromComputed, toComputed)
+
+	d.Resources = DiffManifests(from.Manifest, to.Manifest)
+	return d, nil
+}
+
+func chartNameVersion(rel *release.Release) string {
+	if rel.Chart == nil || rel.Chart.Metadata == nil {
+		return "MISSING"
+	}
+	return fmt.Sprintf("%s-%s", rel.Chart.Metadata.Name, rel.Chart.Metadata.Version)
+}
+
+// DiffValues compares two sets of values leaf by leaf. Lists are compared as
+// a whole. The changes are sorted by path.
+func DiffValues(from, to map[string]interface{}) []ValueChange {
+	fromLeaves := map[string]interface{}{}
+	flattenValues("", from, fromLeaves)
+	toLeaves := map[string]interface{}{}
+	flattenValues("", to, toLeaves)
+
+	changes := []ValueChange{}
+	for path, f := range fromLeaves {
+		t, ok := toLeaves[path]
+		switch {
+		case !ok:
+			changes = append(changes, ValueChange{Path: path, Change: ChangeRemoved, From: f})
+		case !reflect.DeepEqual(f, t):
+			changes = append(changes, ValueChange{Path: path, Change: ChangeModified, From: f, To: t})
+		}
+	}
+	for path, t := range toLeaves {
+		if _, ok := fromLeaves[path]; !ok {
+			changes = append(changes, ValueChange{Path: path, Change: ChangeAdded, To: t})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// flattenValues collects the leaves of a values tree keyed by their dotted
+// path. Empty maps are kept as leaves so that adding or removing them shows.
+func flattenValues(prefix string, v map[string]interface{}, out map[string]interface{}) {
+	for k, val := range v {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if m, ok := val.(map[string]interface{}); ok && len(m) > 0 {
+			flattenValues(path, m, out)
+			continue
+		}
+		out[path] = val
+	}
+}
+
+// DiffManifests compares two rendered manifests resource by resource. The
+// resources are identified by group/version, kind, namespace and name rather
+// than by their position in the manifest. The changes are sorted by resource.
+func DiffManifests(from, to string) []ResourceChange {
+	fromDocs := manifestsByResource(from)
+	toDocs := manifestsByResource(to)
+
+	changes := []ResourceChange{}
+	for key, f := range fromDocs {
+		t, ok := toDocs[key]
+		switch {
+		case !ok:
+			changes = append(changes, ResourceChange{Resource: key, Change: ChangeRemoved, Diff: unifiedDiff(key, f, "")})
+		case f != t:
+			changes = append(changes, ResourceChange{Resource: key, Change: ChangeModified, Diff: unifiedDiff(key, f, t)})
+		}
+	}
+	for key, t := range toDocs {
+		if _, ok := fromDocs[key]; !ok {
+			changes = append(changes, ResourceChange{Resource: key, Change: ChangeAdded, Diff: unifiedDiff(key, "", t)})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Resource < changes[j].Resource })
+	return changes
+}
+
+// manifestsByResource splits a manifest into its documents keyed by the
+// identity of the resource they describe.
+func manifestsByResource(manifest string) map[string]string {
+	docs := map[string]string{}
+	for name, doc := range releaseutil.SplitManifests(manifest) {
+		// Drop the "# Source:" comments, they change with the chart layout
+		// and not with the resource.
+		var lines []string
+		for _, l := range strings.Split(doc, "\n") {
+			if !strings.HasPrefix(l, "# Source: ") {
+				lines = append(lines, l)
+			}
+		}
+		doc = strings.TrimSpace(strings.Join(lines, "\n")) + "\n"
+
+		var head struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Metadata   struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		}
+		key := name
+		if err := yaml.Unmarshal([]byte(doc), &head); err == nil && head.Kind != "" {
+			key = fmt.Sprintf("%s/%s/%s/%s", head.APIVersion, head.Kind, head.Metadata.Namespace, head.Metadata.Name)
+		}
+		docs[key] = doc
+	}
+	return docs
+}
+
+func unifiedDiff(name, from, to string) string {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(from),
+		B:        splitLines(to),
+		FromFile: name,
+		ToFile:   name,
+		Context:  3,
+	})
+	if err != nil {
+		// The diff is only written to a buffer, which does not fail.
+		return ""
+	}
+	return diff
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return difflib.SplitLines(strings.TrimSuffix(s, "\n"))
+}
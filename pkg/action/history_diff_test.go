@@ -0,0 +1,146 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestDiffValues(t *testing.T) {
+	from := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.0",
+		},
+		"debug":    true,
+		"ports":    []interface{}{80},
+		"empty":    map[string]interface{}{},
+		"replicas": 1,
+	}
+	to := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.1",
+		},
+		"ports":    []interface{}{80, 443},
+		"replicas": 1,
+		"service":  map[string]interface{}{"type": "ClusterIP"},
+	}
+
+	assert.Equal(t, []ValueChange{
+		{Path: "debug", Change: ChangeRemoved, From: true},
+		{Path: "empty", Change: ChangeRemoved, From: map[string]interface{}{}},
+		{Path: "image.tag", Change: ChangeModified, From: "1.0", To: "1.1"},
+		{Path: "ports", Change: ChangeModified, From: []interface{}{80}, To: []interface{}{80, 443}},
+		{Path: "service.type", Change: ChangeAdded, To: "ClusterIP"},
+	}, DiffValues(from, to))
+
+	assert.Empty(t, DiffValues(from, from))
+}
+
+func TestDiffManifests(t *testing.T) {
+	from := `---
+# Source: chart/templates/cm.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: one
+---
+# Source: chart/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: secret
+  namespace: other
+`
+	// The resources are reordered and moved to another template, only the
+	// ConfigMap data and the added Service are actual changes.
+	to := `---
+# Source: chart/templates/svc.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+---
+# Source: chart/templates/all.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: secret
+  namespace: other
+---
+# Source: chart/templates/all.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: two
+`
+	changes := DiffManifests(from, to)
+	require.Len(t, changes, 2)
+
+	assert.Equal(t, "v1/ConfigMap//config", changes[0].Resource)
+	assert.Equal(t, ChangeModified, changes[0].Change)
+	assert.Contains(t, changes[0].Diff, "-  key: one\n+  key: two\n")
+
+	assert.Equal(t, "v1/Service//svc", changes[1].Resource)
+	assert.Equal(t, ChangeAdded, changes[1].Change)
+	assert.Contains(t, changes[1].Diff, "+kind: Service\n")
+	assert.NotContains(t, changes[1].Diff, "\n-")
+}
+
+func TestHistoryDiff(t *testing.T) {
+	config := actionConfigFixture(t)
+
+	rel1 := releaseStub()
+	rel1.Manifest = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n"
+	rel2 := releaseStub()
+	rel2.Version = 2
+	rel2.Config = map[string]interface{}{"name": "other"}
+	rel2.Chart.Metadata.Version = "0.2.0"
+	rel2.Manifest = ""
+	for _, r := range []*release.Release{rel1, rel2} {
+		require.NoError(t, config.Releases.Create(r))
+	}
+
+	client := NewHistoryDiff(config)
+	d, err := client.Run(rel1.Name, 1, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, d.From)
+	assert.Equal(t, 2, d.To)
+	assert.Equal(t, "hello-0.1.0", d.FromChart)
+	assert.Equal(t, "hello-0.2.0", d.ToChart)
+	assert.Equal(t, []ValueChange{{Path: "name", Change: ChangeModified, From: "value", To: "other"}}, d.UserValues)
+	assert.Contains(t, d.ComputedValues, ValueChange{Path: "name", Change: ChangeModified, From: "value", To: "other"})
+	require.Len(t, d.Resources, 1)
+	assert.Equal(t, ChangeRemoved, d.Resources[0].Change)
+
+	_, err = client.Run(rel1.Name, 1, 3)
+	assert.ErrorContains(t, err, "unable to get revision 3")
+
+	_, err = client.Run(rel1.Name, 0, 2)
+	assert.ErrorIs(t, err, errInvalidRevision)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/kube"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// HookEvents lists the hook events that can be run on demand.
+var HookEvents = []release.HookEvent{
+	release.HookPreInstall,
+	release.HookPostInstall,
+	release.HookPreDelete,
+	release.HookPostDelete,
+	release.HookPreUpgrade,
+	release.HookPostUpgrade,
+	release.HookPreRollback,
+	release.HookPostRollback,
+	release.HookTest,
+}
+
+// HookRun is the action for re-running the hooks of a deployed release.
+//
+// It provides the implementation of 'helm hook run'.
+type HookRun struct {
+	cfg *Configuration
+
+	// Event selects the hooks to run by the event they fire on.
+	Event release.HookEvent
+	// Names restricts the run to the hooks with the given names.
+	// When empty, every hook for Event is run.
+	Names        []string
+	Timeout      time.Duration
+	WaitStrategy kube.WaitStrategy
+}
+
+// NewHookRun creates a new HookRun object with the given configuration.
+func NewHookRun(cfg *Configuration) *HookRun {
+	return &HookRun{
+		cfg: cfg,
+	}
+}
+
+// Run executes 'helm hook run' against the given release.
+//
+// The hooks are executed through the same path used during install, upgrade,
+// rollback and uninstall, so their delete and output log policies apply. The
+// result of each execution is recorded in the stored release.
+func (h *HookRun) Run(name string) (*release.Release, error) {
+	if err := h.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, fmt.Errorf("hookRun: Release name is invalid: %s", name)
+	}
+
+	if !slices.Contains(HookEvents, h.Event) {
+		return nil, fmt.Errorf("invalid hook event %q", h.Event)
+	}
+
+	rel, err := h.cfg.Releases.Deployed(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []*release.Hook
+	for _, hk := range rel.Hooks {
+		if !slices.Contains(hk.Events, h.Event) {
+			continue
+		}
+		if len(h.Names) > 0 && !slices.Contains(h.Names, hk.Name) {
+			continue
+		}
+		selected = append(selected, hk)
+	}
+	for _, n := range h.Names {
+		if !slices.ContainsFunc(selected, func(hk *release.Hook) bool { return hk.Name == n }) {
+			return rel, fmt.Errorf("release %q has no %s hook named %q", name, h.Event, n)
+		}
+	}
+	if len(selected) == 0 {
+		return rel, fmt.Errorf("release %q has no %s hooks", name, h.Event)
+	}
+
+	slog.Debug("running hooks", "release", name, "event", h.Event, "count", len(selected))
+
+	serverSideApply := rel.ApplyMethod == string(release.ApplyMethodServerSideApply)
+	runErr := h.cfg.execHooks(rel, h.Event, selected, h.WaitStrategy, h.Timeout, serverSideApply)
+
+	if err := h.cfg.Releases.Update(rel); err != nil {
+		if runErr != nil {
+			return rel, fmt.Errorf("an error occurred while recording hook results. original hook error: %w: %w", runErr, err)
+		}
+		return rel, err
+	}
+	return rel, runErr
+}
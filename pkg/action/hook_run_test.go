@@ -0,0 +1,164 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func hookRunAction(t *testing.T) *HookRun {
+	t.Helper()
+	config := actionConfigFixture(t)
+	rel := releaseStub()
+	require.NoError(t, config.Releases.Create(rel))
+	return NewHookRun(config)
+}
+
+func TestHookRun(t *testing.T) {
+	client := hookRunAction(t)
+	client.Event = release.HookPostInstall
+
+	rel, err := client.Run("angry-panda")
+	require.NoError(t, err)
+
+	stored, err := client.cfg.Releases.Get("angry-panda", 1)
+	require.NoError(t, err)
+	assert.Len(t, stored.Hooks, len(rel.Hooks), "all hooks should be kept in the release")
+	for _, h := range stored.Hooks {
+		switch h.Name {
+		case "test-cm":
+			assert.Equal(t, release.HookPhaseSucceeded, h.LastRun.Phase)
+			assert.False(t, h.LastRun.StartedAt.IsZero())
+			assert.False(t, h.LastRun.CompletedAt.IsZero())
+		default:
+			assert.True(t, h.LastRun.StartedAt.IsZero(), "hook %s should not have run", h.Name)
+		}
+	}
+}
+
+func TestHookRunByName(t *testing.T) {
+	client := hookRunAction(t)
+	client.Event = release.HookTest
+	client.Names = []string{"finding-nemo"}
+
+	_, err := client.Run("angry-panda")
+	require.NoError(t, err)
+
+	stored, err := client.cfg.Releases.Get("angry-panda", 1)
+	require.NoError(t, err)
+	for _, h := range stored.Hooks {
+		if h.Name == "finding-nemo" {
+			assert.Equal(t, release.HookPhaseSucceeded, h.LastRun.Phase)
+		} else {
+			assert.True(t, h.LastRun.StartedAt.IsZero(), "hook %s should not have run", h.Name)
+		}
+	}
+}
+
+func TestHookRunDeployedRevision(t *testing.T) {
+	client := hookRunAction(t)
+	client.Event = release.HookPostInstall
+	failed := releaseStub()
+	failed.Version = 2
+	failed.Info.Status = release.StatusFailed
+	require.NoError(t, client.cfg.Releases.Create(failed))
+
+	rel, err := client.Run("angry-panda")
+	require.NoError(t, err)
+	assert.Equal(t, 1, rel.Version, "the hooks of the deployed revision should be run")
+
+	stored, err := client.cfg.Releases.Get("angry-panda", 2)
+	require.NoError(t, err)
+	for _, h := range stored.Hooks {
+		assert.True(t, h.LastRun.StartedAt.IsZero(), "hook %s of the failed revision should not have run", h.Name)
+	}
+}
+
+func TestHookRunFailure(t *testing.T) {
+	client := hookRunAction(t)
+	client.Event = release.HookPostInstall
+	client.cfg.KubeClient = &kubefake.FailingKubeClient{
+		PrintingKubeClient:   kubefake.PrintingKubeClient{Out: io.Discard},
+		WatchUntilReadyError: errors.New("job failed"),
+	}
+
+	_, err := client.Run("angry-panda")
+	require.ErrorContains(t, err, "job failed")
+
+	stored, err := client.cfg.Releases.Get("angry-panda", 1)
+	require.NoError(t, err)
+	for _, h := range stored.Hooks {
+		if h.Name == "test-cm" {
+			assert.Equal(t, release.HookPhaseFailed, h.LastRun.Phase)
+		}
+	}
+}
+
+func TestHookRunErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		release string
+		event   release.HookEvent
+		names   []string
+		errMsg  string
+	}{
+		{
+			name:    "invalid event",
+			release: "angry-panda",
+			event:   "post-nothing",
+			errMsg:  `invalid hook event "post-nothing"`,
+		},
+		{
+			name:    "no hooks for event",
+			release: "angry-panda",
+			event:   release.HookPreUpgrade,
+			errMsg:  `release "angry-panda" has no pre-upgrade hooks`,
+		},
+		{
+			name:    "unknown hook name",
+			release: "angry-panda",
+			event:   release.HookPostInstall,
+			names:   []string{"test-cm", "missing"},
+			errMsg:  `release "angry-panda" has no post-install hook named "missing"`,
+		},
+		{
+			name:    "missing release",
+			release: "happy-panda",
+			event:   release.HookPostInstall,
+			errMsg:  `"happy-panda" has no deployed releases`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := hookRunAction(t)
+			client.Event = tt.event
+			client.Names = tt.names
+
+			_, err := client.Run(tt.release)
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
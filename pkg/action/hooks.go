@@ -44,65 +44,23 @@ func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent,
 		}
 	}
 
+	return cfg.execHooks(rl, hook, executingHooks, waitStrategy, timeout, serverSideApply)
+}
+
+// execHooks executes the given hooks of a release for a hook event. The
+// other hooks of the release are left as they are.
+func (cfg *Configuration) execHooks(rl *release.Release, hook release.HookEvent, executingHooks []*release.Hook, waitStrategy kube.WaitStrategy, timeout time.Duration, serverSideApply bool) error {
 	// hooke are pre-ordered by kind, so keep order stable
 	sort.Stable(hookByWeight(executingHooks))
 
 	for i, h := range executingHooks {
-		// Set default delete policy to before-hook-creation
-		cfg.hookSetDeletePolicy(h)
-
-		if err := cfg.deleteHookByPolicy(h, release.HookBeforeHookCreation, waitStrategy, timeout); err != nil {
-			return err
-		}
-
-		resources, err := cfg.KubeClient.Build(bytes.NewBufferString(h.Manifest), true)
+		resources, waiter, err := cfg.startHook(h, hook, waitStrategy, timeout, serverSideApply, func() { cfg.recordRelease(rl) })
 		if err != nil {
-			return fmt.Errorf("unable to build kubernetes object for %s hook %s: %w", hook, h.Path, err)
-		}
-
-		// Record the time at which the hook was applied to the cluster
-		h.LastRun = release.HookExecution{
-			StartedAt: helmtime.Now(),
-			Phase:     release.HookPhaseRunning,
-		}
-		cfg.recordRelease(rl)
-
-		// As long as the implementation of WatchUntilReady does not panic, HookPhaseFailed or HookPhaseSucceeded
-		// should always be set by this function. If we fail to do that for any reason, then HookPhaseUnknown is
-		// the most appropriate value to surface.
-		h.LastRun.Phase = release.HookPhaseUnknown
-
-		// Create hook resources
-		if _, err := cfg.KubeClient.Create(
-			resources,
-			kube.ClientCreateOptionServerSideApply(serverSideApply, false)); err != nil {
-			h.LastRun.CompletedAt = helmtime.Now()
-			h.LastRun.Phase = release.HookPhaseFailed
-			return fmt.Errorf("warning: Hook %s %s failed: %w", hook, h.Path, err)
-		}
-
-		waiter, err := cfg.KubeClient.GetWaiter(waitStrategy)
-		if err != nil {
-			return fmt.Errorf("unable to get waiter: %w", err)
+			return err
 		}
 		// Watch hook resources until they have completed
-		err = waiter.WatchUntilReady(resources, timeout)
-		// Note the time of success/failure
-		h.LastRun.CompletedAt = helmtime.Now()
-		// Mark hook as succeeded or failed
-		if err != nil {
-			h.LastRun.Phase = release.HookPhaseFailed
-			// If a hook is failed, check the annotation of the hook to determine if we should copy the logs client side
-			if errOutputting := cfg.outputLogsByPolicy(h, rl.Namespace, release.HookOutputOnFailed); errOutputting != nil {
-				// We log the error here as we want to propagate the hook failure upwards to the release object.
-				log.Printf("error outputting logs for hook failure: %v", errOutputting)
-			}
-			// If a hook is failed, check the annotation of the hook to determine whether the hook should be deleted
-			// under failed condition. If so, then clear the corresponding resource object in the hook
-			if errDeleting := cfg.deleteHookByPolicy(h, release.HookFailed, waitStrategy, timeout); errDeleting != nil {
-				// We log the error here as we want to propagate the hook failure upwards to the release object.
-				log.Printf("error deleting the hook resource on hook failure: %v", errDeleting)
-			}
+		if err := cfg.watchHook(h, waiter, resources, timeout); err != nil {
+			cfg.cleanUpFailedHook(h, rl.Namespace, waitStrategy, timeout)
 
 			// If a hook is failed, check the annotation of the previous successful hooks to determine whether the hooks
 			// should be deleted under succeeded condition.
@@ -112,18 +70,12 @@ func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent,
 
 			return err
 		}
-		h.LastRun.Phase = release.HookPhaseSucceeded
 	}
 
 	// If all hooks are successful, check the annotation of each hook to determine whether the hook should be deleted
 	// or output should be logged under succeeded condition. If so, then clear the corresponding resource object in each hook
 	for i := len(executingHooks) - 1; i >= 0; i-- {
-		h := executingHooks[i]
-		if err := cfg.outputLogsByPolicy(h, rl.Namespace, release.HookOutputOnSucceeded); err != nil {
-			// We log here as we still want to attempt hook resource deletion even if output logging fails.
-			log.Printf("error outputting logs for hook failure: %v", err)
-		}
-		if err := cfg.deleteHookByPolicy(h, release.HookSucceeded, waitStrategy, timeout); err != nil {
+		if err := cfg.cleanUpSucceededHook(executingHooks[i], rl.Namespace, waitStrategy, timeout); err != nil {
 			return err
 		}
 	}
@@ -131,6 +83,89 @@ func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent,
 	return nil
 }
 
+// startHook creates the resources of a hook for a hook event, recording the
+// start of its execution on the hook, and returns them with the waiter to
+// watch them with. record is called once the hook is marked as running.
+func (cfg *Configuration) startHook(h *release.Hook, hook release.HookEvent, waitStrategy kube.WaitStrategy, timeout time.Duration, serverSideApply bool, record func()) (kube.ResourceList, kube.Waiter, error) {
+	// Set default delete policy to before-hook-creation
+	cfg.hookSetDeletePolicy(h)
+
+	if err := cfg.deleteHookByPolicy(h, release.HookBeforeHookCreation, waitStrategy, timeout); err != nil {
+		return nil, nil, err
+	}
+
+	resources, err := cfg.KubeClient.Build(bytes.NewBufferString(h.Manifest), true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to build kubernetes object for %s hook %s: %w", hook, h.Path, err)
+	}
+
+	// Record the time at which the hook was applied to the cluster
+	h.LastRun = release.HookExecution{
+		StartedAt: helmtime.Now(),
+		Phase:     release.HookPhaseRunning,
+	}
+	record()
+
+	// As long as the implementation of WatchUntilReady does not panic, HookPhaseFailed or HookPhaseSucceeded
+	// should always be set by this function. If we fail to do that for any reason, then HookPhaseUnknown is
+	// the most appropriate value to surface.
+	h.LastRun.Phase = release.HookPhaseUnknown
+
+	// Create hook resources
+	if _, err := cfg.KubeClient.Create(
+		resources,
+		kube.ClientCreateOptionServerSideApply(serverSideApply, false)); err != nil {
+		h.LastRun.CompletedAt = helmtime.Now()
+		h.LastRun.Phase = release.HookPhaseFailed
+		return nil, nil, fmt.Errorf("warning: Hook %s %s failed: %w", hook, h.Path, err)
+	}
+
+	waiter, err := cfg.KubeClient.GetWaiter(waitStrategy)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to get waiter: %w", err)
+	}
+	return resources, waiter, nil
+}
+
+// watchHook watches the resources of a started hook until they have
+// completed, and records whether the hook succeeded or failed.
+func (cfg *Configuration) watchHook(h *release.Hook, waiter kube.Waiter, resources kube.ResourceList, timeout time.Duration) error {
+	err := waiter.WatchUntilReady(resources, timeout)
+	// Note the time of success/failure
+	h.LastRun.CompletedAt = helmtime.Now()
+	// Mark hook as succeeded or failed
+	if err != nil {
+		h.LastRun.Phase = release.HookPhaseFailed
+		return err
+	}
+	h.LastRun.Phase = release.HookPhaseSucceeded
+	return nil
+}
+
+// cleanUpFailedHook outputs the logs of a failed hook and deletes it, when
+// its policies instruct it to. Errors are logged, so that the failure of the
+// hook is the one propagated.
+func (cfg *Configuration) cleanUpFailedHook(h *release.Hook, namespace string, waitStrategy kube.WaitStrategy, timeout time.Duration) {
+	if err := cfg.outputLogsByPolicy(h, namespace, release.HookOutputOnFailed); err != nil {
+		log.Printf("error outputting logs for hook failure: %v", err)
+	}
+	// If a hook is failed, check the annotation of the hook to determine whether the hook should be deleted
+	// under failed condition. If so, then clear the corresponding resource object in the hook
+	if err := cfg.deleteHookByPolicy(h, release.HookFailed, waitStrategy, timeout); err != nil {
+		log.Printf("error deleting the hook resource on hook failure: %v", err)
+	}
+}
+
+// cleanUpSucceededHook outputs the logs of a succeeded hook and deletes it,
+// when its policies instruct it to.
+func (cfg *Configuration) cleanUpSucceededHook(h *release.Hook, namespace string, waitStrategy kube.WaitStrategy, timeout time.Duration) error {
+	if err := cfg.outputLogsByPolicy(h, namespace, release.HookOutputOnSucceeded); err != nil {
+		// We log here as we still want to attempt hook resource deletion even if output logging fails.
+		log.Printf("error outputting logs for hook %s: %v", h.Name, err)
+	}
+	return cfg.deleteHookByPolicy(h, release.HookSucceeded, waitStrategy, timeout)
+}
+
 // hookByWeight is a sorter for hooks
 type hookByWeight []*release.Hook
 
@@ -151,26 +186,29 @@ func (cfg *Configuration) deleteHookByPolicy(h *release.Hook, policy release.Hoo
 		return nil
 	}
 	if cfg.hookHasDeletePolicy(h, policy) {
-		resources, err := cfg.KubeClient.Build(bytes.NewBufferString(h.Manifest), false)
-		if err != nil {
-			return fmt.Errorf("unable to build kubernetes object for deleting hook %s: %w", h.Path, err)
-		}
-		_, errs := cfg.KubeClient.Delete(resources)
-		if len(errs) > 0 {
-			return joinErrors(errs, "; ")
-		}
-
-		waiter, err := cfg.KubeClient.GetWaiter(waitStrategy)
-		if err != nil {
-			return err
-		}
-		if err := waiter.WaitForDelete(resources, timeout); err != nil {
-			return err
-		}
+		return cfg.deleteHook(h, waitStrategy, timeout)
 	}
 	return nil
 }
 
+// deleteHook deletes the resources of a hook and waits until they are gone.
+func (cfg *Configuration) deleteHook(h *release.Hook, waitStrategy kube.WaitStrategy, timeout time.Duration) error {
+	resources, err := cfg.KubeClient.Build(bytes.NewBufferString(h.Manifest), false)
+	if err != nil {
+		return fmt.Errorf("unable to build kubernetes object for deleting hook %s: %w", h.Path, err)
+	}
+	_, errs := cfg.KubeClient.Delete(resources)
+	if len(errs) > 0 {
+		return joinErrors(errs, "; ")
+	}
+
+	waiter, err := cfg.KubeClient.GetWaiter(waitStrategy)
+	if err != nil {
+		return err
+	}
+	return waiter.WaitForDelete(resources, timeout)
+}
+
 // deleteHooksByPolicy deletes all hooks if the hook policy instructs it to
 func (cfg *Configuration) deleteHooksByPolicy(hooks []*release.Hook, policy release.HookDeletePolicy, waitStrategy kube.WaitStrategy, timeout time.Duration) error {
 	for _, h := range hooks {
@@ -125,6 +125,9 @@ type Install struct {
 	// TakeOwnership will ignore the check for helm annotations and take ownership of the resources.
 	TakeOwnership bool
 	PostRenderer  postrenderer.PostRenderer
+	// ValuesFrom are the references to the Secrets and ConfigMaps some of
+	// the values were read from. They are recorded in the release.
+	ValuesFrom []string
 	// Lock to control raceconditions when the process receives a SIGTERM
 	Lock sync.Mutex
 }
@@ -338,7 +341,8 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 	rel := i.createRelease(chrt, vals, i.Labels)
 
 	var manifestDoc *bytes.Buffer
-	rel.Hooks, manifestDoc, rel.Info.Notes, err = i.cfg.renderResources(chrt, valuesToRender, i.ReleaseName, i.OutputDir, i.SubNotes, i.UseReleaseName, i.IncludeCRDs, i.PostRenderer, interactWithRemote, i.EnableDNS, i.HideSecret)
+	var sources manifestSources
+	rel.Hooks, manifestDoc, rel.Info.Notes, sources, err = i.cfg.renderResources(chrt, valuesToRender, i.ReleaseName, i.OutputDir, i.SubNotes, i.UseReleaseName, i.IncludeCRDs, i.PostRenderer, interactWithRemote, i.EnableDNS, i.HideSecret)
 	// Even for errors, attach this if available
 	if manifestDoc != nil {
 		rel.Manifest = manifestDoc.String()
@@ -356,6 +360,7 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 	var toBeAdopted kube.ResourceList
 	resources, err := i.cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), !i.DisableOpenAPIValidation)
 	if err != nil {
+		err = i.cfg.locateBuildError(rel.Manifest, sources, !i.DisableOpenAPIValidation, err)
 		return nil, fmt.Errorf("unable to build kubernetes objects from release manifest: %w", err)
 	}
 
@@ -433,7 +438,7 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 
 	rel, err = i.performInstallCtx(ctx, rel, toBeAdopted, resources)
 	if err != nil {
-		rel, err = i.failRelease(rel, err)
+		rel, err = i.failRelease(rel, sources.locateResourceError(rel.Manifest, err))
 	}
 	return rel, err
 }
@@ -590,18 +595,20 @@ func (i *Install) createRelease(chrt *chart.Chart, rawVals map[string]interface{
 	ts := i.cfg.Now()
 
 	r := &release.Release{
-		Name:      i.ReleaseName,
-		Namespace: i.Namespace,
-		Chart:     chrt,
-		Config:    rawVals,
+		Name:       i.ReleaseName,
+		Namespace:  i.Namespace,
+		Chart:      chrt,
+		Config:     rawVals,
+		ValuesFrom: i.ValuesFrom,
 		Info: &release.Info{
 			FirstDeployed: ts,
 			LastDeployed:  ts,
 			Status:        release.StatusUnknown,
 		},
-		Version:     1,
-		Labels:      labels,
-		ApplyMethod: string(determineReleaseSSApplyMethod(i.ServerSideApply)),
+		Version:         1,
+		Labels:          labels,
+		ApplyMethod:     string(determineReleaseSSApplyMethod(i.ServerSideApply)),
+		ChartAPIVersion: chrt.Metadata.APIVersion,
 	}
 
 	return r
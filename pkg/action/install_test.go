@@ -152,6 +152,7 @@ func TestInstallRelease(t *testing.T) {
 	is.NotEqual(len(rel.Manifest), 0)
 	is.Contains(rel.Manifest, "---\n# Source: hello/templates/hello\nhello: world")
 	is.Equal(rel.Info.Description, "Install complete")
+	is.Equal("v1", rel.ChartAPIVersion)
 
 	// Detecting previous bug where context termination after successful release
 	// caused release to fail.
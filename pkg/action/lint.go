@@ -22,7 +22,10 @@ import (
 	"path/filepath"
 	"strings"
 
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/engine"
+	"helm.sh/helm/v4/pkg/kube/openapi"
 	"helm.sh/helm/v4/pkg/lint"
 	"helm.sh/helm/v4/pkg/lint/support"
 )
@@ -36,7 +39,41 @@ type Lint struct {
 	WithSubcharts        bool
 	Quiet                bool
 	SkipSchemaValidation bool
-	KubeVersion          *chartutil.KubeVersion
+	// KubeVersion is the Kubernetes version the charts are linted for. When
+	// set, it replaces the Kubernetes versions of the matrix of the lint
+	// configuration.
+	KubeVersion *chartutil.KubeVersion
+	// LookupProvider, when set, serves the 'lookup' template function, e.g.
+	// from fixtures. Without it, 'lookup' returns empty objects.
+	LookupProvider engine.ClientProvider
+	// PluginDirs are the directories the template function plugins required
+	// by the charts, and the lint plugins run against them, are loaded from.
+	PluginDirs []string
+	// RenderConcurrency is the maximum number of templates rendered at the
+	// same time, see engine.Engine.Concurrency.
+	RenderConcurrency int
+	// WorkloadRules checks the rendered workloads for common risks, e.g.
+	// privileged containers. Resources skip the rules listed in their
+	// helm.sh/lint-ignore annotation.
+	WorkloadRules bool
+	// ManifestSchemas validates the rendered resources offline against the
+	// OpenAPI schemas of their kinds, for the Kubernetes version the charts
+	// are linted for, and against the schemas of the CRDs of the charts.
+	ManifestSchemas bool
+	// OpenAPISpec is an OpenAPI spec, or a directory of specs by Kubernetes
+	// version, replacing the schemas bundled with Helm. See openapi.Options.
+	OpenAPISpec string
+	// CRDFiles are files of CRDs whose schemas are added to the ones of the
+	// CRDs of the charts.
+	CRDFiles []string
+	// ConfigFile is the lint configuration file applied to the charts. When
+	// empty, the .helmlint.yaml file of each chart directory is applied, if
+	// any. See lint.Config.
+	ConfigFile string
+	// Fix rewrites the Chart.yaml and values.yaml files of the charts in
+	// directories, fixing the mechanical findings, before linting them. See
+	// lint.FixChart.
+	Fix bool
 }
 
 // LintResult is the result of Lint
@@ -44,6 +81,8 @@ type LintResult struct {
 	TotalChartsLinted int
 	Messages          []support.Message
 	Errors            []error
+	// Fixes are the changes made to the charts when fixing them.
+	Fixes []lint.Fix
 }
 
 // NewLint creates a new Lint object with the given configuration.
@@ -59,15 +98,24 @@ func (l *Lint) Run(paths []string, vals map[string]interface{}) *LintResult {
 	}
 	result := &LintResult{}
 	for _, path := range paths {
-		linter, err := lintChart(path, vals, l.Namespace, l.KubeVersion, l.SkipSchemaValidation)
+		if fi, err := os.Stat(path); l.Fix && err == nil && fi.IsDir() {
+			fixes, err := lint.FixChart(path)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("unable to fix chart %s: %w", path, err))
+				continue
+			}
+			result.Fixes = append(result.Fixes, fixes...)
+		}
+
+		messages, err := l.lintWithConfig(path, vals)
 		if err != nil {
 			result.Errors = append(result.Errors, err)
 			continue
 		}
 
-		result.Messages = append(result.Messages, linter.Messages...)
+		result.Messages = append(result.Messages, messages...)
 		result.TotalChartsLinted++
-		for _, msg := range linter.Messages {
+		for _, msg := range messages {
 			if msg.Severity >= lowestTolerance {
 				result.Errors = append(result.Errors, msg.Err)
 			}
@@ -76,6 +124,143 @@ func (l *Lint) Run(paths []string, vals map[string]interface{}) *LintResult {
 	return result
 }
 
+// lintWithConfig lints a chart, applying its lint configuration. The chart
+// is linted for every combination of the values files and Kubernetes
+// versions of the matrix of the configuration. A message raised for some of
+// the combinations only names them.
+func (l *Lint) lintWithConfig(path string, vals map[string]interface{}) ([]support.Message, error) {
+	config, err := l.config(path)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		schemas, err := l.manifestSchemas(l.KubeVersion)
+		if err != nil {
+			return nil, err
+		}
+		linter, err := lintChart(path, vals, l.Namespace, l.linterOptions(l.KubeVersion, l.WorkloadRules, schemas)...)
+		return linter.Messages, err
+	}
+
+	valuesFiles := config.Matrix.Values
+	if len(valuesFiles) == 0 {
+		valuesFiles = []string{""}
+	}
+	kubeVersions := []*chartutil.KubeVersion{l.KubeVersion}
+	matrixKubeVersions := l.KubeVersion == nil && len(config.Matrix.KubeVersions) > 0
+	if matrixKubeVersions {
+		kubeVersions = nil
+		for _, v := range config.Matrix.KubeVersions {
+			// The versions were validated when loading the configuration.
+			kubeVersion, _ := chartutil.ParseKubeVersion(v)
+			kubeVersions = append(kubeVersions, kubeVersion)
+		}
+	}
+	workloadRules := l.WorkloadRules || config.EnablesWorkloadRules()
+
+	type found struct {
+		msg          support.Message
+		combinations []string
+	}
+	var messages []*found
+	byKey := map[string]*found{}
+	for _, valuesFile := range valuesFiles {
+		combinationVals := vals
+		if valuesFile != "" {
+			fileVals, err := chartutil.ReadValuesFile(valuesFile)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read values file %s: %w", valuesFile, err)
+			}
+			combinationVals = loader.MergeMaps(fileVals, vals)
+		}
+		for _, kubeVersion := range kubeVersions {
+			schemas, err := l.manifestSchemas(kubeVersion)
+			if err != nil {
+				return nil, err
+			}
+			linter, err := lintChart(path, combinationVals, l.Namespace, l.linterOptions(kubeVersion, workloadRules, schemas)...)
+			if err != nil {
+				return nil, err
+			}
+
+			var combination []string
+			if valuesFile != "" {
+				combination = append(combination, "values "+relativePath(path, valuesFile))
+			}
+			if matrixKubeVersions {
+				combination = append(combination, "kube version "+kubeVersion.Version)
+			}
+			for _, msg := range config.Apply(linter.Messages, l.WorkloadRules) {
+				key := fmt.Sprintf("%s\x00%d\x00%s\x00%s", msg.RuleID, msg.Severity, msg.Path, msg.Err)
+				f, ok := byKey[key]
+				if !ok {
+					f = &found{msg: msg}
+					byKey[key] = f
+					messages = append(messages, f)
+				}
+				f.combinations = append(f.combinations, strings.Join(combination, ", "))
+			}
+		}
+	}
+
+	out := make([]support.Message, 0, len(messages))
+	for _, f := range messages {
+		if len(f.combinations) < len(valuesFiles)*len(kubeVersions) {
+			f.msg.Err = fmt.Errorf("%w (with %s)", f.msg.Err, strings.Join(f.combinations, "; "))
+		}
+		out = append(out, f.msg)
+	}
+	return out, nil
+}
+
+// linterOptions returns the options a chart is linted with for a Kubernetes
+// version.
+func (l *Lint) linterOptions(kubeVersion *chartutil.KubeVersion, workloadRules bool, schemas *openapi.Validator) []lint.LinterOption {
+	return []lint.LinterOption{
+		lint.WithKubeVersion(kubeVersion),
+		lint.WithSkipSchemaValidation(l.SkipSchemaValidation),
+		lint.WithLookupProvider(l.LookupProvider),
+		lint.WithPluginDirs(l.PluginDirs),
+		lint.WithRenderConcurrency(l.RenderConcurrency),
+		lint.WithWorkloadRules(workloadRules),
+		lint.WithManifestSchemas(schemas),
+	}
+}
+
+// manifestSchemas returns the validator of the manifest schemas for a
+// Kubernetes version, or nil when the manifests are not validated. Without a
+// Kubernetes version, the one of the bundled schemas is used.
+func (l *Lint) manifestSchemas(kubeVersion *chartutil.KubeVersion) (*openapi.Validator, error) {
+	if !l.ManifestSchemas {
+		return nil, nil
+	}
+	opts := openapi.Options{Spec: l.OpenAPISpec, CRDFiles: l.CRDFiles}
+	if kubeVersion != nil {
+		opts.KubeVersion = kubeVersion.Version
+	}
+	return openapi.New(opts)
+}
+
+// config returns the lint configuration of a chart, or nil when it has none.
+func (l *Lint) config(path string) (*lint.Config, error) {
+	if l.ConfigFile != "" {
+		return lint.LoadConfig(l.ConfigFile)
+	}
+	filename := filepath.Join(path, lint.ConfigFileName)
+	if _, err := os.Stat(filename); err != nil {
+		return nil, nil
+	}
+	return lint.LoadConfig(filename)
+}
+
+// relativePath returns a path relative to a directory, when it is in it.
+func relativePath(dir, name string) string {
+	if rel, err := filepath.Rel(dir, name); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return name
+}
+
 // HasWarningsOrErrors checks is LintResult has any warnings or errors
 func HasWarningsOrErrors(result *LintResult) bool {
 	for _, msg := range result.Messages {
@@ -86,7 +271,7 @@ func HasWarningsOrErrors(result *LintResult) bool {
 	return len(result.Errors) > 0
 }
 
-func lintChart(path string, vals map[string]interface{}, namespace string, kubeVersion *chartutil.KubeVersion, skipSchemaValidation bool) (support.Linter, error) {
+func lintChart(path string, vals map[string]interface{}, namespace string, options ...lint.LinterOption) (support.Linter, error) {
 	var chartPath string
 	linter := support.Linter{}
 
@@ -125,11 +310,5 @@ func lintChart(path string, vals map[string]interface{}, namespace string, kubeV
 		return linter, fmt.Errorf("unable to check Chart.yaml file in chart: %w", err)
 	}
 
-	return lint.RunAll(
-		chartPath,
-		vals,
-		namespace,
-		lint.WithKubeVersion(kubeVersion),
-		lint.WithSkipSchemaValidation(skipSchemaValidation),
-	), nil
+	return lint.RunAll(chartPath, vals, namespace, options...), nil
 }
@@ -17,7 +17,11 @@ limitations under the License.
 package action
 
 import (
+	"strings"
 	"testing"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/lint"
 )
 
 var (
@@ -83,7 +87,7 @@ func TestLintChart(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := lintChart(tt.chartPath, map[string]interface{}{}, namespace, nil, tt.skipSchemaValidation)
+			_, err := lintChart(tt.chartPath, map[string]interface{}{}, namespace, lint.WithSkipSchemaValidation(tt.skipSchemaValidation))
 			switch {
 			case err != nil && !tt.err:
 				t.Errorf("%s", err)
@@ -163,3 +167,79 @@ func TestLint_ChartWithWarnings(t *testing.T) {
 		}
 	})
 }
+
+func TestLint_ChartWithConfig(t *testing.T) {
+	chart := "testdata/charts/chart-with-lint-config"
+
+	testLint := NewLint()
+	result := testLint.Run([]string{chart}, values)
+	if len(result.Errors) > 0 {
+		t.Fatal(result.Errors)
+	}
+	var got []string
+	for _, msg := range result.Messages {
+		got = append(got, msg.Error())
+	}
+	want := []string{
+		"[WARNING] Chart.yaml: icon is recommended",
+		"[WARNING] templates/deployment.yaml:19: autoscaling/v2beta2 HorizontalPodAutoscaler is deprecated in v1.23+, unavailable in v1.26+; use autoscaling/v2 HorizontalPodAutoscaler (with values ci/pinned-values.yaml, kube version v1.24.0; values ci/latest-values.yaml, kube version v1.24.0)",
+		`[WARNING] templates/deployment.yaml:16: container "web" uses the image "nginx:latest" with the latest tag (with values ci/latest-values.yaml, kube version v1.24.0; values ci/latest-values.yaml, kube version v1.30.0)`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected messages:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	t.Run("values given on the command line take precedence", func(t *testing.T) {
+		result := testLint.Run([]string{chart}, map[string]interface{}{"image": "nginx:1.28"})
+		for _, msg := range result.Messages {
+			if strings.Contains(msg.Error(), "latest tag") {
+				t.Errorf("unexpected message %s", msg)
+			}
+		}
+	})
+
+	t.Run("kube version given on the command line replaces the ones of the matrix", func(t *testing.T) {
+		testLint := NewLint()
+		testLint.KubeVersion = &chartutil.KubeVersion{Version: "v1.30.0", Major: "1", Minor: "30"}
+		result := testLint.Run([]string{chart}, values)
+		var got []string
+		for _, msg := range result.Messages {
+			got = append(got, msg.Error())
+		}
+		want := []string{
+			"[WARNING] Chart.yaml: icon is recommended",
+			`[WARNING] templates/deployment.yaml:16: container "web" uses the image "nginx:latest" with the latest tag (with values ci/latest-values.yaml)`,
+		}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("unexpected messages:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+	})
+
+	t.Run("configuration file given instead of the one of the chart", func(t *testing.T) {
+		testLint := NewLint()
+		testLint.ConfigFile = "testdata/lint-config.yaml"
+		result := testLint.Run([]string{chart}, values)
+		if len(result.Errors) != 1 || result.Errors[0].Error() != "icon is recommended" {
+			t.Errorf("expected the icon-missing error only, got %v", result.Errors)
+		}
+	})
+
+	t.Run("invalid configuration file", func(t *testing.T) {
+		testLint := NewLint()
+		testLint.ConfigFile = "testdata/no-such-lint-config.yaml"
+		result := testLint.Run([]string{chart}, values)
+		if len(result.Errors) != 1 || result.TotalChartsLinted != 0 {
+			t.Errorf("expected one error, got %v", result.Errors)
+		}
+	})
+}
+
+func TestLint_ManifestSchemasForAnotherKubeVersion(t *testing.T) {
+	testLint := NewLint()
+	testLint.ManifestSchemas = true
+	testLint.KubeVersion = &chartutil.KubeVersion{Version: "v1.20.0", Major: "1", Minor: "20"}
+	result := testLint.Run([]string{chart1MultipleChartLint}, values)
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "an OpenAPI spec of v1.20 is required") {
+		t.Errorf("expected the bundled schemas not to be used for another kube version, got %v", result.Errors)
+	}
+}
@@ -20,17 +20,24 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"golang.org/x/term"
 	"sigs.k8s.io/yaml"
 
-	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	"helm.sh/helm/v4/pkg/chart/loader"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/lint"
 	"helm.sh/helm/v4/pkg/provenance"
+	"helm.sh/helm/v4/pkg/unittest"
 )
 
 // Package is the action for packaging a chart.
@@ -46,6 +53,13 @@ type Package struct {
 	AppVersion       string
 	Destination      string
 	DependencyUpdate bool
+	// Reproducible packages the chart in an archive which only depends on
+	// its contents. When the archive already exists, the rebuild is
+	// verified to match it instead of replacing it.
+	Reproducible bool
+	// SourceDateEpoch is the modification time of the files of a
+	// reproducible archive. The zero time stands for the Unix epoch.
+	SourceDateEpoch time.Time
 
 	RepositoryConfig      string
 	RepositoryCache       string
@@ -74,6 +88,8 @@ func (p *Package) Run(path string, _ map[string]interface{}) (string, error) {
 		return "", err
 	}
 
+	excludeFromPackage(ch)
+
 	// If version is set, modify the version.
 	if p.Version != "" {
 		ch.Metadata.Version = p.Version
@@ -105,7 +121,12 @@ func (p *Package) Run(path string, _ map[string]interface{}) (string, error) {
 		dest = p.Destination
 	}
 
-	name, err := chartutil.Save(ch, dest)
+	var name string
+	if p.Reproducible {
+		name, err = p.saveReproducible(ch, dest)
+	} else {
+		name, err = chartutil.Save(ch, dest)
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to save: %w", err)
 	}
@@ -117,6 +138,50 @@ func (p *Package) Run(path string, _ map[string]interface{}) (string, error) {
 	return name, err
 }
 
+// excludeFromPackage removes the files of a chart, and of its dependencies,
+// that are only used while developing it: the unit test suites of its tests/
+// directory and its lint configuration.
+func excludeFromPackage(c *chart.Chart) {
+	c.Files = slices.DeleteFunc(c.Files, func(f *chart.File) bool {
+		return strings.HasPrefix(f.Name, unittest.TestsDir+"/") || f.Name == lint.ConfigFileName
+	})
+	for _, dep := range c.Dependencies() {
+		excludeFromPackage(dep)
+	}
+}
+
+// saveReproducible saves a reproducible archive of the chart in dest. When
+// the archive already exists, the chart is saved in a temporary directory and
+// the digests of both archives are compared.
+func (p *Package) saveReproducible(ch *chart.Chart, dest string) (string, error) {
+	name := filepath.Join(dest, fmt.Sprintf("%s-%s.tgz", ch.Name(), ch.Metadata.Version))
+	want, err := provenance.DigestFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return chartutil.SaveReproducible(ch, dest, p.SourceDateEpoch)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	tmp, err := os.MkdirTemp("", "helm-package-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+	rebuilt, err := chartutil.SaveReproducible(ch, tmp, p.SourceDateEpoch)
+	if err != nil {
+		return "", err
+	}
+	got, err := provenance.DigestFile(rebuilt)
+	if err != nil {
+		return "", err
+	}
+	if got != want {
+		return "", fmt.Errorf("rebuilt chart does not match the existing archive %s: digest sha256:%s, want sha256:%s", name, got, want)
+	}
+	return name, nil
+}
+
 // validateVersion Verify that version is a Version, and error out if it is not.
 func validateVersion(ver string) error {
 	if _, err := semver.NewVersion(ver); err != nil {
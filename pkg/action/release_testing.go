@@ -17,18 +17,25 @@ limitations under the License.
 package action
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"slices"
 	"sort"
+	"sync"
 	"time"
 
+	"go.yaml.in/yaml/v3"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
 
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/kube"
 	release "helm.sh/helm/v4/pkg/release/v1"
+	helmtime "helm.sh/helm/v4/pkg/time"
 )
 
 const (
@@ -45,7 +52,36 @@ type ReleaseTesting struct {
 	// Used for fetching logs from test pods
 	Namespace string
 	Filters   map[string][]string
+	// Selector is a label selector matched against the labels of each test
+	// hook's manifest. Only matching tests are run.
+	Selector  string
 	HideNotes bool
+	// Parallel is the maximum number of tests of the same weight run at
+	// the same time. Tests with different weights are still run in order
+	// of their weight. Values below 2 run the tests one by one.
+	Parallel int
+	// Retries is the number of times a failed test is re-run before it is
+	// reported as failed.
+	Retries int
+
+	mu       sync.Mutex
+	attempts map[string]int
+}
+
+// TestResult is the outcome of a single test of a release.
+type TestResult struct {
+	Name        string            `json:"name"`
+	Path        string            `json:"path,omitempty"`
+	Phase       release.HookPhase `json:"phase"`
+	StartedAt   helmtime.Time     `json:"started_at"`
+	CompletedAt helmtime.Time     `json:"completed_at"`
+	Duration    time.Duration     `json:"duration"`
+	// Attempts is the number of times the test was run, including retries.
+	Attempts int `json:"attempts"`
+	// Logs holds the captured logs of the test pod, if requested.
+	Logs string `json:"logs,omitempty"`
+	// LogsError is the error capturing the logs of the test pod, if any.
+	LogsError string `json:"logs_error,omitempty"`
 }
 
 // NewReleaseTesting creates a new ReleaseTesting object with the given configuration.
@@ -66,47 +102,196 @@ func (r *ReleaseTesting) Run(name string) (*release.Release, error) {
 		return nil, fmt.Errorf("releaseTest: Release name is invalid: %s", name)
 	}
 
+	selector, err := labels.Parse(r.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid test selector %q: %w", r.Selector, err)
+	}
+
 	// finds the non-deleted release with the given name
 	rel, err := r.cfg.Releases.Last(name)
 	if err != nil {
 		return rel, err
 	}
 
-	skippedHooks := []*release.Hook{}
-	executingHooks := []*release.Hook{}
-	if len(r.Filters[ExcludeNameFilter]) != 0 {
-		for _, h := range rel.Hooks {
-			if slices.Contains(r.Filters[ExcludeNameFilter], h.Name) {
-				skippedHooks = append(skippedHooks, h)
-			} else {
-				executingHooks = append(executingHooks, h)
-			}
+	tests := []*release.Hook{}
+	for _, h := range rel.Hooks {
+		if !slices.Contains(h.Events, release.HookTest) {
+			continue
+		}
+		ok, err := r.selected(h, selector)
+		if err != nil {
+			return rel, err
+		}
+		if ok {
+			tests = append(tests, h)
 		}
-		rel.Hooks = executingHooks
 	}
-	if len(r.Filters[IncludeNameFilter]) != 0 {
-		executingHooks = nil
-		for _, h := range rel.Hooks {
-			if slices.Contains(r.Filters[IncludeNameFilter], h.Name) {
-				executingHooks = append(executingHooks, h)
-			} else {
-				skippedHooks = append(skippedHooks, h)
+
+	r.attempts = map[string]int{}
+	serverSideApply := rel.ApplyMethod == string(release.ApplyMethodServerSideApply)
+	if r.Parallel > 1 || r.Retries > 0 {
+		err = r.execTests(rel, tests, serverSideApply)
+	} else {
+		err = r.cfg.execHooks(rel, release.HookTest, tests, kube.StatusWatcherStrategy, r.Timeout, serverSideApply)
+		for _, h := range tests {
+			if !h.LastRun.StartedAt.IsZero() {
+				r.attempts[h.Name] = 1
 			}
 		}
-		rel.Hooks = executingHooks
 	}
 
-	serverSideApply := rel.ApplyMethod == string(release.ApplyMethodServerSideApply)
-	if err := r.cfg.execHook(rel, release.HookTest, kube.StatusWatcherStrategy, r.Timeout, serverSideApply); err != nil {
-		rel.Hooks = append(skippedHooks, rel.Hooks...)
+	if err != nil {
 		r.cfg.Releases.Update(rel)
 		return rel, err
 	}
-
-	rel.Hooks = append(skippedHooks, rel.Hooks...)
 	return rel, r.cfg.Releases.Update(rel)
 }
 
+// selected reports whether the given test hook matches the name filters and
+// the label selector.
+func (r *ReleaseTesting) selected(h *release.Hook, selector labels.Selector) (bool, error) {
+	if slices.Contains(r.Filters[ExcludeNameFilter], h.Name) {
+		return false, nil
+	}
+	if len(r.Filters[IncludeNameFilter]) > 0 && !slices.Contains(r.Filters[IncludeNameFilter], h.Name) {
+		return false, nil
+	}
+	if selector.Empty() {
+		return true, nil
+	}
+	tmp := struct {
+		Metadata struct {
+			Labels map[string]string
+		}
+	}{}
+	if err := yaml.Unmarshal([]byte(h.Manifest), &tmp); err != nil {
+		return false, fmt.Errorf("unable to parse metadata.labels from kubernetes manifest for test %s: %w", h.Path, err)
+	}
+	return selector.Matches(labels.Set(tmp.Metadata.Labels)), nil
+}
+
+// execTests runs the given test hooks of the release, running up to r.Parallel tests
+// of the same weight at once and retrying failed tests up to r.Retries times.
+//
+// Tests are grouped by weight, and groups are run in order of their weight. If
+// a test in a group fails, the remaining tests of that group are still run but
+// later groups are skipped.
+func (r *ReleaseTesting) execTests(rel *release.Release, tests []*release.Hook, serverSideApply bool) error {
+	sort.Stable(hookByWeight(tests))
+
+	parallel := max(r.Parallel, 1)
+	for start := 0; start < len(tests); {
+		end := start + 1
+		for end < len(tests) && tests[end].Weight == tests[start].Weight {
+			end++
+		}
+
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, parallel)
+		errs := make([]error, end-start)
+		for i, h := range tests[start:end] {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				errs[i] = r.execTest(rel, h, serverSideApply)
+			}()
+		}
+		wg.Wait()
+
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+		start = end
+	}
+	return nil
+}
+
+// execTest runs a single test hook, retrying it on failure. The outcome is
+// recorded on the hook; the release itself is stored once all the tests are
+// done, as the tests may be run at the same time.
+func (r *ReleaseTesting) execTest(rel *release.Release, h *release.Hook, serverSideApply bool) error {
+	var err error
+	for attempt := 1; attempt <= r.Retries+1; attempt++ {
+		if attempt > 1 {
+			// The hook of the failed attempt may still exist, whatever its
+			// delete policy is, so it is deleted before the test is re-run.
+			if err := r.cfg.deleteHook(h, kube.StatusWatcherStrategy, r.Timeout); err != nil {
+				return fmt.Errorf("unable to delete test %s before retrying it: %w", h.Path, err)
+			}
+		}
+
+		r.mu.Lock()
+		r.attempts[h.Name] = attempt
+		r.mu.Unlock()
+
+		var resources kube.ResourceList
+		var waiter kube.Waiter
+		resources, waiter, err = r.cfg.startHook(h, release.HookTest, kube.StatusWatcherStrategy, r.Timeout, serverSideApply, func() {})
+		if err != nil {
+			continue
+		}
+		if err = r.cfg.watchHook(h, waiter, resources, r.Timeout); err == nil {
+			break
+		}
+	}
+
+	if err != nil {
+		r.cfg.cleanUpFailedHook(h, rel.Namespace, kube.StatusWatcherStrategy, r.Timeout)
+		return err
+	}
+	return r.cfg.cleanUpSucceededHook(h, rel.Namespace, kube.StatusWatcherStrategy, r.Timeout)
+}
+
+// Results returns the result of every test of the release that was run.
+//
+// When withLogs is set, the logs of each test pod are captured as well. A test
+// pod which was deleted by its delete policy, or cannot be read, has its error
+// recorded in the result instead.
+func (r *ReleaseTesting) Results(rel *release.Release, withLogs bool) ([]TestResult, error) {
+	var client kubernetes.Interface
+	if withLogs {
+		var err error
+		if client, err = r.cfg.KubernetesClientSet(); err != nil {
+			return nil, fmt.Errorf("unable to get kubernetes client to fetch pod logs: %w", err)
+		}
+	}
+
+	hooks := append([]*release.Hook{}, rel.Hooks...)
+	sort.Stable(hookByWeight(hooks))
+
+	results := []TestResult{}
+	for _, h := range hooks {
+		if !slices.Contains(h.Events, release.HookTest) || h.LastRun.StartedAt.IsZero() {
+			continue
+		}
+		res := TestResult{
+			Name:        h.Name,
+			Path:        h.Path,
+			Phase:       h.LastRun.Phase,
+			StartedAt:   h.LastRun.StartedAt,
+			CompletedAt: h.LastRun.CompletedAt,
+			Attempts:    r.attempts[h.Name],
+		}
+		if !h.LastRun.CompletedAt.IsZero() {
+			res.Duration = h.LastRun.CompletedAt.Sub(h.LastRun.StartedAt)
+		}
+		if res.Attempts == 0 {
+			res.Attempts = 1
+		}
+		if client != nil {
+			var buf bytes.Buffer
+			if err := r.podLogs(client, &buf, h.Name); err != nil {
+				res.LogsError = err.Error()
+			}
+			res.Logs = buf.String()
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
 // GetPodLogs will write the logs for all test pods in the given release into
 // the given writer. These can be immediately output to the user or captured for
 // other uses
@@ -116,31 +301,43 @@ func (r *ReleaseTesting) GetPodLogs(out io.Writer, rel *release.Release) error {
 		return fmt.Errorf("unable to get kubernetes client to fetch pod logs: %w", err)
 	}
 
+	selector, err := labels.Parse(r.Selector)
+	if err != nil {
+		return fmt.Errorf("invalid test selector %q: %w", r.Selector, err)
+	}
+
 	hooksByWight := append([]*release.Hook{}, rel.Hooks...)
 	sort.Stable(hookByWeight(hooksByWight))
 	for _, h := range hooksByWight {
-		for _, e := range h.Events {
-			if e == release.HookTest {
-				if slices.Contains(r.Filters[ExcludeNameFilter], h.Name) {
-					continue
-				}
-				if len(r.Filters[IncludeNameFilter]) > 0 && !slices.Contains(r.Filters[IncludeNameFilter], h.Name) {
-					continue
-				}
-				req := client.CoreV1().Pods(r.Namespace).GetLogs(h.Name, &v1.PodLogOptions{})
-				logReader, err := req.Stream(context.Background())
-				if err != nil {
-					return fmt.Errorf("unable to get pod logs for %s: %w", h.Name, err)
-				}
-
-				fmt.Fprintf(out, "POD LOGS: %s\n", h.Name)
-				_, err = io.Copy(out, logReader)
-				fmt.Fprintln(out)
-				if err != nil {
-					return fmt.Errorf("unable to write pod logs for %s: %w", h.Name, err)
-				}
-			}
+		if !slices.Contains(h.Events, release.HookTest) {
+			continue
+		}
+		if ok, err := r.selected(h, selector); err != nil {
+			return err
+		} else if !ok {
+			continue
 		}
+		fmt.Fprintf(out, "POD LOGS: %s\n", h.Name)
+		err := r.podLogs(client, out, h.Name)
+		fmt.Fprintln(out)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// podLogs copies the logs of the named test pod to out.
+func (r *ReleaseTesting) podLogs(client kubernetes.Interface, out io.Writer, name string) error {
+	req := client.CoreV1().Pods(r.Namespace).GetLogs(name, &v1.PodLogOptions{})
+	logReader, err := req.Stream(context.Background())
+	if err != nil {
+		return fmt.Errorf("unable to get pod logs for %s: %w", name, err)
+	}
+	defer logReader.Close()
+
+	if _, err := io.Copy(out, logReader); err != nil {
+		return fmt.Errorf("unable to write pod logs for %s: %w", name, err)
 	}
 	return nil
 }
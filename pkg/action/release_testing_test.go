@@ -0,0 +1,195 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func testHook(name, suite string, weight int) *release.Hook {
+	return &release.Hook{
+		Name: name,
+		Kind: "Pod",
+		Path: fmt.Sprintf("templates/tests/%s.yaml", name),
+		Manifest: fmt.Sprintf(`apiVersion: v1
+kind: Pod
+metadata:
+  name: %s
+  labels:
+    suite: %s
+  annotations:
+    "helm.sh/hook": test
+spec:
+  containers:
+  - name: test
+    image: fake-image
+`, name, suite),
+		Weight: weight,
+		Events: []release.HookEvent{release.HookTest},
+	}
+}
+
+func releaseTestingAction(t *testing.T) *ReleaseTesting {
+	t.Helper()
+	config := actionConfigFixture(t)
+	rel := releaseStub()
+	// Replace the stub's test hook, its manifest has no parsable labels
+	rel.Hooks = append(rel.Hooks[:1],
+		testHook("smoke-1", "smoke", 0),
+		testHook("smoke-2", "smoke", 0),
+		testHook("e2e-1", "e2e", 5),
+	)
+	require.NoError(t, config.Releases.Create(rel))
+	return NewReleaseTesting(config)
+}
+
+func ranTests(t *testing.T, rel *release.Release) []string {
+	t.Helper()
+	var names []string
+	for _, h := range rel.Hooks {
+		if !h.LastRun.StartedAt.IsZero() {
+			names = append(names, h.Name)
+		}
+	}
+	return names
+}
+
+func TestReleaseTesting(t *testing.T) {
+	client := releaseTestingAction(t)
+
+	rel, err := client.Run("angry-panda")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"smoke-1", "smoke-2", "e2e-1"}, ranTests(t, rel))
+	assert.Len(t, rel.Hooks, 4, "the non-test hooks should be kept in the release")
+
+	results, err := client.Results(rel, false)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	for _, r := range results {
+		assert.Equal(t, release.HookPhaseSucceeded, r.Phase)
+		assert.Equal(t, 1, r.Attempts)
+	}
+	assert.Equal(t, "e2e-1", results[len(results)-1].Name, "results should be ordered by weight")
+}
+
+func TestReleaseTestingSelector(t *testing.T) {
+	client := releaseTestingAction(t)
+	client.Selector = "suite=smoke"
+	client.Filters[ExcludeNameFilter] = []string{"smoke-2"}
+
+	rel, err := client.Run("angry-panda")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"smoke-1"}, ranTests(t, rel))
+
+	stored, err := client.cfg.Releases.Get("angry-panda", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"smoke-1"}, ranTests(t, stored))
+}
+
+func TestReleaseTestingInvalidSelector(t *testing.T) {
+	client := releaseTestingAction(t)
+	client.Selector = "suite in ("
+
+	_, err := client.Run("angry-panda")
+	assert.ErrorContains(t, err, "invalid test selector")
+}
+
+func TestReleaseTestingParallel(t *testing.T) {
+	client := releaseTestingAction(t)
+	client.Parallel = 3
+
+	rel, err := client.Run("angry-panda")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"smoke-1", "smoke-2", "e2e-1"}, ranTests(t, rel))
+
+	stored, err := client.cfg.Releases.Get("angry-panda", 1)
+	require.NoError(t, err)
+	assert.Len(t, stored.Hooks, 4)
+	for _, h := range stored.Hooks {
+		if h.Events[0] == release.HookTest {
+			assert.Equal(t, release.HookPhaseSucceeded, h.LastRun.Phase, h.Name)
+		}
+	}
+}
+
+func TestReleaseTestingRetries(t *testing.T) {
+	client := releaseTestingAction(t)
+	client.Retries = 2
+	client.Filters[IncludeNameFilter] = []string{"smoke-1", "e2e-1"}
+	client.cfg.KubeClient = &kubefake.FailingKubeClient{
+		PrintingKubeClient:   kubefake.PrintingKubeClient{Out: io.Discard},
+		WatchUntilReadyError: errors.New("test failed"),
+	}
+
+	rel, err := client.Run("angry-panda")
+	require.ErrorContains(t, err, "test failed")
+	// e2e-1 has a higher weight and is skipped once smoke-1 has failed
+	assert.Equal(t, []string{"smoke-1"}, ranTests(t, rel))
+
+	results, err := client.Results(rel, false)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, release.HookPhaseFailed, results[0].Phase)
+	assert.Equal(t, 3, results[0].Attempts)
+}
+
+// deleteCountingKubeClient counts the resources deleted through it.
+type deleteCountingKubeClient struct {
+	*kubefake.FailingKubeClient
+	deleted []string
+}
+
+func (c *deleteCountingKubeClient) Delete(resources kube.ResourceList) (*kube.Result, []error) {
+	for _, r := range resources {
+		c.deleted = append(c.deleted, r.Name)
+	}
+	return c.FailingKubeClient.Delete(resources)
+}
+
+func TestReleaseTestingRetriesDeleteTest(t *testing.T) {
+	client := releaseTestingAction(t)
+	client.Retries = 2
+	client.Filters[IncludeNameFilter] = []string{"smoke-1"}
+	kubeClient := &deleteCountingKubeClient{FailingKubeClient: &kubefake.FailingKubeClient{
+		PrintingKubeClient:   kubefake.PrintingKubeClient{Out: io.Discard},
+		WatchUntilReadyError: errors.New("test failed"),
+		BuildDummy:           true,
+	}}
+	client.cfg.KubeClient = kubeClient
+
+	rel, err := client.cfg.Releases.Last("angry-panda")
+	require.NoError(t, err)
+	for _, h := range rel.Hooks {
+		// A policy which never deletes the test before it is run.
+		h.DeletePolicies = []release.HookDeletePolicy{release.HookSucceeded}
+	}
+	require.NoError(t, client.cfg.Releases.Update(rel))
+
+	_, err = client.Run("angry-panda")
+	require.ErrorContains(t, err, "test failed")
+	assert.Len(t, kubeClient.deleted, 2, "the test should be deleted before each retry")
+}
@@ -152,10 +152,11 @@ func (r *Rollback) prepareRollback(name string) (*release.Release, *release.Rele
 
 	// Store a new release object with previous release's configuration
 	targetRelease := &release.Release{
-		Name:      name,
-		Namespace: currentRelease.Namespace,
-		Chart:     previousRelease.Chart,
-		Config:    previousRelease.Config,
+		Name:       name,
+		Namespace:  currentRelease.Namespace,
+		Chart:      previousRelease.Chart,
+		Config:     previousRelease.Config,
+		ValuesFrom: previousRelease.ValuesFrom,
 		Info: &release.Info{
 			FirstDeployed: currentRelease.Info.FirstDeployed,
 			LastDeployed:  helmtime.Now(),
@@ -165,11 +166,12 @@ func (r *Rollback) prepareRollback(name string) (*release.Release, *release.Rele
 			// message here, and only override it later if we experience failure.
 			Description: fmt.Sprintf("Rollback to %d", previousVersion),
 		},
-		Version:     currentRelease.Version + 1,
-		Labels:      previousRelease.Labels,
-		Manifest:    previousRelease.Manifest,
-		Hooks:       previousRelease.Hooks,
-		ApplyMethod: string(determineReleaseSSApplyMethod(serverSideApply)),
+		Version:         currentRelease.Version + 1,
+		Labels:          previousRelease.Labels,
+		Manifest:        previousRelease.Manifest,
+		Hooks:           previousRelease.Hooks,
+		ApplyMethod:     string(determineReleaseSSApplyMethod(serverSideApply)),
+		ChartAPIVersion: previousRelease.ChartAPIVersion,
 	}
 
 	return currentRelease, targetRelease, serverSideApply, nil
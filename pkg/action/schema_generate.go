@@ -0,0 +1,72 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"helm.sh/helm/v4/pkg/chart/loader"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+// SchemaGenerate is the action for generating the values schema of a chart.
+//
+// It provides the implementation of 'helm schema generate'.
+type SchemaGenerate struct {
+	// Stdout writes the schema to the output instead of the chart's
+	// values.schema.json.
+	Stdout bool
+}
+
+// NewSchemaGenerate creates a new SchemaGenerate object.
+func NewSchemaGenerate() *SchemaGenerate {
+	return &SchemaGenerate{}
+}
+
+// Run generates the values schema of the chart at chartpath.
+func (s *SchemaGenerate) Run(chartpath string, out io.Writer) error {
+	c, err := loader.Load(chartpath)
+	if err != nil {
+		return err
+	}
+	schema, err := chartutil.GenerateSchema(c)
+	if err != nil {
+		return err
+	}
+
+	if s.Stdout {
+		_, err := out.Write(schema)
+		return err
+	}
+
+	fi, err := os.Stat(chartpath)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%s is not a chart directory, use --stdout to generate the schema of a packaged chart", chartpath)
+	}
+	dest := filepath.Join(chartpath, "values.schema.json")
+	if err := os.WriteFile(dest, schema, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Wrote %s\n", dest)
+	return nil
+}
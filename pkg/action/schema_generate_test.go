@@ -0,0 +1,60 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaGenerate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte("apiVersion: v2\nname: demo\nversion: 0.1.0\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "values.yaml"), []byte("# @schema minimum: 1\nreplicas: 1\n"), 0644))
+
+	var out bytes.Buffer
+	client := NewSchemaGenerate()
+	require.NoError(t, client.Run(dir, &out))
+
+	dest := filepath.Join(dir, "values.schema.json")
+	assert.Equal(t, "Wrote "+dest+"\n", out.String())
+	written, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Contains(t, string(written), `"minimum": 1`)
+
+	out.Reset()
+	client.Stdout = true
+	require.NoError(t, client.Run(dir, &out))
+	assert.Equal(t, string(written), out.String())
+}
+
+func TestSchemaGeneratePackagedChart(t *testing.T) {
+	var out bytes.Buffer
+	client := NewSchemaGenerate()
+	err := client.Run("testdata/charts/compressedchart-0.1.0.tgz", &out)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not a chart directory")
+
+	client.Stdout = true
+	require.NoError(t, client.Run("testdata/charts/compressedchart-0.1.0.tgz", &out))
+	assert.Contains(t, out.String(), `"$schema": "https://json-schema.org/draft/2020-12/schema"`)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/engine"
+	releaseutil "helm.sh/helm/v4/pkg/release/util"
+)
+
+// manifestSources maps the lines of a release manifest, starting at 1, to the
+// template lines that produced them. Lines of unknown origin, e.g. the source
+// comments, map to the zero Location.
+type manifestSources []engine.Location
+
+func (s *manifestSources) addUnknown(n int) {
+	*s = append(*s, make([]engine.Location, n)...)
+}
+
+// addManifest adds the lines written for a manifest rendered from a template.
+func (s *manifestSources) addManifest(m releaseutil.Manifest, file string, sourceMap engine.SourceMap) {
+	s.addUnknown(2)
+	start := 0
+	if i := strings.Index(file, m.Content); i >= 0 {
+		start = strings.Count(file[:i], "\n") + 1
+	}
+	for j := range strings.Count(m.Content, "\n") + 1 {
+		var loc engine.Location
+		if start > 0 {
+			loc, _ = sourceMap.Lookup(m.Name, start+j)
+		}
+		*s = append(*s, loc)
+	}
+}
+
+// addCRD adds the lines written for a CRD, which are not templates.
+func (s *manifestSources) addCRD(crd chart.CRD) {
+	s.addUnknown(2)
+	for j := range strings.Count(string(crd.File.Data), "\n") + 1 {
+		*s = append(*s, engine.Location{File: crd.Filename, Line: j + 1})
+	}
+}
+
+func (s manifestSources) locate(line int) (engine.Location, bool) {
+	if line < 1 || line > len(s) || s[line-1].File == "" {
+		return engine.Location{}, false
+	}
+	return s[line-1], true
+}
+
+// fieldPatterns find the path of the field an error is about in the errors of
+// the Kubernetes validation.
+var fieldPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`unknown field "([^"]+)"`),
+	regexp.MustCompile(`duplicate field "([^"]+)"`),
+	regexp.MustCompile(`ValidationError\(\w+\.([^)]+)\)`),
+	regexp.MustCompile(`is invalid: ([\w.\[\]/-]+):`),
+}
+
+// resourcePattern finds the kind and name of the resource an API server error
+// is about, e.g. 'Deployment.apps "web" is invalid'.
+var resourcePattern = regexp.MustCompile(`(\w+)(?:\.[\w.]+)? "([^"]+)" is invalid`)
+
+// annotate prefixes err with the template line the document, or the field of
+// the document the error is about, was rendered from.
+func (s manifestSources) annotate(doc releaseutil.Document, err error) error {
+	line := 1
+	for _, p := range fieldPatterns {
+		if m := p.FindStringSubmatch(err.Error()); m != nil {
+			if l := releaseutil.FieldLine(doc.Content, m[1]); l > 0 {
+				line = l
+			}
+			break
+		}
+	}
+	if line == 1 {
+		// Point at the first line of the object rather than a comment.
+		if l := releaseutil.FieldLine(doc.Content, ""); l > 0 {
+			line = l
+		}
+	}
+	loc, ok := s.locate(doc.Line + line - 1)
+	if !ok {
+		return err
+	}
+	return fmt.Errorf("%s: %w", loc, err)
+}
+
+// locateBuildError finds the documents of a manifest the Kubernetes client
+// fails to build, and points the error at the templates they come from. The
+// error is returned as is when none can be located.
+func (cfg *Configuration) locateBuildError(manifest string, sources manifestSources, validate bool, err error) error {
+	if len(sources) == 0 {
+		return err
+	}
+	var errs []error
+	for _, doc := range releaseutil.SplitDocuments(manifest) {
+		if _, derr := cfg.KubeClient.Build(bytes.NewBufferString(doc.Content), validate); derr != nil {
+			errs = append(errs, sources.annotate(doc, derr))
+		}
+	}
+	if len(errs) == 0 {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// locateResourceError points an error the API server returned for a resource
+// of a manifest at the template the resource comes from. The error is returned
+// as is when the resource cannot be located.
+func (s manifestSources) locateResourceError(manifest string, err error) error {
+	if len(s) == 0 || err == nil {
+		return err
+	}
+	m := resourcePattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	for _, doc := range releaseutil.SplitDocuments(manifest) {
+		var head releaseutil.SimpleHead
+		if yaml.Unmarshal([]byte(doc.Content), &head) != nil || head.Metadata == nil {
+			continue
+		}
+		if head.Kind == m[1] && head.Metadata.Name == m[2] {
+			return s.annotate(doc, err)
+		}
+	}
+	return err
+}
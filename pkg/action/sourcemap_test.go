@@ -0,0 +1,131 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+)
+
+const sourceMapDeployment = `# a comment
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  {{- if .Values.replicas }}
+  replicaz: {{ .Values.replicas }}
+  {{- end }}
+`
+
+const sourceMapService = `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`
+
+// unknownFieldKubeClient fails to build the documents with a misspelled field.
+type unknownFieldKubeClient struct {
+	kubefake.FailingKubeClient
+	builds int
+}
+
+func (c *unknownFieldKubeClient) Build(r io.Reader, validate bool) (kube.ResourceList, error) {
+	c.builds++
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(string(data), "replicaz") {
+		return nil, errors.New(`error validating data: unknown field "spec.replicaz"`)
+	}
+	return c.FailingKubeClient.Build(strings.NewReader(string(data)), validate)
+}
+
+func sourceMapChart() *chart.Chart {
+	return buildChartWithTemplates([]*chart.File{
+		{Name: "templates/deployment.yaml", Data: []byte(sourceMapDeployment)},
+		{Name: "templates/service.yaml", Data: []byte(sourceMapService)},
+	})
+}
+
+func TestInstallLocatesBuildError(t *testing.T) {
+	config := actionConfigFixture(t)
+	config.KubeClient = &unknownFieldKubeClient{FailingKubeClient: kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}}}
+	config.RenderSourceMap = true
+	instAction := installActionWithConfig(config)
+
+	_, err := instAction.Run(sourceMapChart(), map[string]interface{}{"replicas": 3})
+	require.Error(t, err)
+	assert.Equal(t, `unable to build kubernetes objects from release manifest: hello/templates/deployment.yaml:8: error validating data: unknown field "spec.replicaz"`, err.Error())
+}
+
+func TestInstallBuildErrorWithoutSourceMap(t *testing.T) {
+	config := actionConfigFixture(t)
+	kubeClient := &unknownFieldKubeClient{FailingKubeClient: kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}}}
+	config.KubeClient = kubeClient
+	instAction := installActionWithConfig(config)
+
+	_, err := instAction.Run(sourceMapChart(), map[string]interface{}{"replicas": 3})
+	require.Error(t, err)
+	assert.Equal(t, `unable to build kubernetes objects from release manifest: error validating data: unknown field "spec.replicaz"`, err.Error())
+	assert.Equal(t, 1, kubeClient.builds, "the documents should not be built one by one")
+}
+
+func TestUpgradeLocatesBuildError(t *testing.T) {
+	config := actionConfigFixture(t)
+	config.KubeClient = &unknownFieldKubeClient{FailingKubeClient: kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}}}
+	config.RenderSourceMap = true
+	upAction := NewUpgrade(config)
+	rel := releaseStub()
+	require.NoError(t, config.Releases.Create(rel))
+
+	_, err := upAction.Run(rel.Name, sourceMapChart(), map[string]interface{}{"replicas": 3})
+	require.Error(t, err)
+	assert.Equal(t, `hello/templates/deployment.yaml:8: error validating data: unknown field "spec.replicaz"`, err.Error())
+}
+
+func TestInstallLocatesResourceError(t *testing.T) {
+	config := actionConfigFixtureWithDummyResources(t, createDummyResourceList(true))
+	// The existing resource is owned by the release, so it is updated.
+	config.KubeClient.(*kubefake.FailingKubeClient).UpdateError = errors.New(`Service "web" is invalid: metadata.name: Invalid value`)
+	config.RenderSourceMap = true
+	instAction := installActionWithConfig(config)
+
+	_, err := instAction.RunWithContext(context.Background(), sourceMapChart(), map[string]interface{}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `hello/templates/service.yaml:4: Service "web" is invalid`)
+}
+
+func TestManifestSourcesWithoutSourceMap(t *testing.T) {
+	var sources manifestSources
+	err := errors.New(`Service "web" is invalid: metadata.name: Invalid value`)
+	assert.Same(t, err, sources.locateResourceError("kind: Service\n", err))
+
+	config := actionConfigFixture(t)
+	assert.Same(t, err, config.locateBuildError("kind: Service\n", sources, true, err))
+}
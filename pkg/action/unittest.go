@@ -0,0 +1,70 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"os"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/unittest"
+)
+
+// UnitTest is the action for running the unit test suites stored in the
+// tests/ directory of charts.
+//
+// It provides the implementation of 'helm unittest'.
+type UnitTest struct {
+	// KubeVersion is the Kubernetes version the templates are rendered for,
+	// unless a suite sets one.
+	KubeVersion *chartutil.KubeVersion
+}
+
+// UnitTestResult is the result of running the test suites of a chart.
+type UnitTestResult struct {
+	// Chart is the path of the chart directory.
+	Chart  string
+	Suites []*unittest.SuiteResult
+}
+
+// NewUnitTest creates a new UnitTest object.
+func NewUnitTest() *UnitTest {
+	return &UnitTest{}
+}
+
+// Run runs the test suites of the given chart directories.
+func (u *UnitTest) Run(paths []string) ([]*UnitTestResult, error) {
+	var results []*UnitTestResult
+	for _, path := range paths {
+		if fi, err := os.Stat(path); err != nil {
+			return results, err
+		} else if !fi.IsDir() {
+			return results, fmt.Errorf("%s is not a chart directory", path)
+		}
+		files, err := unittest.FindSuites(path)
+		if err != nil {
+			return results, fmt.Errorf("cannot find the test suites of %s: %w", path, err)
+		}
+		runner := &unittest.Runner{ChartDir: path, KubeVersion: u.KubeVersion}
+		result := &UnitTestResult{Chart: path}
+		for _, f := range files {
+			result.Suites = append(result.Suites, runner.RunFile(f))
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	clivalues "helm.sh/helm/v4/pkg/cli/values"
+)
+
+// ClusterValuesReader reads the values referenced by --values-from from the
+// Secrets and ConfigMaps of a namespace.
+type ClusterValuesReader struct {
+	cfg       *Configuration
+	namespace string
+}
+
+// NewClusterValuesReader creates a reader for the Secrets and ConfigMaps of
+// the given namespace, using the client of the configuration.
+func NewClusterValuesReader(cfg *Configuration, namespace string) *ClusterValuesReader {
+	return &ClusterValuesReader{
+		cfg:       cfg,
+		namespace: namespace,
+	}
+}
+
+// Read returns the data stored under the key of the referenced object.
+func (r *ClusterValuesReader) Read(ref clivalues.Reference) ([]byte, error) {
+	client, err := r.cfg.KubernetesClientSet()
+	if err != nil {
+		return nil, err
+	}
+	return readValuesFrom(client, r.namespace, ref)
+}
+
+func readValuesFrom(client kubernetes.Interface, namespace string, ref clivalues.Reference) ([]byte, error) {
+	switch ref.Kind {
+	case clivalues.ReferenceSecret:
+		secret, err := client.CoreV1().Secrets(namespace).Get(context.Background(), ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if data, ok := secret.Data[ref.Key]; ok {
+			return data, nil
+		}
+		if data, ok := secret.StringData[ref.Key]; ok {
+			return []byte(data), nil
+		}
+	case clivalues.ReferenceConfigMap:
+		cm, err := client.CoreV1().ConfigMaps(namespace).Get(context.Background(), ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if data, ok := cm.Data[ref.Key]; ok {
+			return []byte(data), nil
+		}
+		if data, ok := cm.BinaryData[ref.Key]; ok {
+			return data, nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown kind %q", ref.Kind)
+	}
+	return nil, fmt.Errorf("%s %q in namespace %q has no key %q", ref.Kind, ref.Name, namespace, ref.Key)
+}
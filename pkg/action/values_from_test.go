@@ -0,0 +1,137 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	clivalues "helm.sh/helm/v4/pkg/cli/values"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestReadValuesFrom(t *testing.T) {
+	client := fake.NewClientset(
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"},
+			Data:       map[string][]byte{"values.yaml": []byte("password: secret\n")},
+		},
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "overrides", Namespace: "prod"},
+			Data:       map[string]string{"prod.yaml": "replicas: 3\n"},
+		},
+	)
+
+	tests := []struct {
+		name string
+		ref  string
+		want string
+		err  string
+	}{{
+		name: "secret with the default key",
+		ref:  "secret/db",
+		want: "password: secret\n",
+	}, {
+		name: "configmap with a key",
+		ref:  "configmap/overrides:prod.yaml",
+		want: "replicas: 3\n",
+	}, {
+		name: "missing key",
+		ref:  "configmap/overrides:dev.yaml",
+		err:  `configmap "overrides" in namespace "prod" has no key "dev.yaml"`,
+	}, {
+		name: "missing object",
+		ref:  "secret/missing",
+		err:  `secrets "missing" not found`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := clivalues.ParseReference(tt.ref)
+			require.NoError(t, err)
+			data, err := readValuesFrom(client, "prod", ref)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+		})
+	}
+}
+
+func TestInstallRecordsValuesFrom(t *testing.T) {
+	instAction := installAction(t)
+	instAction.ValuesFrom = []string{"secret/db"}
+
+	res, err := instAction.Run(buildChart(), map[string]interface{}{"password": "secret"})
+	require.NoError(t, err)
+
+	rel, err := instAction.cfg.Releases.Get(res.Name, res.Version)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"secret/db"}, rel.ValuesFrom)
+}
+
+func TestUpgradeValuesFrom(t *testing.T) {
+	tests := []struct {
+		name        string
+		reuseValues bool
+		resetValues bool
+		valuesFrom  []string
+		want        []string
+	}{{
+		name:       "new references replace the current ones",
+		valuesFrom: []string{"configmap/overrides"},
+		want:       []string{"configmap/overrides"},
+	}, {
+		name:        "reused values keep their references",
+		reuseValues: true,
+		valuesFrom:  []string{"configmap/overrides", "secret/db"},
+		want:        []string{"secret/db", "configmap/overrides"},
+	}, {
+		name:        "reset values drop the current references",
+		resetValues: true,
+		want:        nil,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upAction := upgradeAction(t)
+
+			rel := releaseStub()
+			rel.Name = "nuketown"
+			rel.Info.Status = release.StatusDeployed
+			rel.Config = map[string]interface{}{"password": "secret"}
+			rel.ValuesFrom = []string{"secret/db"}
+			require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+			upAction.ReuseValues = tt.reuseValues
+			upAction.ResetValues = tt.resetValues
+			upAction.ValuesFrom = tt.valuesFrom
+			res, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{"replicas": 3})
+			require.NoError(t, err)
+
+			updated, err := upAction.cfg.Releases.Get(res.Name, 2)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, updated.ValuesFrom)
+		})
+	}
+}
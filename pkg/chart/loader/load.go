@@ -0,0 +1,219 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package loader loads charts of any supported apiVersion.
+
+Charts with apiVersion v1 and v2 are loaded by the v2 loader. Charts with
+apiVersion v3 are loaded by the v3 loader when the HELM_EXPERIMENTAL_CHART_V3
+feature gate is enabled, and converted to the v2 representation used by the
+actions, the rendering engine and the linter. The apiVersion of the chart is
+kept, so it can be told apart once loaded.
+*/
+package loader
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	v3 "helm.sh/helm/v4/internal/chart/v3"
+	v3loader "helm.sh/helm/v4/internal/chart/v3/loader"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	"helm.sh/helm/v4/pkg/gates"
+)
+
+// Load takes a string name, tries to resolve it to a file or directory, and
+// then loads it with the loader of the apiVersion of the chart.
+func Load(name string) (*chart.Chart, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return LoadDir(name)
+	}
+	return LoadFile(name)
+}
+
+// LoadDir loads a chart from a directory.
+func LoadDir(dir string) (*chart.Chart, error) {
+	files, err := loader.LoadDirFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return LoadFiles(files)
+}
+
+// LoadFile loads a chart from an archive file.
+func LoadFile(name string) (*chart.Chart, error) {
+	files, err := loader.LoadFileFiles(name)
+	if err != nil {
+		return nil, err
+	}
+	return LoadFiles(files)
+}
+
+// LoadArchive loads a chart from a reader containing a compressed tar archive.
+func LoadArchive(in io.Reader) (*chart.Chart, error) {
+	files, err := loader.LoadArchiveFiles(in)
+	if err != nil {
+		return nil, err
+	}
+	return LoadFiles(files)
+}
+
+// LoadFiles loads a chart from in-memory files with the loader of the
+// apiVersion given in its Chart.yaml.
+func LoadFiles(files []*loader.BufferedFile) (*chart.Chart, error) {
+	metadata := struct {
+		APIVersion string `json:"apiVersion"`
+		Name       string `json:"name"`
+	}{}
+	for _, f := range files {
+		if f.Name == "Chart.yaml" {
+			// A Chart.yaml that cannot be parsed is reported by the v2 loader.
+			_ = yaml.Unmarshal(f.Data, &metadata)
+			break
+		}
+	}
+	if metadata.APIVersion != v3.APIVersionV3 {
+		return loader.LoadFiles(files)
+	}
+
+	if !gates.ChartV3.IsEnabled() {
+		return nil, fmt.Errorf("chart %q has apiVersion %s: %w", metadata.Name, v3.APIVersionV3, gates.ChartV3.Error())
+	}
+	v3files := make([]*v3loader.BufferedFile, 0, len(files))
+	for _, f := range files {
+		v3files = append(v3files, &v3loader.BufferedFile{Name: f.Name, Data: f.Data})
+	}
+	c, err := v3loader.LoadFiles(v3files)
+	if err != nil {
+		return nil, err
+	}
+	return convert(c), nil
+}
+
+// IsV3 reports whether a chart has apiVersion v3.
+func IsV3(c *chart.Chart) bool {
+	return c != nil && c.Metadata != nil && c.Metadata.APIVersion == v3.APIVersionV3
+}
+
+// convert converts a v3 chart and its dependencies to the v2 representation.
+func convert(in *v3.Chart) *chart.Chart {
+	out := &chart.Chart{
+		Raw:       convertFiles(in.Raw),
+		Templates: convertFiles(in.Templates),
+		Files:     convertFiles(in.Files),
+		Values:    in.Values,
+		Schema:    in.Schema,
+		Metadata:  convertMetadata(in.Metadata),
+		Lock:      convertLock(in.Lock),
+	}
+	for _, dep := range in.Dependencies() {
+		out.AddDependency(convert(dep))
+	}
+	return out
+}
+
+func convertMetadata(in *v3.Metadata) *chart.Metadata {
+	if in == nil {
+		return nil
+	}
+	out := &chart.Metadata{
+		Name:         in.Name,
+		Home:         in.Home,
+		Sources:      in.Sources,
+		Version:      in.Version,
+		Description:  in.Description,
+		Keywords:     in.Keywords,
+		Icon:         in.Icon,
+		APIVersion:   in.APIVersion,
+		Condition:    in.Condition,
+		Tags:         in.Tags,
+		AppVersion:   in.AppVersion,
+		Deprecated:   in.Deprecated,
+		Annotations:  in.Annotations,
+		KubeVersion:  in.KubeVersion,
+		Dependencies: convertDependencies(in.Dependencies),
+		Type:         in.Type,
+	}
+	for _, m := range in.Maintainers {
+		if m == nil {
+			out.Maintainers = append(out.Maintainers, nil)
+			continue
+		}
+		out.Maintainers = append(out.Maintainers, &chart.Maintainer{Name: m.Name, Email: m.Email, URL: m.URL})
+	}
+	for _, p := range in.Plugins {
+		if p == nil {
+			out.Plugins = append(out.Plugins, nil)
+			continue
+		}
+		out.Plugins = append(out.Plugins, &chart.Plugin{Name: p.Name, Version: p.Version})
+	}
+	return out
+}
+
+func convertLock(in *v3.Lock) *chart.Lock {
+	if in == nil {
+		return nil
+	}
+	return &chart.Lock{
+		Generated:    in.Generated,
+		Digest:       in.Digest,
+		Dependencies: convertDependencies(in.Dependencies),
+	}
+}
+
+func convertDependencies(in []*v3.Dependency) []*chart.Dependency {
+	if in == nil {
+		return nil
+	}
+	out := make([]*chart.Dependency, 0, len(in))
+	for _, d := range in {
+		if d == nil {
+			out = append(out, nil)
+			continue
+		}
+		out = append(out, &chart.Dependency{
+			Name:         d.Name,
+			Version:      d.Version,
+			Repository:   d.Repository,
+			Condition:    d.Condition,
+			Tags:         d.Tags,
+			Enabled:      d.Enabled,
+			ImportValues: d.ImportValues,
+			Alias:        d.Alias,
+		})
+	}
+	return out
+}
+
+func convertFiles(in []*v3.File) []*chart.File {
+	if in == nil {
+		return nil
+	}
+	out := make([]*chart.File, 0, len(in))
+	for _, f := range in {
+		out = append(out, &chart.File{Name: f.Name, Data: f.Data})
+	}
+	return out
+}
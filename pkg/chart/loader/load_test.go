@@ -0,0 +1,85 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/gates"
+)
+
+func verifyAlbatross(t *testing.T, c *chart.Chart) {
+	t.Helper()
+	assert.True(t, IsV3(c))
+	assert.Equal(t, "albatross", c.Name())
+	assert.Equal(t, "application", c.Metadata.Type)
+	require.Len(t, c.Metadata.Dependencies, 1)
+	assert.Equal(t, "feather", c.Metadata.Dependencies[0].Name)
+	assert.Equal(t, map[string]interface{}{"wingspan": "3m"}, c.Values)
+	require.Len(t, c.Templates, 1)
+	assert.Equal(t, "templates/configmap.yaml", c.Templates[0].Name)
+
+	require.Len(t, c.Dependencies(), 1)
+	dep := c.Dependencies()[0]
+	assert.True(t, IsV3(dep))
+	assert.Equal(t, "feather", dep.Name())
+	assert.Same(t, c, dep.Parent())
+}
+
+func TestLoadV3(t *testing.T) {
+	t.Setenv(string(gates.ChartV3), "")
+	_, err := Load("testdata/albatross")
+	assert.ErrorContains(t, err, `chart "albatross" has apiVersion v3`)
+	assert.ErrorContains(t, err, string(gates.ChartV3))
+
+	t.Setenv(string(gates.ChartV3), "1")
+	c, err := Load("testdata/albatross")
+	require.NoError(t, err)
+	verifyAlbatross(t, c)
+
+	archive, err := chartutil.Save(c, t.TempDir())
+	require.NoError(t, err)
+
+	c, err = Load(archive)
+	require.NoError(t, err)
+	verifyAlbatross(t, c)
+
+	f, err := os.Open(archive)
+	require.NoError(t, err)
+	defer f.Close()
+	c, err = LoadArchive(f)
+	require.NoError(t, err)
+	verifyAlbatross(t, c)
+}
+
+func TestLoadV2(t *testing.T) {
+	t.Setenv(string(gates.ChartV3), "")
+	c, err := Load("../v2/loader/testdata/frobnitz")
+	require.NoError(t, err)
+	assert.Equal(t, chart.APIVersionV1, c.Metadata.APIVersion)
+	assert.False(t, IsV3(c))
+
+	c, err = Load("../v2/loader/testdata/frobnitz-1.2.3.tgz")
+	require.NoError(t, err)
+	assert.Equal(t, "frobnitz", c.Name())
+}
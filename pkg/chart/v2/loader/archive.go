@@ -53,6 +53,15 @@ func (l FileLoader) Load() (*chart.Chart, error) {
 
 // LoadFile loads from an archive file.
 func LoadFile(name string) (*chart.Chart, error) {
+	files, err := LoadFileFiles(name)
+	if err != nil {
+		return nil, err
+	}
+	return LoadFiles(files)
+}
+
+// LoadFileFiles reads in the files of an archive file into memory.
+func LoadFileFiles(name string) ([]*BufferedFile, error) {
 	if fi, err := os.Stat(name); err != nil {
 		return nil, err
 	} else if fi.IsDir() {
@@ -70,13 +79,11 @@ func LoadFile(name string) (*chart.Chart, error) {
 		return nil, err
 	}
 
-	c, err := LoadArchive(raw)
-	if err != nil {
-		if err == gzip.ErrHeader {
-			return nil, fmt.Errorf("file '%s' does not appear to be a valid chart file (details: %s)", name, err)
-		}
+	files, err := LoadArchiveFiles(raw)
+	if err == gzip.ErrHeader {
+		return nil, fmt.Errorf("file '%s' does not appear to be a valid chart file (details: %s)", name, err)
 	}
-	return c, err
+	return files, err
 }
 
 // ensureArchive's job is to return an informative error if the file does not appear to be a gzipped archive.
@@ -177,6 +177,55 @@ func TestValidate(t *testing.T) {
 			&Metadata{APIVersion: "v2", Name: "test", Version: "1.2.3.4"},
 			ValidationError("chart.metadata.version \"1.2.3.4\" is invalid"),
 		},
+		{
+			"plugin with valid version",
+			&Metadata{
+				Name:       "test",
+				APIVersion: "v2",
+				Version:    "1.0",
+				Plugins: []*Plugin{
+					{Name: "netfuncs", Version: ">=1.0.0"},
+				},
+			},
+			nil,
+		},
+		{
+			"plugin without name",
+			&Metadata{
+				Name:       "test",
+				APIVersion: "v2",
+				Version:    "1.0",
+				Plugins: []*Plugin{
+					{Version: "1.0.0"},
+				},
+			},
+			ValidationError("plugins must have a name"),
+		},
+		{
+			"plugin with invalid version",
+			&Metadata{
+				Name:       "test",
+				APIVersion: "v2",
+				Version:    "1.0",
+				Plugins: []*Plugin{
+					{Name: "netfuncs", Version: "one"},
+				},
+			},
+			ValidationError("plugin \"netfuncs\" version \"one\" is invalid"),
+		},
+		{
+			"same plugin twice",
+			&Metadata{
+				Name:       "test",
+				APIVersion: "v2",
+				Version:    "1.0",
+				Plugins: []*Plugin{
+					{Name: "netfuncs"},
+					{Name: "netfuncs", Version: "1.0.0"},
+				},
+			},
+			ValidationError("more than one plugin with name \"netfuncs\""),
+		},
 	}
 
 	for _, tt := range tests {
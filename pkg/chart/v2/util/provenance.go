@@ -0,0 +1,246 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// ValuesSource is a single layer of values taking part in the computation of
+// the final values, such as a values file or one --set flag.
+type ValuesSource struct {
+	// Name describes the source, e.g. "values file" or "--set image.tag=v2".
+	Name string
+	// File is the file the values were read from, if any.
+	File string
+	// Values are the values of the layer, rooted at the top level chart.
+	Values map[string]interface{}
+	// Lines maps the dotted path of a value to its line in File.
+	Lines map[string]int
+}
+
+// ValueOrigin is a source that set a value.
+type ValueOrigin struct {
+	Source string      `json:"source"`
+	File   string      `json:"file,omitempty"`
+	Line   int         `json:"line,omitempty"`
+	Value  interface{} `json:"value"`
+	// Global is the path of the global value this one was copied from, if
+	// the value reached a subchart through the globals.
+	Global string `json:"global,omitempty"`
+}
+
+// ValueExplanation describes where a single leaf value comes from.
+type ValueExplanation struct {
+	// Path is the dotted path of the value, e.g. "image.tag".
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+	// Origins are the sources that set the value, from the lowest to the
+	// highest precedence.
+	Origins []ValueOrigin `json:"origins"`
+}
+
+// ExplainValues explains the computed values of a chart leaf by leaf.
+//
+// The origins of each value are collected by replaying the layers that
+// CoalesceValues merges: the defaults of the subcharts (deepest first), the
+// values imported from them, the defaults of the parent charts, the globals
+// handed down to the subcharts and finally the user supplied sources in the
+// order given. Lists are treated as a single value.
+//
+// Line numbers of chart defaults are only known when the chart was loaded
+// from its files. Charts read back from a release carry neither their raw
+// files nor their subcharts, so their defaults are reported as a single
+// layer.
+func ExplainValues(chrt *chart.Chart, computed map[string]interface{}, sources []ValuesSource) []ValueExplanation {
+	origins := map[string][]ValueOrigin{}
+	add := func(path string, o ValueOrigin) {
+		origins[path] = append(origins[path], o)
+	}
+
+	explainChartDefaults(chrt, nil, add)
+	for _, s := range sources {
+		leaves := map[string]interface{}{}
+		flattenLeaves("", s.Values, leaves)
+		for path, v := range leaves {
+			add(path, ValueOrigin{Source: s.Name, File: s.File, Line: s.Lines[path], Value: v})
+		}
+	}
+
+	final := map[string]interface{}{}
+	flattenLeaves("", computed, final)
+	paths := make([]string, 0, len(final))
+	for path := range final {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	explanations := make([]ValueExplanation, 0, len(paths))
+	for _, path := range paths {
+		o := append(origins[path], globalOrigins(path, origins)...)
+		if m, ok := final[path].(map[string]interface{}); ok && len(m) == 0 && len(o) == 0 {
+			// Coalescing creates empty tables for subcharts and their
+			// globals, they were not set by anything.
+			continue
+		}
+		explanations = append(explanations, ValueExplanation{
+			Path:    path,
+			Value:   final[path],
+			Origins: o,
+		})
+	}
+	return explanations
+}
+
+// explainChartDefaults records the defaults of a chart and its subcharts.
+// prefix is the path of the chart's values within the top level values.
+func explainChartDefaults(c *chart.Chart, prefix []string, add func(string, ValueOrigin)) {
+	var deps []string
+	for _, sub := range c.Dependencies() {
+		deps = append(deps, sub.Name())
+		explainChartDefaults(sub, append(slices.Clone(prefix), sub.Name()), add)
+	}
+
+	source := "chart defaults"
+	if !c.IsRoot() {
+		source = "subchart defaults"
+	}
+	file := c.ChartFullPath() + "/" + ValuesfileName
+
+	var raw []byte
+	for _, f := range c.Raw {
+		if f.Name == ValuesfileName {
+			raw = f.Data
+		}
+	}
+	if raw == nil {
+		// Without the raw values file the processed values are all there is.
+		leaves := map[string]interface{}{}
+		flattenLeaves(joinPath(prefix...), c.Values, leaves)
+		for path, v := range leaves {
+			add(path, ValueOrigin{Source: source, File: file, Value: v})
+		}
+		return
+	}
+
+	defaults, err := ReadValues(raw)
+	if err != nil {
+		defaults = Values{}
+	}
+	lines, err := ValuesLines(raw)
+	if err != nil {
+		lines = map[string]int{}
+	}
+	leaves := map[string]interface{}{}
+	flattenLeaves("", defaults, leaves)
+	for path, v := range leaves {
+		add(joinPath(append(slices.Clone(prefix), path)...), ValueOrigin{Source: source, File: file, Line: lines[path], Value: v})
+	}
+
+	// Processing the dependencies merges the imported values, as well as a
+	// copy of the subchart values, into the chart's values. Whatever differs
+	// from the values file outside of the subchart keys was imported.
+	processed := map[string]interface{}{}
+	flattenLeaves("", c.Values, processed)
+	for path, v := range processed {
+		if slices.Contains(deps, parsePath(path)[0]) {
+			continue
+		}
+		if d, ok := leaves[path]; ok && reflect.DeepEqual(d, v) {
+			continue
+		}
+		add(joinPath(append(slices.Clone(prefix), path)...), ValueOrigin{Source: "import-values", File: c.ChartFullPath() + "/Chart.yaml", Value: v})
+	}
+}
+
+// globalOrigins returns the origins of the globals of the parent charts that
+// were copied to the given path, from the nearest parent to the top level
+// chart, which has the highest precedence.
+func globalOrigins(path string, origins map[string][]ValueOrigin) []ValueOrigin {
+	var out []ValueOrigin
+	segments := parsePath(path)
+	i := slices.Index(segments, GlobalKey)
+	if i <= 0 {
+		return nil
+	}
+	for j := i - 1; j >= 0; j-- {
+		global := joinPath(append(slices.Clone(segments[:j]), segments[i:]...)...)
+		for _, o := range origins[global] {
+			o.Global = global
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// flattenLeaves collects the leaves of a values tree keyed by their dotted
+// path. Lists and empty maps are leaves.
+func flattenLeaves(prefix string, v map[string]interface{}, out map[string]interface{}) {
+	for k, val := range v {
+		path := concatPrefix(prefix, k)
+		if m, ok := val.(map[string]interface{}); ok && len(m) > 0 {
+			flattenLeaves(path, m, out)
+			continue
+		}
+		out[path] = val
+	}
+}
+
+// ValuesLines maps the dotted path of every key of a YAML values document to
+// the line it is defined on.
+func ValuesLines(data []byte) (map[string]int, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return map[string]int{}, nil
+		}
+		return nil, err
+	}
+	lines := map[string]int{}
+	if len(doc.Content) > 0 {
+		collectLines("", doc.Content[0], lines)
+	}
+	return lines, nil
+}
+
+func collectLines(prefix string, n *yaml.Node, lines map[string]int) {
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	if n.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, val := n.Content[i], n.Content[i+1]
+		if key.Tag == "!!merge" {
+			continue
+		}
+		path := concatPrefix(prefix, strings.TrimSpace(key.Value))
+		lines[path] = key.Line
+		collectLines(path, val, lines)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func chartWithRawValues(t *testing.T, name, raw string) *chart.Chart {
+	t.Helper()
+	vals, err := ReadValues([]byte(raw))
+	require.NoError(t, err)
+	return &chart.Chart{
+		Metadata: &chart.Metadata{Name: name, Version: "0.1.0"},
+		Raw:      []*chart.File{{Name: ValuesfileName, Data: []byte(raw)}},
+		Values:   vals,
+	}
+}
+
+func TestExplainValues(t *testing.T) {
+	parent := chartWithRawValues(t, "parent", `image:
+  repository: nginx
+  tag: "1.0"
+global:
+  env: dev
+sub:
+  replicas: 2
+`)
+	sub := chartWithRawValues(t, "sub", `replicas: 1
+port: 80
+`)
+	parent.AddDependency(sub)
+
+	userVals := map[string]interface{}{
+		"image":  map[string]interface{}{"tag": "2.0"},
+		"global": map[string]interface{}{"env": "prod"},
+	}
+	computed, err := CoalesceValues(parent, userVals)
+	require.NoError(t, err)
+
+	sources := []ValuesSource{{
+		Name:   "values file",
+		File:   "prod.yaml",
+		Values: map[string]interface{}{"image": map[string]interface{}{"tag": "2.0"}},
+		Lines:  map[string]int{"image": 1, "image.tag": 2},
+	}, {
+		Name:   "--set global.env=prod",
+		Values: map[string]interface{}{"global": map[string]interface{}{"env": "prod"}},
+	}}
+
+	explanations := ExplainValues(parent, computed, sources)
+	byPath := map[string]ValueExplanation{}
+	var paths []string
+	for _, e := range explanations {
+		byPath[e.Path] = e
+		paths = append(paths, e.Path)
+	}
+
+	assert.Equal(t, []string{
+		"global.env",
+		"image.repository",
+		"image.tag",
+		"sub.global.env",
+		"sub.port",
+		"sub.replicas",
+	}, paths)
+
+	assert.Equal(t, []ValueOrigin{
+		{Source: "chart defaults", File: "parent/values.yaml", Line: 3, Value: "1.0"},
+		{Source: "values file", File: "prod.yaml", Line: 2, Value: "2.0"},
+	}, byPath["image.tag"].Origins)
+	assert.Equal(t, "2.0", byPath["image.tag"].Value)
+
+	assert.Equal(t, []ValueOrigin{
+		{Source: "subchart defaults", File: "parent/charts/sub/values.yaml", Line: 1, Value: 1.0},
+		{Source: "chart defaults", File: "parent/values.yaml", Line: 7, Value: 2.0},
+	}, byPath["sub.replicas"].Origins)
+
+	assert.Equal(t, []ValueOrigin{
+		{Source: "chart defaults", File: "parent/values.yaml", Line: 5, Value: "dev", Global: "global.env"},
+		{Source: "--set global.env=prod", Value: "prod", Global: "global.env"},
+	}, byPath["sub.global.env"].Origins)
+	assert.Equal(t, "prod", byPath["sub.global.env"].Value)
+}
+
+func TestExplainValuesWithoutRawValues(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "stored", Version: "0.1.0"},
+		Values:   map[string]interface{}{"name": "default", "list": []interface{}{"a", "b"}},
+	}
+	computed, err := CoalesceValues(c, map[string]interface{}{"name": "mine"})
+	require.NoError(t, err)
+
+	explanations := ExplainValues(c, computed, []ValuesSource{{
+		Name:   "user-supplied values",
+		Values: map[string]interface{}{"name": "mine"},
+	}})
+	assert.Equal(t, []ValueExplanation{{
+		Path:  "list",
+		Value: []interface{}{"a", "b"},
+		Origins: []ValueOrigin{
+			{Source: "chart defaults", File: "stored/values.yaml", Value: []interface{}{"a", "b"}},
+		},
+	}, {
+		Path:  "name",
+		Value: "mine",
+		Origins: []ValueOrigin{
+			{Source: "chart defaults", File: "stored/values.yaml", Value: "default"},
+			{Source: "user-supplied values", Value: "mine"},
+		},
+	}}, explanations)
+}
+
+func TestValuesLines(t *testing.T) {
+	lines, err := ValuesLines([]byte(`# comment
+a:
+  b: 1
+
+  c:
+    d: true
+e: [1, 2]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"a": 2, "a.b": 3, "a.c": 5, "a.c.d": 6, "e": 7}, lines)
+
+	lines, err = ValuesLines(nil)
+	require.NoError(t, err)
+	assert.Empty(t, lines)
+}
@@ -25,6 +25,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"sigs.k8s.io/yaml"
@@ -105,6 +106,51 @@ func SaveDir(c *chart.Chart, dest string) error {
 //
 // This returns the absolute path to the chart archive file.
 func Save(c *chart.Chart, outDir string) (string, error) {
+	return save(c, outDir, func(out *tar.Writer) error {
+		return writeTarContents(func(name string, body []byte) error {
+			return writeToTar(out, name, body, time.Now())
+		}, c, "")
+	})
+}
+
+// SaveReproducible creates an archived chart to the given directory, like
+// Save, but the archive only depends on the contents of the chart: the files
+// are written in the order of their names, with the given modification time,
+// mode 0644 and no owner. Saving the same chart twice gives the same archive,
+// byte for byte.
+//
+// A zero modTime stands for the Unix epoch.
+func SaveReproducible(c *chart.Chart, outDir string, modTime time.Time) (string, error) {
+	if modTime.IsZero() {
+		modTime = time.Unix(0, 0)
+	}
+	return save(c, outDir, func(out *tar.Writer) error {
+		type file struct {
+			name string
+			body []byte
+		}
+		var files []file
+		err := writeTarContents(func(name string, body []byte) error {
+			files = append(files, file{name: filepath.ToSlash(name), body: body})
+			return nil
+		}, c, "")
+		if err != nil {
+			return err
+		}
+
+		sort.SliceStable(files, func(i, j int) bool { return files[i].name < files[j].name })
+		for _, f := range files {
+			if err := writeToTar(out, f.name, f.body, modTime); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// save creates the archive of a chart in the given directory, with the files
+// written by writeFiles. The archive is removed when writing the files fails.
+func save(c *chart.Chart, outDir string, writeFiles func(*tar.Writer) error) (string, error) {
 	if err := c.Validate(); err != nil {
 		return "", fmt.Errorf("chart validation: %w", err)
 	}
@@ -146,14 +192,16 @@ func Save(c *chart.Chart, outDir string) (string, error) {
 		}
 	}()
 
-	if err := writeTarContents(twriter, c, ""); err != nil {
+	if err := writeFiles(twriter); err != nil {
 		rollback = true
 		return filename, err
 	}
 	return filename, nil
 }
 
-func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
+// writeTarContents writes the files of a chart, and of its dependencies, with
+// write.
+func writeTarContents(write func(name string, body []byte) error, c *chart.Chart, prefix string) error {
 	err := validateName(c.Name())
 	if err != nil {
 		return err
@@ -174,19 +222,19 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 	if err != nil {
 		return err
 	}
-	if err := writeToTar(out, filepath.Join(base, ChartfileName), cdata); err != nil {
+	if err := write(filepath.Join(base, ChartfileName), cdata); err != nil {
 		return err
 	}
 
 	// Save Chart.lock
 	// TODO: remove the APIVersion check when APIVersionV1 is not used anymore
-	if c.Metadata.APIVersion == chart.APIVersionV2 {
+	if c.Metadata.APIVersion != chart.APIVersionV1 {
 		if c.Lock != nil {
 			ldata, err := yaml.Marshal(c.Lock)
 			if err != nil {
 				return err
 			}
-			if err := writeToTar(out, filepath.Join(base, "Chart.lock"), ldata); err != nil {
+			if err := write(filepath.Join(base, "Chart.lock"), ldata); err != nil {
 				return err
 			}
 		}
@@ -195,7 +243,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 	// Save values.yaml
 	for _, f := range c.Raw {
 		if f.Name == ValuesfileName {
-			if err := writeToTar(out, filepath.Join(base, ValuesfileName), f.Data); err != nil {
+			if err := write(filepath.Join(base, ValuesfileName), f.Data); err != nil {
 				return err
 			}
 		}
@@ -206,7 +254,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 		if !json.Valid(c.Schema) {
 			return errors.New("invalid JSON in " + SchemafileName)
 		}
-		if err := writeToTar(out, filepath.Join(base, SchemafileName), c.Schema); err != nil {
+		if err := write(filepath.Join(base, SchemafileName), c.Schema); err != nil {
 			return err
 		}
 	}
@@ -214,7 +262,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 	// Save templates
 	for _, f := range c.Templates {
 		n := filepath.Join(base, f.Name)
-		if err := writeToTar(out, n, f.Data); err != nil {
+		if err := write(n, f.Data); err != nil {
 			return err
 		}
 	}
@@ -222,14 +270,14 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 	// Save files
 	for _, f := range c.Files {
 		n := filepath.Join(base, f.Name)
-		if err := writeToTar(out, n, f.Data); err != nil {
+		if err := write(n, f.Data); err != nil {
 			return err
 		}
 	}
 
 	// Save dependencies
 	for _, dep := range c.Dependencies() {
-		if err := writeTarContents(out, dep, filepath.Join(base, ChartsDir)); err != nil {
+		if err := writeTarContents(write, dep, filepath.Join(base, ChartsDir)); err != nil {
 			return err
 		}
 	}
@@ -237,13 +285,13 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 }
 
 // writeToTar writes a single file to a tar archive.
-func writeToTar(out *tar.Writer, name string, body []byte) error {
+func writeToTar(out *tar.Writer, name string, body []byte, modTime time.Time) error {
 	// TODO: Do we need to create dummy parent directory names if none exist?
 	h := &tar.Header{
 		Name:    filepath.ToSlash(name),
 		Mode:    0644,
 		Size:    int64(len(body)),
-		ModTime: time.Now(),
+		ModTime: modTime,
 	}
 	if err := out.WriteHeader(h); err != nil {
 		return err
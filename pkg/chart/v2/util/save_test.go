@@ -179,6 +179,81 @@ func TestSavePreservesTimestamps(t *testing.T) {
 	}
 }
 
+func TestSaveReproducible(t *testing.T) {
+	modTime := time.Unix(1700000000, 0)
+	newChart := func(files ...*chart.File) *chart.Chart {
+		return &chart.Chart{
+			Metadata: &chart.Metadata{
+				APIVersion: chart.APIVersionV2,
+				Name:       "ahab",
+				Version:    "1.2.3",
+			},
+			Templates: []*chart.File{
+				{Name: "templates/service.yaml", Data: []byte("kind: Service")},
+				{Name: "templates/deployment.yaml", Data: []byte("kind: Deployment")},
+			},
+			Files: files,
+		}
+	}
+	shahryar := &chart.File{Name: "scheherazade/shahryar.txt", Data: []byte("1,001 Nights")}
+	readme := &chart.File{Name: "README.md", Data: []byte("# ahab")}
+
+	first, err := SaveReproducible(newChart(shahryar, readme), t.TempDir(), modTime)
+	if err != nil {
+		t.Fatalf("Failed to save: %s", err)
+	}
+	second, err := SaveReproducible(newChart(readme, shahryar), t.TempDir(), modTime)
+	if err != nil {
+		t.Fatalf("Failed to save: %s", err)
+	}
+
+	data1, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data2, err := os.ReadFile(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data1, data2) {
+		t.Fatal("Expected saving the same chart twice to give the same archive")
+	}
+
+	headers, err := retrieveAllHeadersFromTar(first)
+	if err != nil {
+		t.Fatalf("Failed to parse tar: %v", err)
+	}
+	var names []string
+	for _, h := range headers {
+		names = append(names, h.Name)
+		if !h.ModTime.Equal(modTime) || h.Mode != 0644 || h.Uid != 0 || h.Gid != 0 || h.Uname != "" || h.Gname != "" {
+			t.Errorf("Unexpected header for %s: %+v", h.Name, h)
+		}
+	}
+	expect := []string{
+		"ahab/Chart.yaml",
+		"ahab/README.md",
+		"ahab/scheherazade/shahryar.txt",
+		"ahab/templates/deployment.yaml",
+		"ahab/templates/service.yaml",
+	}
+	if strings.Join(names, ",") != strings.Join(expect, ",") {
+		t.Errorf("Expected files %v, got %v", expect, names)
+	}
+
+	where, err := SaveReproducible(newChart(), t.TempDir(), time.Time{})
+	if err != nil {
+		t.Fatalf("Failed to save: %s", err)
+	}
+	headers, err = retrieveAllHeadersFromTar(where)
+	if err != nil {
+		t.Fatalf("Failed to parse tar: %v", err)
+	}
+	if !headers[0].ModTime.Equal(time.Unix(0, 0)) {
+		t.Errorf("Expected a zero modification time to stand for the Unix epoch, got %v", headers[0].ModTime)
+	}
+}
+
 // We could refactor `load.go` to use this `retrieveAllHeadersFromTar` function
 // as well, so we are not duplicating components of the code which iterate
 // through the tar.
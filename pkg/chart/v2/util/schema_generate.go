@@ -0,0 +1,321 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// SchemaDialect is the JSON schema dialect of generated schemas.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// SchemaAnnotation is the prefix of the comments annotating values for
+// schema generation, e.g.
+//
+//	# @schema description: The number of replicas
+//	# @schema minimum: 1
+//	replicas: 3
+const SchemaAnnotation = "@schema"
+
+// schemaAnnotations are the keywords accepted in annotations.
+var schemaAnnotations = []string{"description", "type", "enum", "required", "pattern", "minimum", "maximum"}
+
+// GenerateSchema generates a JSON schema for the values of a chart.
+//
+// The types are inferred from the chart's values.yaml and refined by
+// "# @schema" comment annotations on the keys. The schemas of the
+// subcharts, either their own values.schema.json or one generated from their
+// values, are merged in under the subchart's name or alias.
+func GenerateSchema(chrt *chart.Chart) ([]byte, error) {
+	schema, err := generateSchema(chrt)
+	if err != nil {
+		return nil, err
+	}
+	schema["$schema"] = SchemaDialect
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func generateSchema(c *chart.Chart) (map[string]interface{}, error) {
+	schema := map[string]interface{}{"type": "object"}
+	for _, f := range c.Raw {
+		if f.Name != ValuesfileName {
+			continue
+		}
+		var doc yaml.Node
+		if err := yaml.NewDecoder(bytes.NewReader(f.Data)).Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s: %w", c.ChartFullPath()+"/"+ValuesfileName, err)
+		}
+		if len(doc.Content) > 0 {
+			s, err := nodeSchema(doc.Content[0])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", c.ChartFullPath()+"/"+ValuesfileName, err)
+			}
+			schema = s
+		}
+	}
+
+	for key, sub := range subchartsByKey(c) {
+		subSchema, err := loadSubchartSchema(sub)
+		if err != nil {
+			return nil, err
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		if props == nil {
+			props = map[string]interface{}{}
+			schema["properties"] = props
+		}
+		if own, ok := props[key].(map[string]interface{}); ok {
+			subSchema = mergeSchemas(subSchema, own)
+		}
+		props[key] = subSchema
+	}
+	return schema, nil
+}
+
+// subchartsByKey maps the subcharts of a chart to the key of their values,
+// which is the alias of the dependency if it has one.
+func subchartsByKey(c *chart.Chart) map[string]*chart.Chart {
+	subs := map[string]*chart.Chart{}
+	var declared []string
+	if c.Metadata != nil {
+		for _, dep := range c.Metadata.Dependencies {
+			declared = append(declared, dep.Name)
+			for _, sub := range c.Dependencies() {
+				if sub.Name() != dep.Name {
+					continue
+				}
+				key := dep.Name
+				if dep.Alias != "" {
+					key = dep.Alias
+				}
+				subs[key] = sub
+			}
+		}
+	}
+	// Subcharts vendored in charts/ without being declared.
+	for _, sub := range c.Dependencies() {
+		if !slices.Contains(declared, sub.Name()) {
+			subs[sub.Name()] = sub
+		}
+	}
+	return subs
+}
+
+// loadSubchartSchema returns the schema shipped with a subchart, or generates one.
+func loadSubchartSchema(sub *chart.Chart) (map[string]interface{}, error) {
+	if len(sub.Schema) == 0 {
+		return generateSchema(sub)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(sub.Schema, &schema); err != nil {
+		return nil, fmt.Errorf("%s/values.schema.json: %w", sub.ChartFullPath(), err)
+	}
+	delete(schema, "$schema")
+	return schema, nil
+}
+
+// mergeSchemas merges override into base. Properties are merged recursively,
+// required keys are combined and any other keyword of override wins.
+func mergeSchemas(base, override map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(base))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range override {
+		switch k {
+		case "properties":
+			baseProps, _ := out[k].(map[string]interface{})
+			props := make(map[string]interface{}, len(baseProps))
+			for name, p := range baseProps {
+				props[name] = p
+			}
+			for name, p := range v.(map[string]interface{}) {
+				bp, ok1 := props[name].(map[string]interface{})
+				op, ok2 := p.(map[string]interface{})
+				if ok1 && ok2 {
+					props[name] = mergeSchemas(bp, op)
+				} else {
+					props[name] = p
+				}
+			}
+			out[k] = props
+		case "required":
+			required := toStrings(out[k])
+			for _, r := range toStrings(v) {
+				if !slices.Contains(required, r) {
+					required = append(required, r)
+				}
+			}
+			slices.Sort(required)
+			out[k] = required
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func toStrings(v interface{}) []string {
+	var out []string
+	switch l := v.(type) {
+	case []string:
+		out = append(out, l...)
+	case []interface{}:
+		for _, s := range l {
+			out = append(out, fmt.Sprint(s))
+		}
+	}
+	return out
+}
+
+// nodeSchema infers the schema of a YAML node.
+func nodeSchema(n *yaml.Node) (map[string]interface{}, error) {
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	schema := map[string]interface{}{}
+	switch n.Kind {
+	case yaml.MappingNode:
+		schema["type"] = "object"
+		props := map[string]interface{}{}
+		var required []string
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, val := n.Content[i], n.Content[i+1]
+			if key.Tag == "!!merge" {
+				continue
+			}
+			p, err := nodeSchema(val)
+			if err != nil {
+				return nil, err
+			}
+			req, err := applyAnnotations(p, key, val)
+			if err != nil {
+				return nil, err
+			}
+			if req {
+				required = append(required, key.Value)
+			}
+			props[key.Value] = p
+		}
+		if len(props) > 0 {
+			schema["properties"] = props
+		}
+		if len(required) > 0 {
+			slices.Sort(required)
+			schema["required"] = required
+		}
+	case yaml.SequenceNode:
+		schema["type"] = "array"
+		var items map[string]interface{}
+		for i, e := range n.Content {
+			s, err := nodeSchema(e)
+			if err != nil {
+				return nil, err
+			}
+			if i == 0 {
+				items = s
+			} else if items != nil && items["type"] != s["type"] {
+				// Mixed lists are left open.
+				items = nil
+			}
+		}
+		if len(items) > 0 {
+			schema["items"] = items
+		}
+	case yaml.ScalarNode:
+		switch n.ShortTag() {
+		case "!!str", "!!binary", "!!timestamp":
+			schema["type"] = "string"
+		case "!!int":
+			schema["type"] = "integer"
+		case "!!float":
+			schema["type"] = "number"
+		case "!!bool":
+			schema["type"] = "boolean"
+		}
+		// A null default says nothing about the type.
+	}
+	return schema, nil
+}
+
+// applyAnnotations applies the "# @schema" annotations in the comments of a
+// key to its schema. It reports whether the key was annotated as required.
+func applyAnnotations(schema map[string]interface{}, key, val *yaml.Node) (bool, error) {
+	required := false
+	comments := strings.Join([]string{key.HeadComment, key.LineComment, val.LineComment}, "\n")
+	for _, line := range strings.Split(comments, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+		rest, ok := strings.CutPrefix(line, SchemaAnnotation)
+		if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimSpace(rest), ":")
+		name = strings.TrimSpace(name)
+		if !slices.Contains(schemaAnnotations, name) {
+			return false, fmt.Errorf("line %d: unknown schema annotation %q on %q", key.Line, name, key.Value)
+		}
+
+		var v interface{}
+		if hasValue {
+			if name == "description" || name == "pattern" {
+				v = strings.TrimSpace(value)
+			} else if err := yaml.Unmarshal([]byte(value), &v); err != nil {
+				return false, fmt.Errorf("line %d: invalid schema annotation %q on %q: %w", key.Line, name, key.Value, err)
+			}
+		}
+
+		switch name {
+		case "required":
+			if !hasValue {
+				required = true
+				continue
+			}
+			b, ok := v.(bool)
+			if !ok {
+				return false, fmt.Errorf("line %d: schema annotation \"required\" on %q must be a boolean", key.Line, key.Value)
+			}
+			required = b
+		case "enum":
+			l, ok := v.([]interface{})
+			if !ok {
+				return false, fmt.Errorf("line %d: schema annotation \"enum\" on %q must be a list", key.Line, key.Value)
+			}
+			schema[name] = l
+		default:
+			if !hasValue || v == nil {
+				return false, fmt.Errorf("line %d: schema annotation %q on %q needs a value", key.Line, name, key.Value)
+			}
+			schema[name] = v
+		}
+	}
+	return required, nil
+}
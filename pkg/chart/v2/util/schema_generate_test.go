@@ -0,0 +1,123 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestGenerateSchema(t *testing.T) {
+	parent := chartWithRawValues(t, "parent", `# @schema description: Number of replicas
+# @schema minimum: 1
+# @schema required
+replicaCount: 1
+image:
+  repository: nginx
+  # @schema enum: [Always, IfNotPresent, Never]
+  pullPolicy: IfNotPresent
+  tag: v1 # @schema pattern: ^v[0-9]+
+ports: [80, 443]
+mixed: [1, a]
+ratio: 0.5
+enabled: true
+extra: null
+cache:
+  size: 10
+`)
+	parent.Metadata.Dependencies = []*chart.Dependency{{Name: "redis", Version: "0.1.0", Alias: "cache"}}
+	redis := chartWithRawValues(t, "redis", "port: 6379\n")
+	redis.Schema = []byte(`{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "required": ["port"], "properties": {"port": {"type": "integer"}}}`)
+	parent.AddDependency(redis)
+
+	data, err := GenerateSchema(parent)
+	require.NoError(t, err)
+
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &schema))
+
+	expected := map[string]interface{}{
+		"$schema":  SchemaDialect,
+		"type":     "object",
+		"required": []interface{}{"replicaCount"},
+		"properties": map[string]interface{}{
+			"replicaCount": map[string]interface{}{"type": "integer", "description": "Number of replicas", "minimum": 1.0},
+			"image": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"repository": map[string]interface{}{"type": "string"},
+					"pullPolicy": map[string]interface{}{"type": "string", "enum": []interface{}{"Always", "IfNotPresent", "Never"}},
+					"tag":        map[string]interface{}{"type": "string", "pattern": "^v[0-9]+"},
+				},
+			},
+			"ports":   map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "integer"}},
+			"mixed":   map[string]interface{}{"type": "array"},
+			"ratio":   map[string]interface{}{"type": "number"},
+			"enabled": map[string]interface{}{"type": "boolean"},
+			"extra":   map[string]interface{}{},
+			"cache": map[string]interface{}{
+				"type":     "object",
+				"required": []interface{}{"port"},
+				"properties": map[string]interface{}{
+					"port": map[string]interface{}{"type": "integer"},
+					"size": map[string]interface{}{"type": "integer"},
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, schema)
+
+	// The generated schema accepts the values it was generated from.
+	require.NoError(t, ProcessDependencies(parent, nil))
+	vals, err := CoalesceValues(parent, nil)
+	require.NoError(t, err)
+	assert.NoError(t, ValidateAgainstSingleSchema(vals, data))
+	vals["replicaCount"] = 0
+	assert.Error(t, ValidateAgainstSingleSchema(vals, data))
+}
+
+func TestGenerateSchemaInvalidAnnotation(t *testing.T) {
+	tests := []struct {
+		name   string
+		values string
+		err    string
+	}{{
+		name:   "unknown annotation",
+		values: "# @schema format: email\nemail: a@example.com\n",
+		err:    `line 2: unknown schema annotation "format" on "email"`,
+	}, {
+		name:   "enum without a list",
+		values: "# @schema enum: a\nmode: a\n",
+		err:    `line 2: schema annotation "enum" on "mode" must be a list`,
+	}, {
+		name:   "annotation without a value",
+		values: "# @schema minimum\nreplicas: 1\n",
+		err:    `line 2: schema annotation "minimum" on "replicas" needs a value`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateSchema(chartWithRawValues(t, "bad", tt.values))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,336 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package config reads and writes the Helm configuration file.
+
+The configuration file holds defaults for the flags of the Helm commands and
+the feature gates to enable. Named profiles layer their own defaults on top:
+
+	flags:
+	  namespace: apps
+	commands:
+	  install:
+	    wait: watcher
+	    timeout: 10m
+	  repo add:
+	    force-update: true
+	gates:
+	  HELM_EXPERIMENTAL_FEATURE: true
+	profiles:
+	  ci:
+	    commands:
+	      install:
+	        rollback-on-failure: true
+
+Flags given on the command line take precedence over environment variables,
+which take precedence over the configuration file.
+*/
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// SectionFlags is the section of the defaults of the global flags.
+	SectionFlags = "flags"
+	// SectionGates is the section of the feature gates.
+	SectionGates = "gates"
+)
+
+// Settings are the defaults of a configuration file or of one of its
+// profiles.
+type Settings struct {
+	// Flags are the defaults of the global flags, by flag name.
+	Flags map[string]interface{} `json:"flags,omitempty"`
+	// Commands are the defaults of the flags of a command, by command path
+	// without the leading "helm" (e.g. "repo add") and flag name.
+	Commands map[string]map[string]interface{} `json:"commands,omitempty"`
+	// Gates enable or disable feature gates by name.
+	Gates map[string]bool `json:"gates,omitempty"`
+}
+
+// File is the Helm configuration file.
+type File struct {
+	Settings
+	// Profile is the profile used when none is selected with --profile or
+	// HELM_PROFILE.
+	Profile string `json:"profile,omitempty"`
+	// Profiles are named settings layered on top of the top level ones.
+	Profiles map[string]*Settings `json:"profiles,omitempty"`
+}
+
+// Key identifies a single setting.
+//
+// Keys are written "flags.<flag>" for global flags, "gates.<gate>" for
+// feature gates and "<command>.<flag>" for the flags of a command, where the
+// words of the command path are separated by dots, e.g. "repo.add.force-update".
+type Key struct {
+	// Section is SectionFlags, SectionGates or empty for the flags of a command.
+	Section string
+	// Command is the command path without the leading "helm", e.g. "repo add".
+	Command string
+	// Name is the name of the flag or of the gate.
+	Name string
+}
+
+// Entry is a setting and its value.
+type Entry struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+// ParseKey parses a setting key.
+func ParseKey(key string) (Key, error) {
+	parts := strings.Split(key, ".")
+	if len(parts) < 2 || slices.Contains(parts, "") {
+		return Key{}, fmt.Errorf("invalid key %q: expected flags.<flag>, gates.<gate> or <command>.<flag>", key)
+	}
+	name := parts[len(parts)-1]
+	switch parts[0] {
+	case SectionFlags, SectionGates:
+		if len(parts) != 2 {
+			return Key{}, fmt.Errorf("invalid key %q: expected %s.<name>", key, parts[0])
+		}
+		return Key{Section: parts[0], Name: name}, nil
+	}
+	return Key{Command: strings.Join(parts[:len(parts)-1], " "), Name: name}, nil
+}
+
+// String returns the key in the format accepted by ParseKey.
+func (k Key) String() string {
+	if k.Section != "" {
+		return k.Section + "." + k.Name
+	}
+	return strings.ReplaceAll(k.Command, " ", ".") + "." + k.Name
+}
+
+// LoadFile loads a configuration file. A missing file is an empty
+// configuration.
+func LoadFile(path string) (*File, error) {
+	f := new(File)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return f, nil
+		}
+		return nil, fmt.Errorf("couldn't load configuration file (%s): %w", path, err)
+	}
+	if err := yaml.UnmarshalStrict(b, f); err != nil {
+		return nil, fmt.Errorf("couldn't load configuration file (%s): %w", path, err)
+	}
+	return f, nil
+}
+
+// WriteFile writes the configuration file, creating its directory if needed.
+func (f *File) WriteFile(path string, perm os.FileMode) error {
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+// Layer returns the settings of a profile, or the top level settings if
+// profile is empty. When create is set, a missing profile is added.
+func (f *File) Layer(profile string, create bool) (*Settings, error) {
+	if profile == "" {
+		return &f.Settings, nil
+	}
+	if s, ok := f.Profiles[profile]; ok && s != nil {
+		return s, nil
+	}
+	if !create {
+		return nil, fmt.Errorf("profile %q not found in the configuration file", profile)
+	}
+	if f.Profiles == nil {
+		f.Profiles = map[string]*Settings{}
+	}
+	s := &Settings{}
+	f.Profiles[profile] = s
+	return s, nil
+}
+
+// Resolve returns the settings in effect for a profile: the top level
+// settings overridden by those of the profile. An empty profile selects the
+// default profile of the file, if any.
+func (f *File) Resolve(profile string) (*Settings, error) {
+	if profile == "" {
+		profile = f.Profile
+	}
+	out := &Settings{}
+	out.merge(&f.Settings)
+	if profile != "" {
+		s, err := f.Layer(profile, false)
+		if err != nil {
+			return nil, err
+		}
+		out.merge(s)
+	}
+	return out, nil
+}
+
+func (s *Settings) merge(o *Settings) {
+	for name, v := range o.Flags {
+		s.set(Key{Section: SectionFlags, Name: name}, v)
+	}
+	for cmd, flags := range o.Commands {
+		for name, v := range flags {
+			s.set(Key{Command: cmd, Name: name}, v)
+		}
+	}
+	for name, v := range o.Gates {
+		s.set(Key{Section: SectionGates, Name: name}, v)
+	}
+}
+
+// Get returns the value of a setting.
+func (s *Settings) Get(k Key) (interface{}, bool) {
+	switch k.Section {
+	case SectionFlags:
+		v, ok := s.Flags[k.Name]
+		return v, ok
+	case SectionGates:
+		v, ok := s.Gates[k.Name]
+		return v, ok
+	}
+	v, ok := s.Commands[k.Command][k.Name]
+	return v, ok
+}
+
+// Set sets a setting from its string form. Gates take a boolean. Booleans
+// and integers written the canonical way are stored as such, anything else
+// as a string.
+func (s *Settings) Set(k Key, value string) error {
+	if k.Section == SectionGates {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for %s: must be a boolean", value, k)
+		}
+		s.set(k, b)
+		return nil
+	}
+	if b, err := strconv.ParseBool(value); err == nil && strconv.FormatBool(b) == value {
+		s.set(k, b)
+	} else if i, err := strconv.Atoi(value); err == nil && strconv.Itoa(i) == value {
+		s.set(k, i)
+	} else {
+		s.set(k, value)
+	}
+	return nil
+}
+
+func (s *Settings) set(k Key, v interface{}) {
+	switch k.Section {
+	case SectionFlags:
+		if s.Flags == nil {
+			s.Flags = map[string]interface{}{}
+		}
+		s.Flags[k.Name] = v
+	case SectionGates:
+		if s.Gates == nil {
+			s.Gates = map[string]bool{}
+		}
+		b, _ := v.(bool)
+		s.Gates[k.Name] = b
+	default:
+		if s.Commands == nil {
+			s.Commands = map[string]map[string]interface{}{}
+		}
+		if s.Commands[k.Command] == nil {
+			s.Commands[k.Command] = map[string]interface{}{}
+		}
+		s.Commands[k.Command][k.Name] = v
+	}
+}
+
+// Unset removes a setting. It reports whether the setting was set.
+func (s *Settings) Unset(k Key) bool {
+	if _, ok := s.Get(k); !ok {
+		return false
+	}
+	switch k.Section {
+	case SectionFlags:
+		delete(s.Flags, k.Name)
+	case SectionGates:
+		delete(s.Gates, k.Name)
+	default:
+		delete(s.Commands[k.Command], k.Name)
+		if len(s.Commands[k.Command]) == 0 {
+			delete(s.Commands, k.Command)
+		}
+	}
+	return true
+}
+
+// Entries returns all the settings sorted by key.
+func (s *Settings) Entries() []Entry {
+	var entries []Entry
+	for name, v := range s.Flags {
+		entries = append(entries, Entry{Key: Key{Section: SectionFlags, Name: name}.String(), Value: v})
+	}
+	for cmd, flags := range s.Commands {
+		for name, v := range flags {
+			entries = append(entries, Entry{Key: Key{Command: cmd, Name: name}.String(), Value: v})
+		}
+	}
+	for name, v := range s.Gates {
+		entries = append(entries, Entry{Key: Key{Section: SectionGates, Name: name}.String(), Value: v})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
+// FlagValues returns the values to set a flag to for a setting value. Lists
+// give one value per item, anything else is formatted as a single value.
+func FlagValues(v interface{}) []string {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			out = append(out, formatValue(item))
+		}
+		return out
+	}
+	return []string{formatValue(v)}
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		// sigs.k8s.io/yaml reads all numbers as float64.
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,167 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `flags:
+  namespace: apps
+commands:
+  install:
+    wait: watcher
+    timeout: 10m
+  repo add:
+    force-update: true
+gates:
+  HELM_EXPERIMENTAL_FEATURE: true
+profile: dev
+profiles:
+  dev:
+    flags:
+      namespace: dev
+  ci:
+    commands:
+      install:
+        rollback-on-failure: true
+        set:
+        - a=1
+        - b=2
+    gates:
+      HELM_EXPERIMENTAL_FEATURE: false
+`
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want Key
+		err  string
+	}{
+		{key: "flags.namespace", want: Key{Section: SectionFlags, Name: "namespace"}},
+		{key: "gates.HELM_EXPERIMENTAL_FEATURE", want: Key{Section: SectionGates, Name: "HELM_EXPERIMENTAL_FEATURE"}},
+		{key: "install.wait", want: Key{Command: "install", Name: "wait"}},
+		{key: "repo.add.force-update", want: Key{Command: "repo add", Name: "force-update"}},
+		{key: "install", err: "invalid key"},
+		{key: "install..wait", err: "invalid key"},
+		{key: "flags.a.b", err: "expected flags.<name>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			k, err := ParseKey(tt.key)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, k)
+			assert.Equal(t, tt.key, k.String())
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	f, err := LoadFile(filepath.Join(dir, "missing.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, &File{}, f)
+
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("flag:\n  namespace: apps\n"), 0644))
+	_, err = LoadFile(path)
+	assert.ErrorContains(t, err, "couldn't load configuration file")
+}
+
+func TestResolve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testConfig), 0644))
+	f, err := LoadFile(path)
+	require.NoError(t, err)
+
+	// The default profile of the file applies.
+	s, err := f.Resolve("")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"namespace": "dev"}, s.Flags)
+
+	s, err = f.Resolve("ci")
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Key: "flags.namespace", Value: "apps"},
+		{Key: "gates.HELM_EXPERIMENTAL_FEATURE", Value: false},
+		{Key: "install.rollback-on-failure", Value: true},
+		{Key: "install.set", Value: []interface{}{"a=1", "b=2"}},
+		{Key: "install.timeout", Value: "10m"},
+		{Key: "install.wait", Value: "watcher"},
+		{Key: "repo.add.force-update", Value: true},
+	}, s.Entries())
+
+	// Resolving does not change the file.
+	assert.Equal(t, map[string]bool{"HELM_EXPERIMENTAL_FEATURE": true}, f.Gates)
+
+	_, err = f.Resolve("prod")
+	assert.EqualError(t, err, `profile "prod" not found in the configuration file`)
+}
+
+func TestSetUnsetAndWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "helm", "config.yaml")
+	f, err := LoadFile(path)
+	require.NoError(t, err)
+
+	layer, err := f.Layer("ci", true)
+	require.NoError(t, err)
+	require.NoError(t, layer.Set(Key{Command: "upgrade", Name: "timeout"}, "10m"))
+	require.NoError(t, f.Set(Key{Section: SectionGates, Name: "HELM_EXPERIMENTAL_FEATURE"}, "true"))
+	assert.ErrorContains(t, f.Set(Key{Section: SectionGates, Name: "HELM_EXPERIMENTAL_FEATURE"}, "maybe"), "must be a boolean")
+	require.NoError(t, f.WriteFile(path, 0600))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `gates:
+  HELM_EXPERIMENTAL_FEATURE: true
+profiles:
+  ci:
+    commands:
+      upgrade:
+        timeout: 10m
+`, string(data))
+
+	f, err = LoadFile(path)
+	require.NoError(t, err)
+	layer, err = f.Layer("ci", false)
+	require.NoError(t, err)
+	v, ok := layer.Get(Key{Command: "upgrade", Name: "timeout"})
+	assert.True(t, ok)
+	assert.Equal(t, "10m", v)
+
+	assert.True(t, layer.Unset(Key{Command: "upgrade", Name: "timeout"}))
+	assert.False(t, layer.Unset(Key{Command: "upgrade", Name: "timeout"}))
+	assert.Empty(t, layer.Commands)
+}
+
+func TestFlagValues(t *testing.T) {
+	assert.Equal(t, []string{"10m"}, FlagValues("10m"))
+	assert.Equal(t, []string{"true"}, FlagValues(true))
+	assert.Equal(t, []string{"300"}, FlagValues(float64(300)))
+	assert.Equal(t, []string{"a=1", "b=2"}, FlagValues([]interface{}{"a=1", "b=2"}))
+	assert.Nil(t, FlagValues(nil))
+}
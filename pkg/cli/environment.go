@@ -93,30 +93,36 @@ type EnvSettings struct {
 	ColorMode string
 	// ContentCache is the location where cached charts are stored
 	ContentCache string
+	// ConfigFile is the path to the Helm configuration file.
+	ConfigFile string
+	// Profile is the profile of the configuration file to use.
+	Profile string
 }
 
 func New() *EnvSettings {
 	env := &EnvSettings{
-		namespace:                 os.Getenv("HELM_NAMESPACE"),
+		namespace:                 os.Getenv(flagEnvVar("namespace")),
 		MaxHistory:                envIntOr("HELM_MAX_HISTORY", defaultMaxHistory),
-		KubeContext:               os.Getenv("HELM_KUBECONTEXT"),
-		KubeToken:                 os.Getenv("HELM_KUBETOKEN"),
-		KubeAsUser:                os.Getenv("HELM_KUBEASUSER"),
-		KubeAsGroups:              envCSV("HELM_KUBEASGROUPS"),
-		KubeAPIServer:             os.Getenv("HELM_KUBEAPISERVER"),
-		KubeCaFile:                os.Getenv("HELM_KUBECAFILE"),
-		KubeTLSServerName:         os.Getenv("HELM_KUBETLS_SERVER_NAME"),
-		KubeInsecureSkipTLSVerify: envBoolOr("HELM_KUBEINSECURE_SKIP_TLS_VERIFY", false),
+		KubeContext:               os.Getenv(flagEnvVar("kube-context")),
+		KubeToken:                 os.Getenv(flagEnvVar("kube-token")),
+		KubeAsUser:                os.Getenv(flagEnvVar("kube-as-user")),
+		KubeAsGroups:              envCSV(flagEnvVar("kube-as-group")),
+		KubeAPIServer:             os.Getenv(flagEnvVar("kube-apiserver")),
+		KubeCaFile:                os.Getenv(flagEnvVar("kube-ca-file")),
+		KubeTLSServerName:         os.Getenv(flagEnvVar("kube-tls-server-name")),
+		KubeInsecureSkipTLSVerify: envBoolOr(flagEnvVar("kube-insecure-skip-tls-verify"), false),
 		PluginsDirectory:          envOr("HELM_PLUGINS", helmpath.DataPath("plugins")),
-		RegistryConfig:            envOr("HELM_REGISTRY_CONFIG", helmpath.ConfigPath("registry/config.json")),
-		RepositoryConfig:          envOr("HELM_REPOSITORY_CONFIG", helmpath.ConfigPath("repositories.yaml")),
-		RepositoryCache:           envOr("HELM_REPOSITORY_CACHE", helmpath.CachePath("repository")),
-		ContentCache:              envOr("HELM_CONTENT_CACHE", helmpath.CachePath("content")),
-		BurstLimit:                envIntOr("HELM_BURST_LIMIT", defaultBurstLimit),
-		QPS:                       envFloat32Or("HELM_QPS", defaultQPS),
+		RegistryConfig:            envOr(flagEnvVar("registry-config"), helmpath.ConfigPath("registry/config.json")),
+		RepositoryConfig:          envOr(flagEnvVar("repository-config"), helmpath.ConfigPath("repositories.yaml")),
+		RepositoryCache:           envOr(flagEnvVar("repository-cache"), helmpath.CachePath("repository")),
+		ContentCache:              envOr(flagEnvVar("content-cache"), helmpath.CachePath("content")),
+		BurstLimit:                envIntOr(flagEnvVar("burst-limit"), defaultBurstLimit),
+		QPS:                       envFloat32Or(flagEnvVar("qps"), defaultQPS),
 		ColorMode:                 envColorMode(),
+		ConfigFile:                envOr("HELM_CONFIG", helmpath.ConfigPath("config.yaml")),
+		Profile:                   os.Getenv(flagEnvVar("profile")),
 	}
-	env.Debug, _ = strconv.ParseBool(os.Getenv("HELM_DEBUG"))
+	env.Debug, _ = strconv.ParseBool(os.Getenv(flagEnvVar("debug")))
 
 	// bind to kubernetes config flags
 	config := &genericclioptions.ConfigFlags{
@@ -169,6 +175,56 @@ func (s *EnvSettings) AddFlags(fs *pflag.FlagSet) {
 	fs.Float32Var(&s.QPS, "qps", s.QPS, "queries per second used when communicating with the Kubernetes API, not including bursting")
 	fs.StringVar(&s.ColorMode, "color", s.ColorMode, "use colored output (never, auto, always)")
 	fs.StringVar(&s.ColorMode, "colour", s.ColorMode, "use colored output (never, auto, always)")
+	fs.StringVar(&s.Profile, "profile", s.Profile, "profile of the Helm configuration file to use")
+}
+
+// flagEnvVars are the environment variables that set the defaults of the
+// global flags. New reads the defaults from the first variable of each flag,
+// except for the kubeconfig, which is read by client-go, and the color, see
+// envColorMode.
+var flagEnvVars = map[string][]string{
+	"namespace":                     {"HELM_NAMESPACE"},
+	"kubeconfig":                    {"KUBECONFIG"},
+	"kube-context":                  {"HELM_KUBECONTEXT"},
+	"kube-token":                    {"HELM_KUBETOKEN"},
+	"kube-as-user":                  {"HELM_KUBEASUSER"},
+	"kube-as-group":                 {"HELM_KUBEASGROUPS"},
+	"kube-apiserver":                {"HELM_KUBEAPISERVER"},
+	"kube-ca-file":                  {"HELM_KUBECAFILE"},
+	"kube-tls-server-name":          {"HELM_KUBETLS_SERVER_NAME"},
+	"kube-insecure-skip-tls-verify": {"HELM_KUBEINSECURE_SKIP_TLS_VERIFY"},
+	"debug":                         {"HELM_DEBUG"},
+	"registry-config":               {"HELM_REGISTRY_CONFIG"},
+	"repository-config":             {"HELM_REPOSITORY_CONFIG"},
+	"repository-cache":              {"HELM_REPOSITORY_CACHE"},
+	"content-cache":                 {"HELM_CONTENT_CACHE"},
+	"burst-limit":                   {"HELM_BURST_LIMIT"},
+	"qps":                           {"HELM_QPS"},
+	"color":                         {"HELM_COLOR", "NO_COLOR"},
+	"colour":                        {"HELM_COLOR", "NO_COLOR"},
+	"profile":                       {"HELM_PROFILE"},
+}
+
+// flagEnvVar returns the environment variable that sets the default of a
+// global flag.
+func flagEnvVar(name string) string {
+	envs, ok := flagEnvVars[name]
+	if !ok {
+		panic("no environment variable for the flag " + name)
+	}
+	return envs[0]
+}
+
+// FlagSetByEnv reports whether the default of a global flag was set by an
+// environment variable. Environment variables take precedence over the
+// defaults of the Helm configuration file.
+func FlagSetByEnv(name string) bool {
+	for _, env := range flagEnvVars[name] {
+		if _, ok := os.LookupEnv(env); ok {
+			return true
+		}
+	}
+	return false
 }
 
 func envOr(name, def string) string {
@@ -255,6 +311,8 @@ func (s *EnvSettings) EnvVars() map[string]string {
 		"HELM_MAX_HISTORY":       strconv.Itoa(s.MaxHistory),
 		"HELM_BURST_LIMIT":       strconv.Itoa(s.BurstLimit),
 		"HELM_QPS":               strconv.FormatFloat(float64(s.QPS), 'f', 2, 32),
+		"HELM_CONFIG":            s.ConfigFile,
+		"HELM_PROFILE":           s.Profile,
 
 		// broken, these are populated from helm flags and not kubeconfig.
 		"HELM_KUBECONTEXT":                  s.KubeContext,
@@ -272,3 +272,22 @@ func resetEnv() func() {
 		}
 	}
 }
+
+func TestFlagSetByEnv(t *testing.T) {
+	defer resetEnv()()
+
+	if FlagSetByEnv("namespace") {
+		t.Error("expected the namespace not to be set by the environment")
+	}
+	t.Setenv("HELM_NAMESPACE", "apps")
+	if !FlagSetByEnv("namespace") {
+		t.Error("expected the namespace to be set by HELM_NAMESPACE")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if !FlagSetByEnv("color") || !FlagSetByEnv("colour") {
+		t.Error("expected the color mode to be set by NO_COLOR")
+	}
+	if FlagSetByEnv("timeout") {
+		t.Error("expected flags without environment variable not to be set by the environment")
+	}
+}
@@ -25,7 +25,9 @@ import (
 	"os"
 	"strings"
 
+	"helm.sh/helm/v4/internal/sops"
 	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/getter"
 	"helm.sh/helm/v4/pkg/strvals"
 )
@@ -38,81 +40,248 @@ type Options struct {
 	FileValues    []string // --set-file
 	JSONValues    []string // --set-json
 	LiteralValues []string // --set-literal
+	ValuesFrom    []string // --values-from
+
+	// Reader resolves the references of ValuesFrom. It is required when
+	// ValuesFrom is set.
+	Reader Reader
+
+	// stdin keeps the values read from stdin, which can only be read once.
+	stdin []byte
+	// read keeps the sources last read by MergeValues, so that Sources does
+	// not fetch and decrypt the files and references again.
+	read []valuesSource
 }
 
-// MergeValues merges values from files specified via -f/--values and directly
-// via --set-json, --set, --set-string, or --set-file, marshaling them to YAML
+// MergeValues merges values from files specified via -f/--values, from the
+// Secrets and ConfigMaps specified via --values-from and directly via
+// --set-json, --set, --set-string, or --set-file, marshaling them to YAML.
+//
+// Values files and references encrypted with SOPS or age are decrypted in
+// memory, see the sops package.
 func (opts *Options) MergeValues(p getter.Providers) (map[string]interface{}, error) {
+	sources, err := opts.sources(p)
+	if err != nil {
+		return nil, err
+	}
+	opts.read = sources
 	base := map[string]interface{}{}
+	for _, src := range sources {
+		if base, err = src.merge(base); err != nil {
+			return nil, err
+		}
+	}
+	return base, nil
+}
+
+// Sources returns the values of every file and flag as a separate source, in
+// the order MergeValues merges them. It is used to explain where the merged
+// values come from. After MergeValues, the files and references it read are
+// used as they are, without reading them again.
+func (opts *Options) Sources(p getter.Providers) ([]chartutil.ValuesSource, error) {
+	sources := opts.read
+	if sources == nil {
+		var err error
+		if sources, err = opts.sources(p); err != nil {
+			return nil, err
+		}
+	}
+	var out []chartutil.ValuesSource
+	for _, src := range sources {
+		vals, err := src.merge(map[string]interface{}{})
+		if err != nil {
+			return nil, err
+		}
+		var lines map[string]int
+		if src.raw != nil {
+			if lines, err = chartutil.ValuesLines(src.raw); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", src.file, err)
+			}
+		}
+		out = append(out, chartutil.ValuesSource{Name: src.name, File: src.file, Values: vals, Lines: lines})
+	}
+	return out, nil
+}
+
+// valuesSource is a file or a flag values are read from.
+type valuesSource struct {
+	name string
+	// file is the name of the file or reference the values are read from,
+	// empty for flags.
+	file string
+	// raw is the content of the file, nil for flags. The keys of an
+	// encrypted file are in the clear, so it is kept as written.
+	raw []byte
+	// merge parses the values and merges them into base, returning the
+	// merged values. Flags are parsed into base, so that e.g. --set can set
+	// a single item of a list given in a values file.
+	merge func(base map[string]interface{}) (map[string]interface{}, error)
+}
+
+// sources returns the files and flags values are read from, in the order
+// they are merged.
+func (opts *Options) sources(p getter.Providers) ([]valuesSource, error) {
+	var sources []valuesSource
 
 	// User specified a values files via -f/--values
 	for _, filePath := range opts.ValueFiles {
-		raw, err := readFile(filePath, p)
+		raw, err := opts.readFile(filePath, p)
 		if err != nil {
 			return nil, err
 		}
-		currentMap, err := loader.LoadValues(bytes.NewReader(raw))
+		name := filePath
+		if strings.TrimSpace(filePath) == "-" {
+			name = "<stdin>"
+		}
+		data, err := decrypt(filePath, raw)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+			return nil, err
 		}
-		// Merge with the previous map
-		base = loader.MergeMaps(base, currentMap)
+		sources = append(sources, valuesSource{name: "values file", file: name, raw: raw, merge: func(base map[string]interface{}) (map[string]interface{}, error) {
+			currentMap, err := loader.LoadValues(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+			}
+			// Merge with the previous map
+			return loader.MergeMaps(base, currentMap), nil
+		}})
+	}
+
+	// User specified values kept in the cluster via --values-from
+	for _, value := range opts.ValuesFrom {
+		ref, raw, err := opts.readReference(value)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, valuesSource{name: "--values-from", file: ref.String(), raw: raw, merge: func(base map[string]interface{}) (map[string]interface{}, error) {
+			currentMap, err := loader.LoadValues(bytes.NewReader(raw))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", ref, err)
+			}
+			return loader.MergeMaps(base, currentMap), nil
+		}})
 	}
 
 	// User specified a value via --set-json
 	for _, value := range opts.JSONValues {
-		trimmedValue := strings.TrimSpace(value)
-		if len(trimmedValue) > 0 && trimmedValue[0] == '{' {
-			// If value is JSON object format, parse it as map
-			var jsonMap map[string]interface{}
-			if err := json.Unmarshal([]byte(trimmedValue), &jsonMap); err != nil {
-				return nil, fmt.Errorf("failed parsing --set-json data JSON: %s", value)
+		sources = append(sources, valuesSource{name: "--set-json " + value, merge: func(base map[string]interface{}) (map[string]interface{}, error) {
+			trimmedValue := strings.TrimSpace(value)
+			if len(trimmedValue) > 0 && trimmedValue[0] == '{' {
+				// If value is JSON object format, parse it as map
+				var jsonMap map[string]interface{}
+				if err := json.Unmarshal([]byte(trimmedValue), &jsonMap); err != nil {
+					return nil, fmt.Errorf("failed parsing --set-json data JSON: %s", value)
+				}
+				return loader.MergeMaps(base, jsonMap), nil
 			}
-			base = loader.MergeMaps(base, jsonMap)
-		} else {
 			// Otherwise, parse it as key=value format
 			if err := strvals.ParseJSON(value, base); err != nil {
 				return nil, fmt.Errorf("failed parsing --set-json data %s", value)
 			}
-		}
+			return base, nil
+		}})
 	}
 
 	// User specified a value via --set
 	for _, value := range opts.Values {
-		if err := strvals.ParseInto(value, base); err != nil {
-			return nil, fmt.Errorf("failed parsing --set data: %w", err)
-		}
+		sources = append(sources, valuesSource{name: "--set " + value, merge: func(base map[string]interface{}) (map[string]interface{}, error) {
+			if err := strvals.ParseInto(value, base); err != nil {
+				return nil, fmt.Errorf("failed parsing --set data: %w", err)
+			}
+			return base, nil
+		}})
 	}
 
 	// User specified a value via --set-string
 	for _, value := range opts.StringValues {
-		if err := strvals.ParseIntoString(value, base); err != nil {
-			return nil, fmt.Errorf("failed parsing --set-string data: %w", err)
-		}
+		sources = append(sources, valuesSource{name: "--set-string " + value, merge: func(base map[string]interface{}) (map[string]interface{}, error) {
+			if err := strvals.ParseIntoString(value, base); err != nil {
+				return nil, fmt.Errorf("failed parsing --set-string data: %w", err)
+			}
+			return base, nil
+		}})
 	}
 
 	// User specified a value via --set-file
 	for _, value := range opts.FileValues {
+		// The files are read once, however often the values are merged.
+		files := map[string]string{}
 		reader := func(rs []rune) (interface{}, error) {
-			bytes, err := readFile(string(rs), p)
+			if data, ok := files[string(rs)]; ok {
+				return data, nil
+			}
+			bytes, err := opts.readFile(string(rs), p)
 			if err != nil {
 				return nil, err
 			}
+			files[string(rs)] = string(bytes)
 			return string(bytes), err
 		}
-		if err := strvals.ParseIntoFile(value, base, reader); err != nil {
-			return nil, fmt.Errorf("failed parsing --set-file data: %w", err)
-		}
+		sources = append(sources, valuesSource{name: "--set-file " + value, merge: func(base map[string]interface{}) (map[string]interface{}, error) {
+			if err := strvals.ParseIntoFile(value, base, reader); err != nil {
+				return nil, fmt.Errorf("failed parsing --set-file data: %w", err)
+			}
+			return base, nil
+		}})
 	}
 
 	// User specified a value via --set-literal
 	for _, value := range opts.LiteralValues {
-		if err := strvals.ParseLiteralInto(value, base); err != nil {
-			return nil, fmt.Errorf("failed parsing --set-literal data: %w", err)
-		}
+		sources = append(sources, valuesSource{name: "--set-literal " + value, merge: func(base map[string]interface{}) (map[string]interface{}, error) {
+			if err := strvals.ParseLiteralInto(value, base); err != nil {
+				return nil, fmt.Errorf("failed parsing --set-literal data: %w", err)
+			}
+			return base, nil
+		}})
 	}
 
-	return base, nil
+	return sources, nil
+}
+
+// readReference reads the values a --values-from reference refers to.
+func (opts *Options) readReference(value string) (Reference, []byte, error) {
+	ref, err := ParseReference(value)
+	if err != nil {
+		return ref, nil, err
+	}
+	if opts.Reader == nil {
+		return ref, nil, fmt.Errorf("--values-from %s: values can only be read from the cluster at install or upgrade", value)
+	}
+	raw, err := opts.Reader.Read(ref)
+	if err != nil {
+		return ref, nil, fmt.Errorf("--values-from %s: %w", value, err)
+	}
+	raw, err = decrypt(ref.String(), raw)
+	return ref, raw, err
+}
+
+// decrypt decrypts values encrypted with SOPS. Anything else is
+// returned as is.
+func decrypt(name string, raw []byte) ([]byte, error) {
+	if !sops.IsEncrypted(raw) {
+		return raw, nil
+	}
+	data, err := sops.Decrypt(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// readFile reads a file like the package level readFile, but only reads stdin
+// once so that the values can be merged and explained in the same run.
+func (opts *Options) readFile(filePath string, p getter.Providers) ([]byte, error) {
+	if strings.TrimSpace(filePath) != "-" {
+		return readFile(filePath, p)
+	}
+	if opts.stdin == nil {
+		data, err := readFile(filePath, p)
+		if err != nil {
+			return nil, err
+		}
+		opts.stdin = data
+	}
+	return opts.stdin, nil
 }
 
 // readFile load a file from stdin, the local directory, or a remote file with a url.
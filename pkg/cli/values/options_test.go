@@ -26,6 +26,7 @@ import (
 	"strings"
 	"testing"
 
+	"helm.sh/helm/v4/internal/sops/sopstest"
 	"helm.sh/helm/v4/pkg/getter"
 )
 
@@ -387,3 +388,99 @@ func TestMergeValues(t *testing.T) {
 		})
 	}
 }
+
+func TestSources(t *testing.T) {
+	dir := t.TempDir()
+	valuesFile := filepath.Join(dir, "values.yaml")
+	if err := os.WriteFile(valuesFile, []byte("image:\n  tag: v1\nreplicas: 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{
+		ValueFiles:   []string{valuesFile},
+		Values:       []string{"image.tag=v2", "replicas=3"},
+		StringValues: []string{"image.tag=v3"},
+	}
+	sources, err := opts.Sources(getter.Providers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, s := range sources {
+		names = append(names, s.Name)
+	}
+	expectedNames := []string{"values file", "--set image.tag=v2", "--set replicas=3", "--set-string image.tag=v3"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("Sources() names = %v, want %v", names, expectedNames)
+	}
+
+	if sources[0].File != valuesFile {
+		t.Errorf("Sources() file = %q, want %q", sources[0].File, valuesFile)
+	}
+	if line := sources[0].Lines["image.tag"]; line != 2 {
+		t.Errorf("Sources() line of image.tag = %d, want 2", line)
+	}
+	expected := map[string]interface{}{"replicas": int64(3)}
+	if !reflect.DeepEqual(sources[2].Values, expected) {
+		t.Errorf("Sources() values = %v, want %v", sources[2].Values, expected)
+	}
+}
+
+func TestMergeValuesEncrypted(t *testing.T) {
+	id := sopstest.SetupKey(t)
+
+	dir := t.TempDir()
+	plainFile := filepath.Join(dir, "values.yaml")
+	if err := os.WriteFile(plainFile, []byte("database:\n  user: nobody\n  host: db\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	encryptedFile := filepath.Join(dir, "secrets.yaml")
+	encrypted := sopstest.Encrypt(t, []byte("database:\n    user: admin\n    password: s3cr3t\n    port: 5432\n"), id.Recipient(), "")
+	if err := os.WriteFile(encryptedFile, encrypted, 0644); err != nil {
+		t.Fatal(err)
+	}
+	ageFile := filepath.Join(dir, "region.yaml.age")
+	if err := os.WriteFile(ageFile, sopstest.EncryptAge(t, []byte("region: eu\n"), id.Recipient(), true), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{
+		ValueFiles: []string{plainFile, encryptedFile, ageFile},
+		Values:     []string{"database.port=5433"},
+	}
+	got, err := opts.MergeValues(getter.Providers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	database := got["database"].(map[string]interface{})
+	if database["user"] != "admin" || database["password"] != "s3cr3t" || database["host"] != "db" {
+		t.Errorf("MergeValues() database = %v", database)
+	}
+	if database["port"] != int64(5433) {
+		t.Errorf("MergeValues() database.port = %v, want 5433", database["port"])
+	}
+	if got["region"] != "eu" {
+		t.Errorf("MergeValues() region = %v, want eu", got["region"])
+	}
+
+	// The files are decrypted once for both, so Sources does not need the
+	// key anymore.
+	sopstest.SetupKey(t)
+	sources, err := opts.Sources(getter.Providers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sources[1].Values["database"].(map[string]interface{})["password"] != "s3cr3t" {
+		t.Errorf("Sources() values = %v", sources[1].Values)
+	}
+	// The lines are those of the encrypted file as written.
+	if line := sources[1].Lines["database.password"]; line != 3 {
+		t.Errorf("Sources() line of database.password = %d, want 3", line)
+	}
+
+	_, err = opts.MergeValues(getter.Providers{})
+	if err == nil || !strings.Contains(err.Error(), "failed to decrypt "+encryptedFile) {
+		t.Errorf("MergeValues() error = %v, want a decryption error", err)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The kinds of objects values can be read from with --values-from.
+const (
+	ReferenceSecret    = "secret"
+	ReferenceConfigMap = "configmap"
+)
+
+// DefaultReferenceKey is the key read from a Secret or ConfigMap when a
+// reference does not name one.
+const DefaultReferenceKey = "values.yaml"
+
+// Reference refers to values kept in a Kubernetes Secret or ConfigMap in the
+// namespace of the release. It is written as secret/NAME[:KEY] or
+// configmap/NAME[:KEY].
+type Reference struct {
+	Kind string
+	Name string
+	Key  string
+}
+
+// ParseReference parses a --values-from reference.
+func ParseReference(s string) (Reference, error) {
+	kind, rest, ok := strings.Cut(s, "/")
+	name, key, _ := strings.Cut(rest, ":")
+	if !ok || (kind != ReferenceSecret && kind != ReferenceConfigMap) || name == "" || strings.Contains(name, "/") {
+		return Reference{}, fmt.Errorf("invalid --values-from reference %q: must be secret/NAME[:KEY] or configmap/NAME[:KEY]", s)
+	}
+	if key == "" {
+		key = DefaultReferenceKey
+	}
+	return Reference{Kind: kind, Name: name, Key: key}, nil
+}
+
+// NormalizeReferences parses --values-from references and returns them in
+// their normalized form, e.g. "secret/db:values.yaml" for "secret/db", so
+// that the same reference is always written the same way.
+func NormalizeReferences(refs []string) ([]string, error) {
+	var out []string
+	for _, s := range refs {
+		ref, err := ParseReference(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ref.String())
+	}
+	return out, nil
+}
+
+// String returns the reference in the form accepted by ParseReference.
+func (r Reference) String() string {
+	return fmt.Sprintf("%s/%s:%s", r.Kind, r.Name, r.Key)
+}
+
+// Reader reads the values a Reference refers to.
+type Reader interface {
+	Read(ref Reference) ([]byte, error)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Reference
+		wantErr bool
+	}{
+		{in: "secret/db", want: Reference{Kind: "secret", Name: "db", Key: "values.yaml"}},
+		{in: "secret/db:prod.yaml", want: Reference{Kind: "secret", Name: "db", Key: "prod.yaml"}},
+		{in: "configmap/overrides:values", want: Reference{Kind: "configmap", Name: "overrides", Key: "values"}},
+		{in: "configmap/overrides:", want: Reference{Kind: "configmap", Name: "overrides", Key: "values.yaml"}},
+		{in: "pod/db", wantErr: true},
+		{in: "secret/", wantErr: true},
+		{in: "secret", wantErr: true},
+		{in: "secret/ns/db", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseReference(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseReference(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseReference(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+type mapReader map[string]string
+
+func (m mapReader) Read(ref Reference) ([]byte, error) {
+	data, ok := m[ref.String()]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", ref)
+	}
+	return []byte(data), nil
+}
+
+func TestMergeValuesFrom(t *testing.T) {
+	opts := Options{
+		ValuesFrom: []string{"secret/db", "configmap/overrides:prod.yaml"},
+		Values:     []string{"replicas=5"},
+		Reader: mapReader{
+			"secret/db:values.yaml":         "password: secret\nreplicas: 1\n",
+			"configmap/overrides:prod.yaml": "replicas: 3\nregion: eu\n",
+		},
+	}
+	got, err := opts.MergeValues(getter.Providers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{"password": "secret", "replicas": int64(5), "region": "eu"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("MergeValues() = %v, want %v", got, expected)
+	}
+
+	opts.ValuesFrom = []string{"secret/missing"}
+	if _, err := opts.MergeValues(getter.Providers{}); err == nil || !strings.Contains(err.Error(), "secret/missing:values.yaml not found") {
+		t.Errorf("MergeValues() error = %v, want a missing reference error", err)
+	}
+
+	opts.Reader = nil
+	opts.ValuesFrom = []string{"secret/db"}
+	if _, err := opts.MergeValues(getter.Providers{}); err == nil || !strings.Contains(err.Error(), "can only be read from the cluster") {
+		t.Errorf("MergeValues() error = %v, want an error without a reader", err)
+	}
+}
+
+func TestNormalizeReferences(t *testing.T) {
+	got, err := NormalizeReferences([]string{"secret/db", "secret/db:values.yaml", "configmap/overrides:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"secret/db:values.yaml", "secret/db:values.yaml", "configmap/overrides:values.yaml"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NormalizeReferences() = %v, want %v", got, want)
+	}
+
+	if _, err := NormalizeReferences([]string{"pod/db"}); err == nil {
+		t.Error("expected an error for an invalid reference")
+	}
+}
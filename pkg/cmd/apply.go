@@ -0,0 +1,202 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+const applyDesc = `
+This command installs or upgrades a set of releases declared in a file.
+
+The file lists each release with its chart reference, chart version,
+namespace, values files and the releases it needs:
+
+    name: production
+    releases:
+    - name: database
+      namespace: data
+      chart: oci://registry.example.com/charts/postgresql
+      version: 12.1.0
+      values:
+      - values/database.yaml
+    - name: frontend
+      chart: ./charts/frontend
+      needs:
+      - data/database
+
+Releases are applied in dependency order: a release is only installed or
+upgraded once all the releases it needs succeeded. Independent releases are
+applied concurrently, up to '--concurrency' at a time. Releases that are
+already deployed from the same chart version with the same values, and whose
+manifests and hooks render the same, are left unchanged.
+
+Releases applied from a named set are labeled with its name. With '--prune',
+releases carrying the label that are no longer declared in the file are
+uninstalled.
+
+Use '--dry-run' to show the operations that would be performed.
+`
+
+func newApplyCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewApply(nil, settings)
+	var outfmt output.Format
+	var file string
+
+	cmd := &cobra.Command{
+		Use:               "apply -f FILE",
+		Short:             "install or upgrade a set of releases declared in a file",
+		Long:              applyDesc,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if file == "" {
+				return errors.New("a release set file is required, use '-f FILE'")
+			}
+			set, err := action.LoadReleaseSet(file, settings.Namespace())
+			if err != nil {
+				return err
+			}
+			client.ActionConfig = namespacedActionConfigs(cfg)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cSignal := make(chan os.Signal, 2)
+			signal.Notify(cSignal, os.Interrupt, syscall.SIGTERM)
+			defer signal.Stop(cSignal)
+			go func() {
+				select {
+				case <-cSignal:
+					fmt.Fprintln(out, "Apply has been cancelled.")
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+
+			results, runErr := client.Run(ctx, set)
+			if err := outfmt.Write(out, applyWriter(results)); err != nil {
+				return errors.Join(runErr, err)
+			}
+			return runErr
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&file, "file", "f", "", "the file declaring the releases to apply")
+	f.IntVar(&client.Concurrency, "concurrency", 1, "maximum number of releases applied at the same time")
+	f.BoolVar(&client.Prune, "prune", false, "uninstall releases applied from this set that are no longer declared in the file")
+	f.BoolVar(&client.DryRun, "dry-run", false, "show the operations that would be performed without performing them")
+	f.BoolVar(&client.CreateNamespace, "create-namespace", false, "create the release namespace if not present")
+	f.BoolVar(&client.RollbackOnFailure, "rollback-on-failure", false, "if set, a failed install is uninstalled and a failed upgrade is rolled back. The --wait flag will be set automatically to \"watcher\" if --rollback-on-failure is set")
+	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
+	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
+	AddWaitFlag(cmd, &client.WaitStrategy)
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+// namespacedActionConfigs returns a function providing an action
+// configuration per namespace. The configuration of the current namespace is
+// reused, the others are initialized on first use.
+func namespacedActionConfigs(cfg *action.Configuration) func(string) (*action.Configuration, error) {
+	var mu sync.Mutex
+	configs := map[string]*action.Configuration{settings.Namespace(): cfg}
+	return func(namespace string) (*action.Configuration, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if c, ok := configs[namespace]; ok {
+			return c, nil
+		}
+		c := new(action.Configuration)
+		if err := c.Init(settings.RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER")); err != nil {
+			return nil, err
+		}
+		if kc, ok := c.KubeClient.(*kube.Client); ok {
+			kc.Namespace = namespace
+		}
+		c.RegistryClient = cfg.RegistryClient
+		configs[namespace] = c
+		return c, nil
+	}
+}
+
+type applyWriter []*action.ApplyResult
+
+type applyResultInfo struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Operation string `json:"operation"`
+	Revision  int    `json:"revision,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (w applyWriter) infos() []applyResultInfo {
+	infos := make([]applyResultInfo, 0, len(w))
+	for _, r := range w {
+		info := applyResultInfo{
+			Name:      r.Name,
+			Namespace: r.Namespace,
+			Operation: string(r.Operation),
+		}
+		if r.Release != nil {
+			info.Revision = r.Release.Version
+		}
+		if r.Error != nil {
+			info.Error = r.Error.Error()
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+func (w applyWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.infos())
+}
+
+func (w applyWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.infos())
+}
+
+func (w applyWriter) WriteTable(out io.Writer) error {
+	tbl := uitable.New()
+	tbl.AddRow("NAMESPACE", "NAME", "OPERATION", "REVISION", "ERROR")
+	for _, info := range w.infos() {
+		revision := ""
+		if info.Revision > 0 {
+			revision = fmt.Sprint(info.Revision)
+		}
+		tbl.AddRow(info.Namespace, info.Name, info.Operation, revision, info.Error)
+	}
+	return output.EncodeTable(out, tbl)
+}
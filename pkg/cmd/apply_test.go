@@ -0,0 +1,60 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestApplyCmd(t *testing.T) {
+	deployed := release.Mock(&release.MockReleaseOptions{
+		Name:  "backend",
+		Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "empty", Version: "0.1.0"}},
+	})
+
+	tests := []cmdTestCase{{
+		name:   "apply a release set",
+		cmd:    "apply -f testdata/releases/releases.yaml",
+		golden: "output/apply.txt",
+	}, {
+		name:   "apply a release set with an existing release",
+		cmd:    "apply -f testdata/releases/releases.yaml --dry-run",
+		golden: "output/apply-dry-run.txt",
+		rels:   []*release.Release{deployed},
+	}, {
+		name:   "apply a release set as json",
+		cmd:    "apply -f testdata/releases/releases.yaml --dry-run -o json",
+		golden: "output/apply-dry-run.json",
+	}, {
+		name:      "apply a missing release set",
+		cmd:       "apply -f testdata/releases/missing.yaml",
+		wantError: true,
+	}, {
+		name:      "apply without a file",
+		cmd:       "apply",
+		golden:    "output/apply-no-file.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestApplyFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "apply", false)
+}
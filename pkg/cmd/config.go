@@ -0,0 +1,169 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/cli/config"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/gates"
+)
+
+const configHelp = `
+This command consists of multiple subcommands to work with the Helm
+configuration file.
+
+The configuration file holds defaults for the flags of the Helm commands, the
+feature gates to enable and named profiles, selected with --profile or
+HELM_PROFILE, that layer their own defaults on top. Flags given on the command
+line take precedence over environment variables, which take precedence over the
+configuration file.
+
+Settings are addressed by key:
+
+    flags.<flag>         a global flag, e.g. flags.namespace
+    <command>.<flag>     a flag of a command, e.g. install.timeout or
+                         repo.add.force-update
+    gates.<gate>         a feature gate, e.g. gates.HELM_EXPERIMENTAL_FEATURE
+
+The file is located at $HELM_CONFIG_HOME/config.yaml by default, the
+HELM_CONFIG environment variable overrides its location.
+`
+
+func newConfigCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "manage the defaults of the Helm configuration file",
+		Long:  configHelp,
+		Args:  require.NoArgs,
+	}
+
+	cmd.AddCommand(newConfigGetCmd(out))
+	cmd.AddCommand(newConfigSetCmd(out))
+	cmd.AddCommand(newConfigUnsetCmd(out))
+	cmd.AddCommand(newConfigListCmd(out))
+
+	return cmd
+}
+
+// isConfigCmd reports whether cmd is 'helm config' or one of its subcommands,
+// which have to work even when the configuration is broken.
+func isConfigCmd(cmd *cobra.Command) bool {
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		if c.Name() == "config" && !c.Parent().HasParent() {
+			return true
+		}
+	}
+	return false
+}
+
+// loadUserConfig returns the settings of the configuration file for the
+// selected profile.
+func loadUserConfig() (*config.Settings, error) {
+	f, err := config.LoadFile(settings.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	return f.Resolve(settings.Profile)
+}
+
+// applyGlobalConfig sets the defaults of the global flags that were neither
+// given nor set by an environment variable, and enables the feature gates.
+func applyGlobalConfig(flags *pflag.FlagSet, s *config.Settings) {
+	for name, v := range s.Flags {
+		f := flags.Lookup(name)
+		if f == nil {
+			slog.Warn("unknown global flag in the configuration file", "key", config.Key{Section: config.SectionFlags, Name: name}.String())
+			continue
+		}
+		if f.Changed || cli.FlagSetByEnv(name) {
+			continue
+		}
+		if err := setFlagDefault(f, v); err != nil {
+			slog.Warn("invalid value in the configuration file", "key", config.Key{Section: config.SectionFlags, Name: name}.String(), slog.Any("error", err))
+		}
+	}
+
+	defaults := make(map[gates.Gate]bool, len(s.Gates))
+	for name, enabled := range s.Gates {
+		defaults[gates.Gate(name)] = enabled
+	}
+	gates.SetDefaults(defaults)
+}
+
+// applyCommandConfig sets the defaults of the flags of the commands. The
+// flags given on the command line are parsed afterwards and override them.
+func applyCommandConfig(root *cobra.Command, s *config.Settings) {
+	for path, flags := range s.Commands {
+		cmd, err := findCommand(root, path)
+		if err != nil {
+			slog.Warn("unknown command in the configuration file", "command", path)
+			continue
+		}
+		for name, v := range flags {
+			key := config.Key{Command: path, Name: name}.String()
+			f := cmd.Flags().Lookup(name)
+			if f == nil {
+				slog.Warn("unknown flag in the configuration file", "key", key)
+				continue
+			}
+			if err := setFlagDefault(f, v); err != nil {
+				slog.Warn("invalid value in the configuration file", "key", key, slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// findCommand finds a command by its path without the leading "helm".
+func findCommand(root *cobra.Command, path string) (*cobra.Command, error) {
+	cmd, _, err := root.Find(strings.Fields(path))
+	if err != nil || cmd == root || strings.TrimPrefix(cmd.CommandPath(), root.Name()+" ") != path {
+		return nil, fmt.Errorf("unknown command %q", path)
+	}
+	return cmd, nil
+}
+
+// setFlagDefault sets the value of a flag and makes it its default, without
+// marking the flag as changed.
+func setFlagDefault(f *pflag.Flag, v interface{}) error {
+	values := config.FlagValues(v)
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		// Unlike Set, Replace does not mark the list as changed, so values
+		// given on the command line replace the configured ones instead of
+		// being added to them.
+		if err := sv.Replace(values); err != nil {
+			return err
+		}
+	} else {
+		if len(values) != 1 {
+			return fmt.Errorf("flag %q takes a single value", f.Name)
+		}
+		if err := f.Value.Set(values[0]); err != nil {
+			return err
+		}
+	}
+	f.DefValue = f.Value.String()
+	return nil
+}
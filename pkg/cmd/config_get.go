@@ -0,0 +1,77 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/cli/config"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const configGetHelp = `
+This command prints the value of a setting of the Helm configuration file, as
+it applies to the profile selected with --profile or HELM_PROFILE.
+`
+
+func newConfigGetCmd(out io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get KEY",
+		Short: "print a setting of the Helm configuration file",
+		Long:  configGetHelp,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return noMoreArgsComp()
+			}
+			return compListConfigKeys(), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			key, err := config.ParseKey(args[0])
+			if err != nil {
+				return err
+			}
+			s, err := loadUserConfig()
+			if err != nil {
+				return err
+			}
+			v, ok := s.Get(key)
+			if !ok {
+				return fmt.Errorf("%s is not set", key)
+			}
+			fmt.Fprintln(out, strings.Join(config.FlagValues(v), ","))
+			return nil
+		},
+	}
+}
+
+// compListConfigKeys returns the keys set in the configuration file.
+func compListConfigKeys() []string {
+	s, err := loadUserConfig()
+	if err != nil {
+		return nil
+	}
+	var keys []string
+	for _, e := range s.Entries() {
+		keys = append(keys, e.Key)
+	}
+	return keys
+}
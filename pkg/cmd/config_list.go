@@ -0,0 +1,88 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"io"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/cli/config"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const configListHelp = `
+This command lists the settings of the Helm configuration file that apply to
+the profile selected with --profile or HELM_PROFILE, or to the default profile
+of the file.
+`
+
+func newConfigListCmd(out io.Writer) *cobra.Command {
+	var outfmt output.Format
+	cmd := &cobra.Command{
+		Use:               "list",
+		Aliases:           []string{"ls"},
+		Short:             "list the settings of the Helm configuration file",
+		Long:              configListHelp,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			s, err := loadUserConfig()
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &configListWriter{s.Entries()})
+		},
+	}
+
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+type configListWriter struct {
+	entries []config.Entry
+}
+
+func (w *configListWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("KEY", "VALUE")
+	for _, e := range w.entries {
+		table.AddRow(e.Key, strings.Join(config.FlagValues(e.Value), ","))
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *configListWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.nonNil())
+}
+
+func (w *configListWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.nonNil())
+}
+
+// nonNil returns the entries, initialized so that no settings encode as an
+// empty list instead of null.
+func (w *configListWriter) nonNil() []config.Entry {
+	if w.entries == nil {
+		return []config.Entry{}
+	}
+	return w.entries
+}
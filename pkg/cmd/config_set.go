@@ -0,0 +1,118 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"helm.sh/helm/v4/pkg/cli/config"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const configSetHelp = `
+This command sets a setting of the Helm configuration file. With --profile,
+or HELM_PROFILE, the setting is added to that profile, which is created if
+needed.
+
+The flag or command of the key must exist and the value must be valid for the
+flag:
+
+    $ helm config set install.wait watcher
+    $ helm config set upgrade.timeout 10m --profile ci
+    $ helm config set gates.HELM_EXPERIMENTAL_FEATURE true
+`
+
+func newConfigSetCmd(out io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set KEY VALUE",
+		Short: "set a setting of the Helm configuration file",
+		Long:  configSetHelp,
+		Args:  require.ExactArgs(2),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return noMoreArgsComp()
+			}
+			return compListConfigKeys(), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := config.ParseKey(args[0])
+			if err != nil {
+				return err
+			}
+			if err := validateConfigSetting(cmd.Root(), key, args[1]); err != nil {
+				return err
+			}
+
+			f, err := config.LoadFile(settings.ConfigFile)
+			if err != nil {
+				return err
+			}
+			layer, err := f.Layer(settings.Profile, true)
+			if err != nil {
+				return err
+			}
+			if err := layer.Set(key, args[1]); err != nil {
+				return err
+			}
+			if err := f.WriteFile(settings.ConfigFile, 0600); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%s has been set to %q\n", key, args[1])
+			return nil
+		},
+	}
+}
+
+// validateConfigSetting checks that the flag of a key exists and accepts the
+// value. Gates are checked when set.
+func validateConfigSetting(root *cobra.Command, key config.Key, value string) error {
+	var f *pflag.Flag
+	switch key.Section {
+	case config.SectionGates:
+		return nil
+	case config.SectionFlags:
+		if key.Name == "profile" {
+			return fmt.Errorf("the profile cannot be set as a flag default, set \"profile\" at the top of %s instead", settings.ConfigFile)
+		}
+		f = root.PersistentFlags().Lookup(key.Name)
+		if f == nil {
+			return fmt.Errorf("unknown global flag %q", key.Name)
+		}
+	default:
+		cmd, err := findCommand(root, key.Command)
+		if err != nil {
+			return err
+		}
+		f = cmd.Flags().Lookup(key.Name)
+		if f == nil {
+			if root.PersistentFlags().Lookup(key.Name) != nil {
+				return fmt.Errorf("%q is a global flag, use flags.%s", key.Name, key.Name)
+			}
+			return fmt.Errorf("unknown flag %q for %q", key.Name, cmd.CommandPath())
+		}
+	}
+	// The process ends after this command, so the flag can be set to check
+	// the value.
+	if err := setFlagDefault(f, value); err != nil {
+		return fmt.Errorf("invalid value %q for %s: %w", value, key, err)
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/internal/test"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/gates"
+)
+
+const testUserConfig = `flags:
+  burst-limit: 42
+commands:
+  install:
+    timeout: 10m
+    set:
+    - a=1
+    - b=2
+gates:
+  HELM_EXPERIMENTAL_CONFIG_TEST: true
+profiles:
+  ci:
+    commands:
+      install:
+        timeout: 20m
+`
+
+func writeUserConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if content != "" {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	settings.ConfigFile = path
+	return path
+}
+
+func newTestRootCmd(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	root, err := newRootCmdWithConfig(&action.Configuration{}, new(bytes.Buffer), args, func(bool) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// runConfigCmd runs a command the way a fresh process would, without the
+// profile selected by a previous command.
+func runConfigCmd(cmd string) (string, error) {
+	settings.Profile = ""
+	_, out, err := executeActionCommandC(storageFixture(), cmd)
+	return out, err
+}
+
+func flagValue(t *testing.T, root *cobra.Command, path, name string) string {
+	t.Helper()
+	cmd, err := findCommand(root, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cmd.Flags().Lookup(name).Value.String()
+}
+
+func TestConfigDefaults(t *testing.T) {
+	defer resetEnv()()
+	defer gates.SetDefaults(nil)
+	os.Unsetenv("HELM_BURST_LIMIT")
+	os.Unsetenv("HELM_EXPERIMENTAL_CONFIG_TEST")
+	writeUserConfig(t, testUserConfig)
+
+	root := newTestRootCmd(t)
+	if v := flagValue(t, root, "install", "timeout"); v != "10m0s" {
+		t.Errorf("expected the timeout of the configuration file, got %s", v)
+	}
+	if v := flagValue(t, root, "install", "set"); v != "[a=1,b=2]" {
+		t.Errorf("expected the values of the configuration file, got %s", v)
+	}
+	if v := flagValue(t, root, "upgrade", "timeout"); v != "5m0s" {
+		t.Errorf("expected the default timeout for upgrade, got %s", v)
+	}
+	if settings.BurstLimit != 42 {
+		t.Errorf("expected the burst limit of the configuration file, got %d", settings.BurstLimit)
+	}
+	if !gates.Gate("HELM_EXPERIMENTAL_CONFIG_TEST").IsEnabled() {
+		t.Error("expected the gate of the configuration file to be enabled")
+	}
+
+	// Flags given on the command line replace the defaults.
+	install, _ := findCommand(root, "install")
+	if err := install.ParseFlags([]string{"--timeout", "1m", "--set", "c=3"}); err != nil {
+		t.Fatal(err)
+	}
+	if v := flagValue(t, root, "install", "timeout"); v != "1m0s" {
+		t.Errorf("expected the timeout of the command line, got %s", v)
+	}
+	if v := flagValue(t, root, "install", "set"); v != "[c=3]" {
+		t.Errorf("expected the values of the command line, got %s", v)
+	}
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	defer resetEnv()()
+	defer gates.SetDefaults(nil)
+	writeUserConfig(t, testUserConfig)
+
+	// Environment variables take precedence over the configuration file.
+	t.Setenv("HELM_BURST_LIMIT", "7")
+	t.Setenv("HELM_EXPERIMENTAL_CONFIG_TEST", "")
+	settings.BurstLimit = 7
+	newTestRootCmd(t)
+	if settings.BurstLimit != 7 {
+		t.Errorf("expected the burst limit of the environment, got %d", settings.BurstLimit)
+	}
+	if gates.Gate("HELM_EXPERIMENTAL_CONFIG_TEST").IsEnabled() {
+		t.Error("expected the gate to be disabled by the environment")
+	}
+
+	// As do flags.
+	newTestRootCmd(t, "--burst-limit", "8")
+	if settings.BurstLimit != 8 {
+		t.Errorf("expected the burst limit of the command line, got %d", settings.BurstLimit)
+	}
+
+	// Profiles override the top level defaults.
+	root := newTestRootCmd(t, "--profile", "ci")
+	if v := flagValue(t, root, "install", "timeout"); v != "20m0s" {
+		t.Errorf("expected the timeout of the profile, got %s", v)
+	}
+}
+
+func TestConfigUnknownProfile(t *testing.T) {
+	defer resetEnv()()
+	writeUserConfig(t, testUserConfig)
+
+	_, err := runConfigCmd("version --profile prod")
+	if err == nil || err.Error() != `profile "prod" not found in the configuration file` {
+		t.Errorf("expected an error about the profile, got %v", err)
+	}
+
+	// The profile can be created.
+	out, err := runConfigCmd("config set --profile prod upgrade.atomic true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "upgrade.atomic has been set to \"true\"\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestConfigCmds(t *testing.T) {
+	defer resetEnv()()
+	path := writeUserConfig(t, "")
+
+	for _, cmd := range []string{
+		"config set install.wait watcher",
+		"config set repo.add.force-update true",
+		"config set flags.namespace apps",
+		"config set gates.HELM_EXPERIMENTAL_FEATURE true",
+		"config set --profile ci install.timeout 10m",
+	} {
+		if _, err := runConfigCmd(cmd); err != nil {
+			t.Fatalf("%s: %v", cmd, err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `commands:
+  install:
+    wait: watcher
+  repo add:
+    force-update: true
+flags:
+  namespace: apps
+gates:
+  HELM_EXPERIMENTAL_FEATURE: true
+profiles:
+  ci:
+    commands:
+      install:
+        timeout: 10m
+`
+	if string(data) != expected {
+		t.Errorf("expected configuration file:\n%s\ngot:\n%s", expected, data)
+	}
+
+	for cmd, golden := range map[string]string{
+		"config list":                      "output/config-list.txt",
+		"config list --profile ci -o yaml": "output/config-list-profile.yaml",
+	} {
+		out, err := runConfigCmd(cmd)
+		if err != nil {
+			t.Fatalf("%s: %v", cmd, err)
+		}
+		test.AssertGoldenString(t, out, golden)
+	}
+
+	for cmd, want := range map[string]string{
+		"config get install.wait":                 "watcher\n",
+		"config get --profile ci install.timeout": "10m\n",
+	} {
+		out, err := runConfigCmd(cmd)
+		if err != nil {
+			t.Fatalf("%s: %v", cmd, err)
+		}
+		if out != want {
+			t.Errorf("%s: expected %q, got %q", cmd, want, out)
+		}
+	}
+
+	for cmd, want := range map[string]string{
+		"config get install.timeout":          "install.timeout is not set",
+		"config set install.nope true":        `unknown flag "nope" for "helm install"`,
+		"config set nope.wait true":           `unknown command "nope"`,
+		"config set install.namespace apps":   `"namespace" is a global flag, use flags.namespace`,
+		"config set install.timeout forever":  `invalid value "forever" for install.timeout`,
+		"config set gates.HELM_FEATURE maybe": "must be a boolean",
+		"config set flags.profile ci":         "the profile cannot be set as a flag default",
+		"config unset install.timeout":        "install.timeout is not set",
+	} {
+		_, err := runConfigCmd(cmd)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected an error containing %q, got %v", cmd, want, err)
+		}
+	}
+
+	if _, err := runConfigCmd("config unset install.wait"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runConfigCmd("config get install.wait"); err == nil {
+		t.Error("expected install.wait to be removed")
+	}
+}
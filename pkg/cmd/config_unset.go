@@ -0,0 +1,64 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/cli/config"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+func newConfigUnsetCmd(out io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:   "unset KEY",
+		Short: "remove a setting from the Helm configuration file",
+		Long:  "This command removes a setting from the Helm configuration file, or from the profile selected with --profile or HELM_PROFILE.",
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return noMoreArgsComp()
+			}
+			return compListConfigKeys(), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			key, err := config.ParseKey(args[0])
+			if err != nil {
+				return err
+			}
+			f, err := config.LoadFile(settings.ConfigFile)
+			if err != nil {
+				return err
+			}
+			layer, err := f.Layer(settings.Profile, false)
+			if err != nil {
+				return err
+			}
+			if !layer.Unset(key) {
+				return fmt.Errorf("%s is not set", key)
+			}
+			if err := f.WriteFile(settings.ConfigFile, 0600); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%s has been removed\n", key)
+			return nil
+		},
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"log"
 	"log/slog"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
 
@@ -55,6 +56,43 @@ func addValueOptionsFlags(f *pflag.FlagSet, v *values.Options) {
 	f.StringArrayVar(&v.LiteralValues, "set-literal", []string{}, "set a literal STRING value on the command line")
 }
 
+// addValuesFromFlag adds --values-from. The values are read from the cluster,
+// so it is only added to the commands installing or upgrading a release.
+func addValuesFromFlag(f *pflag.FlagSet, v *values.Options) {
+	f.StringArrayVar(&v.ValuesFrom, "values-from", []string{}, "read values from a Secret or ConfigMap in the release namespace, given as secret/NAME[:KEY] or configmap/NAME[:KEY] where KEY defaults to values.yaml (can specify multiple)")
+}
+
+// addLookupFixturesFlag adds --lookup-fixtures, serving the 'lookup' template
+// function from the objects of a directory instead of the cluster.
+func addLookupFixturesFlag(f *pflag.FlagSet, dir *string) {
+	f.StringVar(dir, "lookup-fixtures", "", "serve the 'lookup' template function from the Kubernetes objects of the YAML and JSON files in the given directory")
+}
+
+// addRenderConcurrencyFlag adds --render-concurrency. Rendering templates
+// concurrently is opt-in: each template then gets its own copy of the values,
+// so the changes a template makes to .Values, e.g. with 'set', are not seen by
+// the others as they are when rendering one after the other, like install and
+// upgrade do.
+func addRenderConcurrencyFlag(f *pflag.FlagSet, n *int) {
+	f.IntVar(n, "render-concurrency", 1, "maximum number of templates rendered at the same time, 0 for the number of CPUs. Templates rendered concurrently do not see the changes other templates make to .Values")
+}
+
+// addSourceMapFlag adds --source-map. Mapping the rendered manifests back to
+// the template lines is opt-in for install and upgrade, which otherwise do not
+// keep track of the lines every template writes.
+func addSourceMapFlag(f *pflag.FlagSet, sourceMap *bool) {
+	f.BoolVar(sourceMap, "source-map", false, "point the errors building the rendered manifests, or returned by the Kubernetes API for them, at the template lines they were rendered from")
+}
+
+// renderConcurrency returns the number of templates to render at the same
+// time for the value of --render-concurrency.
+func renderConcurrency(n int) int {
+	if n <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return n
+}
+
 func AddWaitFlag(cmd *cobra.Command, wait *kube.WaitStrategy) {
 	cmd.Flags().Var(
 		newWaitValue(kube.HookOnlyStrategy, wait),
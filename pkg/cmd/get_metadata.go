@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"strings"
 
 	"github.com/spf13/cobra"
 	k8sLabels "k8s.io/apimachinery/pkg/labels"
@@ -103,6 +104,12 @@ func (w metadataWriter) WriteTable(out io.Writer) error {
 	_, _ = fmt.Fprintf(out, "STATUS: %v\n", w.metadata.Status)
 	_, _ = fmt.Fprintf(out, "DEPLOYED_AT: %v\n", w.metadata.DeployedAt)
 	_, _ = fmt.Fprintf(out, "APPLY_METHOD: %v\n", formatApplyMethod(w.metadata.ApplyMethod))
+	if w.metadata.ChartAPIVersion != "" {
+		_, _ = fmt.Fprintf(out, "CHART_API_VERSION: %v\n", w.metadata.ChartAPIVersion)
+	}
+	if len(w.metadata.ValuesFrom) > 0 {
+		_, _ = fmt.Fprintf(out, "VALUES_FROM: %v\n", strings.Join(w.metadata.ValuesFrom, ","))
+	}
 
 	return nil
 }
@@ -21,15 +21,20 @@ import (
 	"io"
 	"log"
 
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/pkg/action"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cmd/require"
 )
 
 var getValuesHelp = `
 This command downloads a values file for a given release.
+
+With '--explain', it lists every computed value along with the sources that
+set it, from the lowest to the highest precedence. The last source wins.
 `
 
 type valuesWriter struct {
@@ -39,6 +44,7 @@ type valuesWriter struct {
 
 func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	var outfmt output.Format
+	var explain bool
 	client := action.NewGetValues(cfg)
 
 	cmd := &cobra.Command{
@@ -53,6 +59,13 @@ func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			return compListReleases(toComplete, args, cfg)
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
+			if explain {
+				explanations, err := client.Explain(args[0])
+				if err != nil {
+					return err
+				}
+				return outfmt.Write(out, &valuesExplanationWriter{explanations})
+			}
 			vals, err := client.Run(args[0])
 			if err != nil {
 				return err
@@ -75,6 +88,7 @@ func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	}
 
 	f.BoolVarP(&client.AllValues, "all", "a", false, "dump all (computed) values")
+	f.BoolVar(&explain, "explain", false, "show where each computed value comes from")
 	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
@@ -96,3 +110,38 @@ func (v valuesWriter) WriteJSON(out io.Writer) error {
 func (v valuesWriter) WriteYAML(out io.Writer) error {
 	return output.EncodeYAML(out, v.vals)
 }
+
+// valuesExplanationWriter writes where each computed value comes from.
+type valuesExplanationWriter struct {
+	explanations []chartutil.ValueExplanation
+}
+
+func (v valuesExplanationWriter) WriteTable(out io.Writer) error {
+	for _, e := range v.explanations {
+		fmt.Fprintf(out, "%s: %s\n", e.Path, formatValue(e.Value))
+		tbl := uitable.New()
+		for _, o := range e.Origins {
+			source := o.Source
+			if o.Global != "" {
+				source = fmt.Sprintf("%s (via %s)", source, o.Global)
+			}
+			location := o.File
+			if o.Line > 0 {
+				location = fmt.Sprintf("%s:%d", o.File, o.Line)
+			}
+			tbl.AddRow("  "+source, location, formatValue(o.Value))
+		}
+		if len(e.Origins) > 0 {
+			fmt.Fprintln(out, tbl)
+		}
+	}
+	return nil
+}
+
+func (v valuesExplanationWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, v.explanations)
+}
+
+func (v valuesExplanationWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, v.explanations)
+}
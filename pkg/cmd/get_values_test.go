@@ -39,6 +39,16 @@ func TestGetValuesCmd(t *testing.T) {
 		cmd:    "get values thomas-guide --all",
 		golden: "output/get-values-all.txt",
 		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+	}, {
+		name:   "get values thomas-guide (explain)",
+		cmd:    "get values thomas-guide --explain",
+		golden: "output/get-values-explain.txt",
+		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+	}, {
+		name:   "get values thomas-guide (explain) to json",
+		cmd:    "get values thomas-guide --explain --output json",
+		golden: "output/get-values-explain.json",
+		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
 	}, {
 		name:   "get values to json",
 		cmd:    "get values thomas-guide --output json",
@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
@@ -48,25 +49,59 @@ The historical release set is printed as a formatted table, e.g:
     2           Mon Oct 3 10:15:13 2016     superseded      alpine-0.1.0      1.0             Upgraded successfully
     3           Mon Oct 3 10:15:13 2016     superseded      alpine-0.1.0      1.0             Rolled back to 2
     4           Mon Oct 3 10:15:13 2016     deployed        alpine-0.1.0      1.0             Upgraded successfully
+
+With '--diff', the release name is followed by two revisions, REV1 and REV2,
+which are compared instead. The chart version, the user-supplied values, the
+computed values and the resources of the manifest are shown side by side.
+Resources are matched by their kind, namespace and name, so reordering the
+templates does not show as a change:
+
+    $ helm history RELEASE_NAME REV1 REV2 --diff
+    $ helm history angry-bird 2 4 --diff
 `
 
 func newHistoryCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewHistory(cfg)
 	var outfmt output.Format
+	var diff bool
 
 	cmd := &cobra.Command{
-		Use:     "history RELEASE_NAME",
+		Use:     "history RELEASE_NAME [REV1 REV2]",
 		Long:    historyHelp,
 		Short:   "fetch release history",
 		Aliases: []string{"hist"},
-		Args:    require.ExactArgs(1),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if diff {
+				return require.ExactArgs(3)(cmd, args)
+			}
+			return require.ExactArgs(1)(cmd, args)
+		},
 		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-			if len(args) != 0 {
-				return noMoreArgsComp()
+			if len(args) == 0 {
+				return compListReleases(toComplete, args, cfg)
 			}
-			return compListReleases(toComplete, args, cfg)
+			if diff && len(args) < 3 {
+				return compListRevisions(toComplete, cfg, args[0])
+			}
+			return noMoreArgsComp()
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
+			if diff {
+				from, err := strconv.Atoi(args[1])
+				if err != nil {
+					return fmt.Errorf("could not convert revision to a number: %v", err)
+				}
+				to, err := strconv.Atoi(args[2])
+				if err != nil {
+					return fmt.Errorf("could not convert revision to a number: %v", err)
+				}
+				d, err := action.NewHistoryDiff(cfg).Run(args[0], from, to)
+				if err != nil {
+					return err
+				}
+				return outfmt.Write(out, &revisionDiffWriter{d})
+			}
+
 			history, err := getHistory(client, args[0])
 			if err != nil {
 				return err
@@ -78,6 +113,7 @@ func newHistoryCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 	f := cmd.Flags()
 	f.IntVar(&client.Max, "max", 256, "maximum number of revision to include in history")
+	f.BoolVar(&diff, "diff", false, "compare two revisions of the release, given as additional arguments: RELEASE_NAME REV1 REV2")
 	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
@@ -159,6 +195,78 @@ func getReleaseHistory(rls []*release.Release) (history releaseHistory) {
 	return history
 }
 
+type revisionDiffWriter struct {
+	diff *action.RevisionDiff
+}
+
+func (w *revisionDiffWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.diff)
+}
+
+func (w *revisionDiffWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.diff)
+}
+
+func (w *revisionDiffWriter) WriteTable(out io.Writer) error {
+	d := w.diff
+	fmt.Fprintf(out, "RELEASE: %s\nREVISIONS: %d -> %d\n", d.Release, d.From, d.To)
+	if d.FromChart == d.ToChart {
+		fmt.Fprintf(out, "CHART: %s\n", d.ToChart)
+	} else {
+		fmt.Fprintf(out, "CHART: %s -> %s\n", d.FromChart, d.ToChart)
+	}
+
+	writeValueChanges(out, "USER-SUPPLIED VALUES", d.UserValues)
+	writeValueChanges(out, "COMPUTED VALUES", d.ComputedValues)
+
+	fmt.Fprintln(out, "\nMANIFEST:")
+	if len(d.Resources) == 0 {
+		fmt.Fprintln(out, "  no changes")
+	}
+	for _, r := range d.Resources {
+		fmt.Fprintf(out, "%s %s\n", changeMarker(r.Change), r.Resource)
+		fmt.Fprint(out, r.Diff)
+	}
+	return nil
+}
+
+func writeValueChanges(out io.Writer, title string, changes []action.ValueChange) {
+	fmt.Fprintf(out, "\n%s:\n", title)
+	if len(changes) == 0 {
+		fmt.Fprintln(out, "  no changes")
+	}
+	for _, c := range changes {
+		switch c.Change {
+		case action.ChangeAdded:
+			fmt.Fprintf(out, "%s %s: %s\n", changeMarker(c.Change), c.Path, formatValue(c.To))
+		case action.ChangeRemoved:
+			fmt.Fprintf(out, "%s %s: %s\n", changeMarker(c.Change), c.Path, formatValue(c.From))
+		default:
+			fmt.Fprintf(out, "%s %s: %s -> %s\n", changeMarker(c.Change), c.Path, formatValue(c.From), formatValue(c.To))
+		}
+	}
+}
+
+func changeMarker(c action.ChangeType) string {
+	switch c {
+	case action.ChangeAdded:
+		return "+"
+	case action.ChangeRemoved:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// formatValue renders a value on a single line as JSON.
+func formatValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
 func formatChartName(c *chart.Chart) string {
 	if c == nil || c.Metadata == nil {
 		// This is an edge case that has happened in prod, though we don't
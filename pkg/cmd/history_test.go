@@ -70,6 +70,50 @@ func TestHistoryCmd(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestHistoryDiffCmd(t *testing.T) {
+	rel1 := release.Mock(&release.MockReleaseOptions{Name: "angry-bird", Version: 1, Status: release.StatusSuperseded})
+	rel2 := release.Mock(&release.MockReleaseOptions{Name: "angry-bird", Version: 2})
+	rel2.Config = map[string]interface{}{"name": "other", "replicas": 3}
+	rel2.Manifest = release.MockManifest + `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: fixture
+data:
+  key: value
+`
+	rels := []*release.Release{rel2, rel1}
+
+	tests := []cmdTestCase{{
+		name:   "diff two revisions",
+		cmd:    "history angry-bird --diff 1 2",
+		rels:   rels,
+		golden: "output/history-diff.txt",
+	}, {
+		name:   "diff two revisions with json output format",
+		cmd:    "history angry-bird --diff 1 2 --output json",
+		rels:   rels,
+		golden: "output/history-diff.json",
+	}, {
+		name:   "diff a revision with itself",
+		cmd:    "history angry-bird --diff 2 2",
+		rels:   rels,
+		golden: "output/history-diff-none.txt",
+	}, {
+		name:      "diff a missing revision",
+		cmd:       "history angry-bird --diff 1 3",
+		rels:      rels,
+		wantError: true,
+	}, {
+		name:      "diff with a single revision",
+		cmd:       "history angry-bird --diff 1",
+		rels:      rels,
+		golden:    "output/history-diff-args.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
 func TestHistoryOutputCompletion(t *testing.T) {
 	outputFlagCompletionTest(t, "history")
 }
@@ -0,0 +1,156 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"io"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	release "helm.sh/helm/v4/pkg/release/v1"
+	helmtime "helm.sh/helm/v4/pkg/time"
+)
+
+const hookRunHelp = `
+This command re-runs the hooks of a deployed release for a given event.
+
+The hooks are taken from the deployed revision of the release and executed the
+same way they are during install, upgrade, rollback or uninstall: delete
+policies and output log policies are honored, and the result of each run is
+recorded in the release.
+
+By default all hooks for the event are run. Use '--name' to select individual
+hooks:
+
+    $ helm hook run my-release --event post-install --name db-migrate
+`
+
+func newHookRunCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewHookRun(cfg)
+	var outfmt output.Format
+	var event string
+
+	cmd := &cobra.Command{
+		Use:   "run RELEASE_NAME",
+		Short: "run the hooks of a release for an event",
+		Long:  hookRunHelp,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return noMoreArgsComp()
+			}
+			return compListReleases(toComplete, args, cfg)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			if event == "" {
+				return errors.New("--event is required to select the hooks to run")
+			}
+			client.Event = release.HookEvent(event)
+			rel, runErr := client.Run(args[0])
+			// Like 'helm test', only bail out early if the release could
+			// not be loaded, so the result of the hooks that did run is shown.
+			if rel == nil {
+				return runErr
+			}
+			if err := outfmt.Write(out, newHookRunWriter(rel, client)); err != nil {
+				return err
+			}
+			return runErr
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&event, "event", "", "the hook event to run hooks for (e.g. post-install)")
+	f.StringSliceVar(&client.Names, "name", []string{}, "only run the hooks with the given names (can specify multiple or separate values with commas: name1,name2)")
+	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
+	AddWaitFlag(cmd, &client.WaitStrategy)
+	bindOutputFlag(cmd, &outfmt)
+
+	err := cmd.RegisterFlagCompletionFunc("event", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		var events []string
+		for _, e := range action.HookEvents {
+			events = append(events, e.String())
+		}
+		return events, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return cmd
+}
+
+type hookRunInfo struct {
+	Name        string            `json:"name"`
+	Kind        string            `json:"kind"`
+	Path        string            `json:"path"`
+	Phase       release.HookPhase `json:"phase"`
+	StartedAt   helmtime.Time     `json:"started_at,omitempty"`
+	CompletedAt helmtime.Time     `json:"completed_at,omitempty"`
+}
+
+type hookRunWriter []hookRunInfo
+
+func newHookRunWriter(rel *release.Release, client *action.HookRun) hookRunWriter {
+	w := hookRunWriter{}
+	for _, h := range rel.Hooks {
+		if !slices.Contains(h.Events, client.Event) {
+			continue
+		}
+		if len(client.Names) > 0 && !slices.Contains(client.Names, h.Name) {
+			continue
+		}
+		// Skip hooks that were not reached, e.g. after an earlier hook failed
+		if h.LastRun.StartedAt.IsZero() {
+			continue
+		}
+		w = append(w, hookRunInfo{
+			Name:        h.Name,
+			Kind:        h.Kind,
+			Path:        h.Path,
+			Phase:       h.LastRun.Phase,
+			StartedAt:   h.LastRun.StartedAt,
+			CompletedAt: h.LastRun.CompletedAt,
+		})
+	}
+	return w
+}
+
+func (w hookRunWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w)
+}
+
+func (w hookRunWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w)
+}
+
+func (w hookRunWriter) WriteTable(out io.Writer) error {
+	tbl := uitable.New()
+	tbl.AddRow("NAME", "KIND", "PHASE", "STARTED", "COMPLETED")
+	for _, h := range w {
+		tbl.AddRow(h.Name, h.Kind, h.Phase, h.StartedAt.Format(time.ANSIC), h.CompletedAt.Format(time.ANSIC))
+	}
+	return output.EncodeTable(out, tbl)
+}
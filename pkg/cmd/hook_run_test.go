@@ -0,0 +1,68 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestHookRunCmd(t *testing.T) {
+	rels := []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "aeneas"})}
+
+	tests := []cmdTestCase{{
+		name: "run hooks for an event",
+		cmd:  "hook run aeneas --event pre-install",
+		rels: rels,
+	}, {
+		name: "run a hook by name",
+		cmd:  "hook run aeneas --event pre-install --name pre-install-hook",
+		rels: rels,
+	}, {
+		name:      "run a hook that does not exist",
+		cmd:       "hook run aeneas --event pre-install --name nope",
+		rels:      rels,
+		wantError: true,
+	}, {
+		name:      "run hooks for an event without hooks",
+		cmd:       "hook run aeneas --event post-upgrade",
+		rels:      rels,
+		wantError: true,
+	}, {
+		name:      "run hooks without event",
+		cmd:       "hook run aeneas",
+		golden:    "output/hook-run-no-event.txt",
+		rels:      rels,
+		wantError: true,
+	}, {
+		name:      "run hooks without args",
+		cmd:       "hook run",
+		golden:    "output/hook-run-no-args.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestHookRunCompletion(t *testing.T) {
+	checkReleaseCompletion(t, "hook run", false)
+}
+
+func TestHookRunFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "hook run", false)
+	checkFileCompletion(t, "hook run myrelease", false)
+}
@@ -33,8 +33,8 @@ import (
 	"github.com/spf13/pflag"
 
 	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chart/loader"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
-	"helm.sh/helm/v4/pkg/chart/v2/loader"
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/cmd/require"
@@ -98,6 +98,21 @@ And in the following example, 'foo' is set to '{"key1":"value1","key2":"bar"}':
 
     $ helm install --set-json='foo={"key1":"value1","key2":"value2"}' --set-json='foo.key2="bar"' myredis ./redis
 
+Values kept in a Secret or ConfigMap in the namespace of the release can be
+read with '--values-from'. They are merged after the '--values' files and
+before the '--set' flags. The release records the references, not only the
+values read:
+
+    $ helm install --values-from secret/redis-prod:values.yaml myredis ./redis
+
+Values files encrypted with SOPS, or encrypted with age as a whole, are
+decrypted in memory and never written to disk. The keys are found the way
+sops finds them: the age identities of SOPS_AGE_KEY, SOPS_AGE_KEY_FILE or
+sops/age/keys.txt in the user configuration directory, the PGP keyring, or a
+cloud KMS:
+
+    $ SOPS_AGE_KEY_FILE=~/keys.txt helm install -f secrets.enc.yaml myredis ./redis
+
 To check the generated manifests of a release without installing the chart,
 the --debug and --dry-run flags can be combined.
 
@@ -135,6 +150,7 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewInstall(cfg)
 	valueOpts := &values.Options{}
 	var outfmt output.Format
+	var sourceMap bool
 
 	cmd := &cobra.Command{
 		Use:   "install [NAME] [CHART]",
@@ -158,6 +174,11 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if client.DryRunOption == "" {
 				client.DryRunOption = "none"
 			}
+			valueOpts.Reader = action.NewClusterValuesReader(cfg, settings.Namespace())
+			cfg.RenderSourceMap = sourceMap
+			defer func() {
+				cfg.RenderSourceMap = false
+			}()
 			rel, err := runInstall(args, client, valueOpts, out)
 			if err != nil {
 				return fmt.Errorf("INSTALLATION FAILED: %w", err)
@@ -178,6 +199,8 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	// it is added separately
 	f := cmd.Flags()
 	f.BoolVar(&client.HideSecret, "hide-secret", false, "hide Kubernetes Secrets when also using the --dry-run flag")
+	addValuesFromFlag(f, valueOpts)
+	addSourceMapFlag(f, &sourceMap)
 	bindOutputFlag(cmd, &outfmt)
 	bindPostRenderFlag(cmd, &client.PostRenderer, settings)
 
@@ -263,6 +286,9 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 	if err != nil {
 		return nil, err
 	}
+	if client.ValuesFrom, err = values.NormalizeReferences(valueOpts.ValuesFrom); err != nil {
+		return nil, err
+	}
 
 	// Check chart dependencies to make sure all are present in /charts
 	chartRequested, err := loader.Load(cp)
@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"helm.sh/helm/v4/pkg/gates"
 	"helm.sh/helm/v4/pkg/repo/repotest"
 )
 
@@ -279,6 +280,15 @@ func TestInstall(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestInstallChartV3(t *testing.T) {
+	t.Setenv(string(gates.ChartV3), "1")
+	runTestCmd(t, []cmdTestCase{{
+		name:   "install chart with apiVersion v3",
+		cmd:    "install albatross testdata/testcharts/chart-v3 --namespace default",
+		golden: "output/install-chart-v3.txt",
+	}})
+}
+
 func TestInstallOutputCompletion(t *testing.T) {
 	outputFlagCompletionTest(t, "install")
 }
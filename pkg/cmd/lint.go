@@ -29,6 +29,7 @@ import (
 	"helm.sh/helm/v4/pkg/action"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/engine"
 	"helm.sh/helm/v4/pkg/getter"
 	"helm.sh/helm/v4/pkg/lint/support"
 )
@@ -40,12 +41,79 @@ the chart is well-formed.
 If the linter encounters things that will cause the chart to fail installation,
 it will emit [ERROR] messages. If it encounters issues that break with convention
 or recommendation, it will emit [WARNING] messages.
+
+Installed plugins of type 'lint/v1' are run as well. They receive the chart
+metadata and the rendered templates, and return messages with a severity.
+
+With '--output json' or '--output sarif', the messages are printed with the
+identifier of the rule that raised them, their severity, chart, file and, when
+known, line and column. SARIF is understood by code scanning tools.
+
+With '--workload-rules', the rendered resources are checked for common risks:
+privileged containers, host paths, host network and PID namespaces, containers
+which may run as root, missing resource requests and limits, images using the
+latest tag or no tag, missing liveness and readiness probes, and roles granting
+access with wildcards. A resource skips the rules listed in its
+'helm.sh/lint-ignore' annotation, e.g.:
+
+	metadata:
+	  annotations:
+	    helm.sh/lint-ignore: host-network,run-as-root
+
+A '.helmlint.yaml' file in the chart directory, or the file given with
+'--config', configures the linting of the chart. It enables and disables
+rules, changes their severity, lists the values files and Kubernetes versions
+the chart is linted against in every combination, and ignores the messages
+about some files:
+
+	rules:
+	  icon-missing:
+	    severity: error
+	  template-indent:
+	    enabled: false
+	  image-tag:
+	    enabled: true
+	matrix:
+	  values:
+	    - ci/small-values.yaml
+	    - ci/large-values.yaml
+	  kubeVersions:
+	    - 1.29.0
+	    - 1.33.0
+	ignore:
+	  - templates/legacy/
+
+The '--kube-version' flag replaces the Kubernetes versions of the matrix. The
+file is not packaged with the chart.
+
+With '--fix', the Chart.yaml and values.yaml files of charts in directories
+are rewritten in place to fix the findings which are mechanical: a version or
+appVersion which is not a string, a missing apiVersion or one spelled
+differently, e.g. 'V2', an icon under a misspelled key, and values of another
+type than the one the values schema wants, e.g. a number where a string is
+wanted. Comments and ordering are kept. The changes are printed as [FIXED]
+messages, and the findings which cannot be fixed safely, e.g. an unknown
+apiVersion, are reported as usual.
+
+With '--validate-schemas', the rendered resources are validated offline
+against the OpenAPI schemas of their kinds for the '--kube-version', and the
+invalid fields are reported with the 'manifest-schema' rule. The schemas of
+the built-in kinds are bundled with Helm, for the Kubernetes version it is
+built with; '--openapi-spec' replaces them with an OpenAPI spec, or the spec of
+the Kubernetes version in a directory of specs, e.g. 'v1.30.json'. Linting
+for another Kubernetes version, with '--kube-version' or the matrix, requires
+'--openapi-spec'. Custom resources are validated against the CRDs of the
+chart and the ones given with '--crd'.
 `
 
 func newLintCmd(out io.Writer) *cobra.Command {
 	client := action.NewLint()
 	valueOpts := &values.Options{}
 	var kubeVersion string
+	var lookupFixtures string
+	var concurrency int
+	var outfmt lintFormat
+	var schemaOpts manifestSchemaOptions
 
 	cmd := &cobra.Command{
 		Use:   "lint PATH",
@@ -65,6 +133,19 @@ func newLintCmd(out io.Writer) *cobra.Command {
 				client.KubeVersion = parsedKubeVersion
 			}
 
+			if lookupFixtures != "" {
+				provider, err := engine.NewFixtureClientProvider(lookupFixtures)
+				if err != nil {
+					return err
+				}
+				client.LookupProvider = provider
+			}
+			client.PluginDirs = filepath.SplitList(settings.PluginsDirectory)
+			client.ManifestSchemas = schemaOpts.enabled()
+			client.OpenAPISpec = schemaOpts.openAPISpec
+			client.CRDFiles = schemaOpts.crdFiles
+			client.RenderConcurrency = renderConcurrency(concurrency)
+
 			if client.WithSubcharts {
 				for _, p := range paths {
 					filepath.Walk(filepath.Join(p, "charts"), func(path string, info os.FileInfo, _ error) error {
@@ -89,9 +170,11 @@ func newLintCmd(out io.Writer) *cobra.Command {
 			var message strings.Builder
 			failed := 0
 			errorsOrWarnings := 0
+			var results []lintChartResult
 
 			for _, path := range paths {
 				result := client.Run([]string{path}, vals)
+				results = append(results, lintChartResult{chart: path, result: result})
 
 				// If there is no errors/warnings and quiet flag is set
 				// go to the next chart
@@ -99,12 +182,16 @@ func newLintCmd(out io.Writer) *cobra.Command {
 				if hasWarningsOrErrors {
 					errorsOrWarnings++
 				}
-				if client.Quiet && !hasWarningsOrErrors {
+				if client.Quiet && !hasWarningsOrErrors && len(result.Fixes) == 0 {
 					continue
 				}
 
 				fmt.Fprintf(&message, "==> Linting %s\n", path)
 
+				for _, fix := range result.Fixes {
+					fmt.Fprintf(&message, "%s\n", fix)
+				}
+
 				// All the Errors that are generated by a chart
 				// that failed a lint will be included in the
 				// results.Messages so we only need to print
@@ -131,9 +218,23 @@ func newLintCmd(out io.Writer) *cobra.Command {
 				fmt.Fprint(&message, "\n")
 			}
 
+			summary := fmt.Sprintf("%d chart(s) linted, %d chart(s) failed", len(paths), failed)
+			if outfmt != lintFormatText {
+				write := writeLintJSON
+				if outfmt == lintFormatSARIF {
+					write = writeLintSARIF
+				}
+				if err := write(out, results, client.Quiet); err != nil {
+					return err
+				}
+				if failed > 0 {
+					return errors.New(summary)
+				}
+				return nil
+			}
+
 			fmt.Fprint(out, message.String())
 
-			summary := fmt.Sprintf("%d chart(s) linted, %d chart(s) failed", len(paths), failed)
 			if failed > 0 {
 				return errors.New(summary)
 			}
@@ -150,7 +251,14 @@ func newLintCmd(out io.Writer) *cobra.Command {
 	f.BoolVar(&client.Quiet, "quiet", false, "print only warnings and errors")
 	f.BoolVar(&client.SkipSchemaValidation, "skip-schema-validation", false, "if set, disables JSON schema validation")
 	f.StringVar(&kubeVersion, "kube-version", "", "Kubernetes version used for capabilities and deprecation checks")
+	f.BoolVar(&client.Fix, "fix", false, "rewrite Chart.yaml and values.yaml in place to fix the mechanical findings before linting")
+	f.StringVar(&client.ConfigFile, "config", "", "lint configuration file applied to the charts, instead of their .helmlint.yaml file")
+	addManifestSchemaFlags(f, &schemaOpts)
+	f.BoolVar(&client.WorkloadRules, "workload-rules", false, "check the rendered workloads for common risks, e.g. privileged containers or images using the latest tag")
+	addLookupFixturesFlag(f, &lookupFixtures)
+	addRenderConcurrencyFlag(f, &concurrency)
 	addValueOptionsFlags(f, valueOpts)
+	bindLintOutputFlag(cmd, &outfmt)
 
 	return cmd
 }
@@ -0,0 +1,287 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/lint/rules"
+	"helm.sh/helm/v4/pkg/lint/support"
+)
+
+// lintFormat is the format 'helm lint' prints its results in.
+type lintFormat string
+
+const (
+	lintFormatText  lintFormat = "text"
+	lintFormatJSON  lintFormat = "json"
+	lintFormatSARIF lintFormat = "sarif"
+)
+
+var lintFormats = map[lintFormat]string{
+	lintFormatText:  "Output the messages in human-readable format",
+	lintFormatJSON:  "Output the messages in JSON format",
+	lintFormatSARIF: "Output the messages in SARIF format, for code scanning tools",
+}
+
+func (f *lintFormat) String() string {
+	return string(*f)
+}
+
+func (f *lintFormat) Type() string {
+	return "format"
+}
+
+func (f *lintFormat) Set(s string) error {
+	if _, ok := lintFormats[lintFormat(s)]; !ok {
+		return fmt.Errorf("invalid format type %q: must be one of text, json or sarif", s)
+	}
+	*f = lintFormat(s)
+	return nil
+}
+
+// bindLintOutputFlag adds the output flag of 'helm lint' to the command.
+func bindLintOutputFlag(cmd *cobra.Command, varRef *lintFormat) {
+	*varRef = lintFormatText
+	cmd.Flags().VarP(varRef, outputFlag, "o", "prints the output in the specified format. Allowed values: text, json, sarif")
+
+	err := cmd.RegisterFlagCompletionFunc(outputFlag, func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		var formatNames []string
+		for format, desc := range lintFormats {
+			formatNames = append(formatNames, fmt.Sprintf("%s\t%s", format, desc))
+		}
+		slices.Sort(formatNames)
+		return formatNames, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// lintChartResult is the result of linting one of the charts.
+type lintChartResult struct {
+	chart  string
+	result *action.LintResult
+}
+
+// lintFinding is a lint message, as printed in JSON.
+type lintFinding struct {
+	RuleID   string `json:"ruleId,omitempty"`
+	Severity string `json:"severity"`
+	Chart    string `json:"chart"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
+}
+
+// lintFix is a change made to a chart when fixing it, as printed in JSON.
+type lintFix struct {
+	RuleID      string `json:"ruleId"`
+	Chart       string `json:"chart"`
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	Description string `json:"description"`
+}
+
+type lintReport struct {
+	Fixes        []lintFix     `json:"fixes,omitempty"`
+	Messages     []lintFinding `json:"messages"`
+	ChartsLinted int           `json:"chartsLinted"`
+	ChartsFailed int           `json:"chartsFailed"`
+}
+
+// lintFindings returns the messages of the results. Charts that could not be
+// linted at all have a single chart-load error. Info messages are left out
+// when quiet.
+func lintFindings(results []lintChartResult, quiet bool) []lintFinding {
+	findings := []lintFinding{}
+	for _, r := range results {
+		if len(r.result.Messages) == 0 {
+			for _, err := range r.result.Errors {
+				findings = append(findings, lintFinding{
+					RuleID:   rules.RuleChartLoad,
+					Severity: support.SeverityName(support.ErrorSev),
+					Chart:    r.chart,
+					Message:  err.Error(),
+				})
+			}
+		}
+		for _, msg := range r.result.Messages {
+			if quiet && msg.Severity <= support.InfoSev {
+				continue
+			}
+			file, line, column := msg.Location()
+			// Messages about the whole chart carry the absolute path of its
+			// directory.
+			if filepath.IsAbs(file) {
+				file = ""
+			}
+			findings = append(findings, lintFinding{
+				RuleID:   msg.RuleID,
+				Severity: support.SeverityName(msg.Severity),
+				Chart:    r.chart,
+				File:     file,
+				Line:     line,
+				Column:   column,
+				Message:  msg.Err.Error(),
+			})
+		}
+	}
+	return findings
+}
+
+func writeLintJSON(out io.Writer, results []lintChartResult, quiet bool) error {
+	report := lintReport{Messages: lintFindings(results, quiet), ChartsLinted: len(results)}
+	for _, r := range results {
+		if len(r.result.Errors) > 0 {
+			report.ChartsFailed++
+		}
+		for _, fix := range r.result.Fixes {
+			file, line, _ := support.Message{Path: fix.Path}.Location()
+			report.Fixes = append(report.Fixes, lintFix{
+				RuleID:      fix.RuleID,
+				Chart:       r.chart,
+				File:        file,
+				Line:        line,
+				Description: fix.Description,
+			})
+		}
+	}
+	return output.EncodeJSON(out, report)
+}
+
+// The subset of SARIF 2.1.0 written by 'helm lint', see
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string        `json:"id"`
+	ShortDescription *sarifMessage `json:"shortDescription,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	RuleIndex *int            `json:"ruleIndex,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifLevels are the SARIF levels of the severities.
+var sarifLevels = map[string]string{
+	"info":    "note",
+	"warning": "warning",
+	"error":   "error",
+}
+
+func writeLintSARIF(out io.Writer, results []lintChartResult, quiet bool) error {
+	findings := lintFindings(results, quiet)
+
+	var ids []string
+	for _, f := range findings {
+		if f.RuleID != "" && !slices.Contains(ids, f.RuleID) {
+			ids = append(ids, f.RuleID)
+		}
+	}
+	slices.Sort(ids)
+	driver := sarifDriver{Name: "helm lint", InformationURI: "https://helm.sh", Rules: []sarifRule{}}
+	for _, id := range ids {
+		rule := sarifRule{ID: id}
+		if desc, ok := rules.RuleDescriptions[id]; ok {
+			rule.ShortDescription = &sarifMessage{Text: desc}
+		}
+		driver.Rules = append(driver.Rules, rule)
+	}
+
+	run := sarifRun{Tool: sarifTool{Driver: driver}, Results: []sarifResult{}}
+	for _, f := range findings {
+		result := sarifResult{
+			RuleID:  f.RuleID,
+			Level:   sarifLevels[f.Severity],
+			Message: sarifMessage{Text: f.Message},
+		}
+		if i := slices.Index(ids, f.RuleID); i >= 0 {
+			result.RuleIndex = &i
+		}
+		loc := sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: path.Join(filepath.ToSlash(f.Chart), strings.TrimSuffix(f.File, "/"))},
+		}
+		if f.Line > 0 {
+			loc.Region = &sarifRegion{StartLine: f.Line, StartColumn: f.Column}
+		}
+		result.Locations = []sarifLocation{{PhysicalLocation: loc}}
+		run.Results = append(run.Results, result)
+	}
+
+	return output.EncodeJSON(out, sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
@@ -18,7 +18,15 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/internal/test"
+	"helm.sh/helm/v4/pkg/gates"
 )
 
 func TestLintCmdWithSubchartsFlag(t *testing.T) {
@@ -91,7 +99,148 @@ func TestLintCmdWithKubeVersionFlag(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestLintCmdChartV3(t *testing.T) {
+	t.Setenv(string(gates.ChartV3), "1")
+	runTestCmd(t, []cmdTestCase{{
+		name:   "lint chart with apiVersion v3",
+		cmd:    "lint testdata/testcharts/chart-v3",
+		golden: "output/lint-chart-v3.txt",
+	}})
+}
+
+func TestLintCmdWithLookupFixtures(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "lint with lookup fixtures",
+		cmd:    "lint testdata/testcharts/chart-with-lookup --lookup-fixtures testdata/lookup-fixtures",
+		golden: "output/lint-lookup-fixtures.txt",
+	}, {
+		name:      "lint with a missing fixtures directory",
+		cmd:       "lint testdata/testcharts/chart-with-lookup --lookup-fixtures testdata/missing-fixtures",
+		golden:    "output/lint-lookup-missing-fixtures.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
 func TestLintFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "lint", true)
 	checkFileCompletion(t, "lint mypath", true) // Multiple paths can be given
 }
+
+func TestLintCmdWithMissingPlugin(t *testing.T) {
+	tests := []cmdTestCase{
+		{
+			name:      "chart requiring a template function plugin which is not installed",
+			cmd:       "lint testdata/testcharts/chart-with-template-plugins",
+			golden:    "output/lint-missing-plugin.txt",
+			wantError: true,
+		},
+	}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithOutputFlag(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "lint chart with deprecated api version in JSON",
+		cmd:    "lint --kube-version 1.22.0 -o json testdata/testcharts/chart-with-deprecated-api",
+		golden: "output/lint-output-json.txt",
+	}, {
+		name:      "lint two charts, one with errors, in SARIF",
+		cmd:       "lint --kube-version 1.22.0 --output sarif testdata/testcharts/chart-with-deprecated-api testdata/testcharts/chart-bad-requirements",
+		golden:    "output/lint-output-sarif.txt",
+		wantError: true,
+	}, {
+		name:      "lint non-existent chart in JSON",
+		cmd:       "lint -o json thischartdoesntexist/",
+		golden:    "output/lint-output-json-missing-chart.txt",
+		wantError: true,
+	}, {
+		name:      "lint with an invalid output format",
+		cmd:       "lint -o table testdata/testcharts/alpine",
+		golden:    "output/lint-output-invalid.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithWorkloadRulesFlag(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "lint chart with workload rules",
+		cmd:    "lint --workload-rules testdata/testcharts/alpine",
+		golden: "output/lint-workload-rules.txt",
+	}, {
+		name:   "lint chart without workload rules",
+		cmd:    "lint testdata/testcharts/alpine",
+		golden: "output/lint-no-workload-rules.txt",
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithConfigFlag(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:      "lint chart with a lint configuration file",
+		cmd:       "lint --config testdata/lint-config.yaml testdata/testcharts/alpine",
+		golden:    "output/lint-config.txt",
+		wantError: true,
+	}, {
+		name:      "lint chart with a missing lint configuration file",
+		cmd:       "lint --config testdata/no-such-lint-config.yaml testdata/testcharts/alpine",
+		golden:    "output/lint-config-missing.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithManifestSchemas(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:      "lint chart validating the manifests against the bundled schemas",
+		cmd:       "lint --validate-schemas testdata/testcharts/chart-with-invalid-manifests",
+		golden:    "output/lint-validate-schemas.txt",
+		wantError: true,
+	}, {
+		name:      "lint chart validating the manifests against extra CRDs",
+		cmd:       "lint --crd testdata/crontab-crd.yaml testdata/testcharts/chart-with-invalid-manifests",
+		golden:    "output/lint-validate-schemas-crd.txt",
+		wantError: true,
+	}, {
+		name:      "lint chart without an OpenAPI spec for the kube version",
+		cmd:       "lint --openapi-spec testdata/openapi --kube-version 1.29.0 testdata/testcharts/chart-with-invalid-manifests",
+		golden:    "output/lint-validate-schemas-missing-spec.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithFixFlag(t *testing.T) {
+	// The chart is fixed in place, so it is linted in a copy.
+	chartPath := filepath.Join(t.TempDir(), "chart-with-lint-fixes")
+	require.NoError(t, os.CopyFS(chartPath, os.DirFS("testdata/testcharts/chart-with-lint-fixes")))
+
+	_, out, err := executeActionCommand("lint --fix " + chartPath)
+	require.NoError(t, err)
+	test.AssertGoldenString(t, strings.ReplaceAll(out, chartPath, "chart-with-lint-fixes"), "output/lint-fix.txt")
+	chart, err := os.ReadFile(filepath.Join(chartPath, "Chart.yaml"))
+	require.NoError(t, err)
+	test.AssertGoldenString(t, string(chart), "output/lint-fix-chart.txt")
+
+	_, out, err = executeActionCommand("lint --fix " + chartPath)
+	require.NoError(t, err)
+	test.AssertGoldenString(t, strings.ReplaceAll(out, chartPath, "chart-with-lint-fixes"), "output/lint-fix-again.txt")
+
+	chartPath = filepath.Join(t.TempDir(), "chart-with-lint-fixes")
+	require.NoError(t, os.CopyFS(chartPath, os.DirFS("testdata/testcharts/chart-with-lint-fixes")))
+	_, out, err = executeActionCommand("lint --fix -o json " + chartPath)
+	require.NoError(t, err)
+	test.AssertGoldenString(t, strings.ReplaceAll(out, chartPath, "chart-with-lint-fixes"), "output/lint-fix-json.txt")
+
+	// An unknown apiVersion is reported, not replaced.
+	chartPath = filepath.Join(t.TempDir(), "chart-with-lint-fixes")
+	require.NoError(t, os.CopyFS(chartPath, os.DirFS("testdata/testcharts/chart-with-lint-fixes")))
+	chartfile := filepath.Join(chartPath, "Chart.yaml")
+	data, err := os.ReadFile(chartfile)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(chartfile, []byte(strings.Replace(string(data), "apiVersion: V2", "apiVersion: v4", 1)), 0644))
+	_, out, err = executeActionCommand("lint --fix " + chartPath)
+	require.Error(t, err)
+	test.AssertGoldenString(t, strings.ReplaceAll(out, chartPath, "chart-with-lint-fixes"), "output/lint-fix-unknown-apiversion.txt")
+}
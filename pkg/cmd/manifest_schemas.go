@@ -0,0 +1,78 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/kube/openapi"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// manifestSchemaOptions configure the offline validation of rendered
+// manifests against the OpenAPI schemas of their kinds.
+type manifestSchemaOptions struct {
+	validate    bool
+	openAPISpec string
+	crdFiles    []string
+}
+
+func addManifestSchemaFlags(f *pflag.FlagSet, o *manifestSchemaOptions) {
+	f.BoolVar(&o.validate, "validate-schemas", false, "validate the rendered resources offline against the OpenAPI schemas of their kinds and the CRDs of the chart. The bundled schemas are those of the single Kubernetes version Helm is built with, other versions require --openapi-spec")
+	f.StringVar(&o.openAPISpec, "openapi-spec", "", "OpenAPI spec, or directory of specs named after Kubernetes versions, e.g. v1.30.json, replacing the bundled schemas, which are for a single Kubernetes version. Required to validate for other Kubernetes versions. Implies --validate-schemas")
+	f.StringArrayVar(&o.crdFiles, "crd", []string{}, "file of CRDs whose schemas the rendered resources are validated against (can specify multiple). Implies --validate-schemas")
+}
+
+// enabled reports whether the manifests are validated.
+func (o manifestSchemaOptions) enabled() bool {
+	return o.validate || o.openAPISpec != "" || len(o.crdFiles) > 0
+}
+
+// validateRelease validates the manifest and the hooks of a rendered release
+// against the schemas for a Kubernetes version and the CRDs of its chart.
+func (o manifestSchemaOptions) validateRelease(rel *release.Release, kubeVersion *chartutil.KubeVersion, skipTests bool) error {
+	opts := openapi.Options{Spec: o.openAPISpec, CRDFiles: o.crdFiles}
+	if kubeVersion != nil {
+		opts.KubeVersion = kubeVersion.Version
+	}
+	schemas, err := openapi.New(opts)
+	if err != nil {
+		return err
+	}
+	for _, crd := range rel.Chart.CRDObjects() {
+		if schemas, err = schemas.WithCRDs(crd.File.Data); err != nil {
+			return fmt.Errorf("unable to load CRDs %s: %w", crd.Filename, err)
+		}
+	}
+
+	var manifest strings.Builder
+	manifest.WriteString(rel.Manifest)
+	for _, h := range rel.Hooks {
+		if skipTests && isTestHook(h) {
+			continue
+		}
+		fmt.Fprintf(&manifest, "\n---\n# Source: %s\n%s\n", h.Path, h.Manifest)
+	}
+	if err := schemas.ValidateManifest(manifest.String()); err != nil {
+		return fmt.Errorf("the rendered manifests do not match the schemas of their kinds:\n%w", err)
+	}
+	return nil
+}
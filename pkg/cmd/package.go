@@ -22,6 +22,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -45,6 +47,15 @@ provide '--keyring path/to/secret/keys' and '--key keyname'.
 
 If '--keyring' is not specified, Helm usually defaults to the public keyring
 unless your environment is otherwise configured.
+
+With '--reproducible', packaging the same chart twice gives the same archive,
+byte for byte: the files are written in the order of their names, with mode
+0644, no owner, and the modification time given in seconds since the Unix epoch
+by the SOURCE_DATE_EPOCH environment variable, or the Unix epoch when it is not
+set. When the archive already exists, it is not replaced: the chart is rebuilt
+and the command fails if the rebuild does not match the archive.
+
+  $ SOURCE_DATE_EPOCH=$(git log -1 --format=%ct) helm package --reproducible ./mychart
 `
 
 func newPackageCmd(out io.Writer) *cobra.Command {
@@ -67,6 +78,15 @@ func newPackageCmd(out io.Writer) *cobra.Command {
 					return errors.New("--keyring is required for signing a package")
 				}
 			}
+			if client.Reproducible {
+				if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+					secs, err := strconv.ParseInt(epoch, 10, 64)
+					if err != nil {
+						return fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %w", epoch, err)
+					}
+					client.SourceDateEpoch = time.Unix(secs, 0)
+				}
+			}
 			client.RepositoryConfig = settings.RepositoryConfig
 			client.RepositoryCache = settings.RepositoryCache
 			p := getter.All(settings)
@@ -125,6 +145,7 @@ func newPackageCmd(out io.Writer) *cobra.Command {
 	f.StringVar(&client.Version, "version", "", "set the version on the chart to this semver version")
 	f.StringVar(&client.AppVersion, "app-version", "", "set the appVersion on the chart to this version")
 	f.StringVarP(&client.Destination, "destination", "d", ".", "location to write the chart.")
+	f.BoolVar(&client.Reproducible, "reproducible", false, "package the chart in an archive which only depends on its contents, and verify the rebuild of an existing archive")
 	f.BoolVarP(&client.DependencyUpdate, "dependency-update", "u", false, `update dependencies from "Chart.yaml" to dir "charts/" before packaging`)
 	f.StringVar(&client.Username, "username", "", "chart repository username where to locate the requested chart")
 	f.StringVar(&client.Password, "password", "", "chart repository password where to locate the requested chart")
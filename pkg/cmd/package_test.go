@@ -16,10 +16,12 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"testing"
 
@@ -196,3 +198,90 @@ func TestPackageFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "package", true)
 	checkFileCompletion(t, "package mypath", true) // Multiple paths can be given
 }
+
+func TestPackageReproducible(t *testing.T) {
+	chartToPackage := "testdata/testcharts/alpine"
+	first, second := t.TempDir(), t.TempDir()
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+
+	for _, dir := range []string{first, second} {
+		cmd := fmt.Sprintf("package --reproducible %s --destination=%s", chartToPackage, dir)
+		if _, output, err := executeActionCommand(cmd); err != nil {
+			t.Logf("Output: %s", output)
+			t.Fatal(err)
+		}
+	}
+	data1, err := os.ReadFile(filepath.Join(first, "alpine-0.1.0.tgz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data2, err := os.ReadFile(filepath.Join(second, "alpine-0.1.0.tgz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data1, data2) {
+		t.Fatal("expected packaging the same chart twice to give the same archive")
+	}
+
+	// The rebuild of an existing archive is verified.
+	cmd := fmt.Sprintf("package --reproducible %s --destination=%s", chartToPackage, first)
+	if _, output, err := executeActionCommand(cmd); err != nil {
+		t.Logf("Output: %s", output)
+		t.Fatal(err)
+	}
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000001")
+	_, _, err = executeActionCommand(cmd)
+	if err == nil || !strings.Contains(err.Error(), "rebuilt chart does not match the existing archive") {
+		t.Fatalf("expected the rebuild not to match the existing archive, got %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(first, "alpine-0.1.0.tgz")); err != nil || !bytes.Equal(data, data1) {
+		t.Fatal("expected the existing archive not to be replaced")
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	_, _, err = executeActionCommand(cmd)
+	if err == nil || !strings.Contains(err.Error(), `invalid SOURCE_DATE_EPOCH "yesterday"`) {
+		t.Fatalf("expected an invalid SOURCE_DATE_EPOCH error, got %v", err)
+	}
+}
+
+func TestPackageExcludesDevelopmentFiles(t *testing.T) {
+	chartToPackage := filepath.Join(t.TempDir(), "alpine")
+	if err := os.CopyFS(chartToPackage, os.DirFS("testdata/testcharts/alpine")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(chartToPackage, "tests"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(chartToPackage, "tests", "pod_test.yaml"), []byte("suite: pod\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(chartToPackage, ".helmlint.yaml"), []byte("ignore: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := t.TempDir()
+	cmd := fmt.Sprintf("package %s --destination=%s", chartToPackage, dest)
+	if _, output, err := executeActionCommand(cmd); err != nil {
+		t.Logf("Output: %s", output)
+		t.Fatal(err)
+	}
+	c, err := loader.Load(filepath.Join(dest, "alpine-0.1.0.tgz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range c.Files {
+		if strings.HasPrefix(f.Name, "tests/") || f.Name == ".helmlint.yaml" {
+			t.Errorf("expected %s not to be packaged", f.Name)
+		}
+	}
+
+	// The suites are files of the chart directory.
+	c, err = loader.Load(chartToPackage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.ContainsFunc(c.Files, func(f *chart.File) bool { return f.Name == "tests/pod_test.yaml" }) {
+		t.Error("expected the suites to be loaded with the chart directory")
+	}
+}
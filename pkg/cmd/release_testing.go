@@ -36,6 +36,14 @@ The test command runs the tests for a release.
 
 The argument this command takes is the name of a deployed release.
 The tests to be run are defined in the chart that was installed.
+
+Tests can be selected by name with '--filter' and by the labels of the test
+manifests with '--selector'. Tests with the same hook weight can be run in
+parallel with '--parallel', and flaky tests can be re-run with '--retries'.
+
+The results can be written as JUnit XML ('--junit-report') and JSON
+('--json-report') files, including the captured test pod logs when '--logs'
+is set.
 `
 
 func newReleaseTestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -43,6 +51,7 @@ func newReleaseTestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command
 	outfmt := output.Table
 	var outputLogs bool
 	var filter []string
+	var junitReport, jsonReport string
 
 	cmd := &cobra.Command{
 		Use:   "test [RELEASE]",
@@ -83,6 +92,18 @@ func newReleaseTestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command
 				return err
 			}
 
+			// The reports are written first, so that they are kept when
+			// the logs of a test pod cannot be output.
+			if junitReport != "" || jsonReport != "" {
+				results, err := client.Results(rel, outputLogs)
+				if err != nil {
+					return errors.Join(runErr, err)
+				}
+				if err := writeTestReports(rel, results, junitReport, jsonReport); err != nil {
+					return errors.Join(runErr, err)
+				}
+			}
+
 			if outputLogs {
 				// Print a newline to stdout to separate the output
 				fmt.Fprintln(out)
@@ -100,6 +121,11 @@ func newReleaseTestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command
 	f.BoolVar(&outputLogs, "logs", false, "dump the logs from test pods (this runs after all tests are complete, but before any cleanup)")
 	f.StringSliceVar(&filter, "filter", []string{}, "specify tests by attribute (currently \"name\") using attribute=value syntax or '!attribute=value' to exclude a test (can specify multiple or separate values with commas: name=test1,name=test2)")
 	f.BoolVar(&client.HideNotes, "hide-notes", false, "if set, do not show notes in test output. Does not affect presence in chart metadata")
+	f.StringVarP(&client.Selector, "selector", "l", "", "only run tests whose labels match the selector (e.g. -l key1=value1,key2=value2). Works with '--filter'")
+	f.IntVar(&client.Parallel, "parallel", 1, "maximum number of tests with the same weight to run at the same time")
+	f.IntVar(&client.Retries, "retries", 0, "number of times to re-run a failed test before reporting it as failed")
+	f.StringVar(&junitReport, "junit-report", "", "write the test results as a JUnit XML report to the given file")
+	f.StringVar(&jsonReport, "json-report", "", "write the test results as a JSON report to the given file. The pod logs are included when '--logs' is set")
 
 	return cmd
 }
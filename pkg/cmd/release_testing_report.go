@@ -0,0 +1,147 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// testReport is the JSON representation of the results of 'helm test'.
+type testReport struct {
+	Release   string              `json:"release"`
+	Namespace string              `json:"namespace"`
+	Revision  int                 `json:"revision"`
+	Tests     []action.TestResult `json:"tests"`
+}
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+	SystemErr string        `xml:"system-err,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+// writeTestReports writes the JUnit and JSON test reports to the given files.
+// An empty file name skips the corresponding report.
+func writeTestReports(rel *release.Release, results []action.TestResult, junitFile, jsonFile string) error {
+	if junitFile != "" {
+		if err := writeReportFile(junitFile, func(w io.Writer) error {
+			return writeJUnitReport(w, rel, results)
+		}); err != nil {
+			return err
+		}
+	}
+	if jsonFile != "" {
+		if err := writeReportFile(jsonFile, func(w io.Writer) error {
+			return writeJSONReport(w, rel, results)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeReportFile(name string, write func(io.Writer) error) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("unable to create test report: %w", err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("unable to write test report %s: %w", name, err)
+	}
+	return f.Close()
+}
+
+func writeJSONReport(out io.Writer, rel *release.Release, results []action.TestResult) error {
+	return output.EncodeJSON(out, testReport{
+		Release:   rel.Name,
+		Namespace: rel.Namespace,
+		Revision:  rel.Version,
+		Tests:     results,
+	})
+}
+
+func writeJUnitReport(out io.Writer, rel *release.Release, results []action.TestResult) error {
+	suite := junitTestSuite{
+		Name: rel.Name,
+	}
+	var total float64
+	for _, r := range results {
+		tc := junitTestCase{
+			Name:      r.Name,
+			Classname: fmt.Sprintf("%s.%s", rel.Namespace, rel.Name),
+			Time:      fmt.Sprintf("%.3f", r.Duration.Seconds()),
+			SystemOut: r.Logs,
+			SystemErr: r.LogsError,
+		}
+		if r.Phase != release.HookPhaseSucceeded {
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("test %s finished with phase %s after %d attempt(s)", r.Name, r.Phase, r.Attempts),
+				Type:    r.Phase.String(),
+			}
+			suite.Failures++
+		}
+		if suite.Timestamp == "" && !r.StartedAt.IsZero() {
+			suite.Timestamp = r.StartedAt.UTC().Format("2006-01-02T15:04:05")
+		}
+		total += r.Duration.Seconds()
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Tests = len(suite.Cases)
+	suite.Time = fmt.Sprintf("%.3f", total)
+
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(out)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(out)
+	return err
+}
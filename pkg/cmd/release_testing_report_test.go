@@ -0,0 +1,95 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"helm.sh/helm/v4/internal/test"
+	"helm.sh/helm/v4/pkg/action"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func testReportFixture() (*release.Release, []action.TestResult) {
+	rel := release.Mock(&release.MockReleaseOptions{Name: "aeneas", Namespace: "troy"})
+	started := testTimestamper()
+	results := []action.TestResult{
+		{
+			Name:        "smoke",
+			Path:        "templates/tests/smoke.yaml",
+			Phase:       release.HookPhaseSucceeded,
+			StartedAt:   started,
+			CompletedAt: started.Add(1500 * time.Millisecond),
+			Duration:    1500 * time.Millisecond,
+			Attempts:    1,
+			Logs:        "all good\n",
+		},
+		{
+			Name:        "e2e",
+			Path:        "templates/tests/e2e.yaml",
+			Phase:       release.HookPhaseFailed,
+			StartedAt:   started.Add(2 * time.Second),
+			CompletedAt: started.Add(5 * time.Second),
+			Duration:    3 * time.Second,
+			Attempts:    3,
+			LogsError:   `unable to get pod logs for e2e: pods "e2e" not found`,
+		},
+	}
+	return rel, results
+}
+
+func TestWriteJUnitReport(t *testing.T) {
+	rel, results := testReportFixture()
+	var buf bytes.Buffer
+	if err := writeJUnitReport(&buf, rel, results); err != nil {
+		t.Fatal(err)
+	}
+	test.AssertGoldenString(t, buf.String(), "output/test-report-junit.xml")
+}
+
+func TestWriteJSONReport(t *testing.T) {
+	rel, results := testReportFixture()
+	var buf bytes.Buffer
+	if err := writeJSONReport(&buf, rel, results); err != nil {
+		t.Fatal(err)
+	}
+	test.AssertGoldenString(t, buf.String(), "output/test-report.json")
+}
+
+func TestWriteTestReports(t *testing.T) {
+	rel, results := testReportFixture()
+	dir := t.TempDir()
+	junitFile := filepath.Join(dir, "junit.xml")
+	jsonFile := filepath.Join(dir, "report.json")
+
+	if err := writeTestReports(rel, results, junitFile, jsonFile); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{junitFile, jsonFile} {
+		if _, err := os.Stat(f); err != nil {
+			t.Errorf("expected report %s to be written: %v", f, err)
+		}
+	}
+
+	if err := writeTestReports(rel, results, filepath.Join(dir, "missing", "junit.xml"), ""); err == nil {
+		t.Error("expected an error writing to a missing directory")
+	}
+}
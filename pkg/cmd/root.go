@@ -24,6 +24,7 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/fatih/color"
@@ -37,6 +38,7 @@ import (
 	"helm.sh/helm/v4/internal/tlsutil"
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/gates"
 	kubefake "helm.sh/helm/v4/pkg/kube/fake"
 	"helm.sh/helm/v4/pkg/registry"
 	release "helm.sh/helm/v4/pkg/release/v1"
@@ -59,6 +61,7 @@ Environment variables:
 |------------------------------------|------------------------------------------------------------------------------------------------------------|
 | $HELM_CACHE_HOME                   | set an alternative location for storing cached files.                                                      |
 | $HELM_CONFIG_HOME                  | set an alternative location for storing Helm configuration.                                                |
+| $HELM_CONFIG                       | set the path to the Helm configuration file (default "$HELM_CONFIG_HOME/config.yaml").                     |
 | $HELM_DATA_HOME                    | set an alternative location for storing Helm data.                                                         |
 | $HELM_DEBUG                        | indicate whether or not Helm is running in Debug mode                                                      |
 | $HELM_DRIVER                       | set the backend storage driver. Values are: configmap, secret, memory, sql.                                |
@@ -67,6 +70,7 @@ Environment variables:
 | $HELM_NAMESPACE                    | set the namespace used for the helm operations.                                                            |
 | $HELM_NO_PLUGINS                   | disable plugins. Set HELM_NO_PLUGINS=1 to disable plugins.                                                 |
 | $HELM_PLUGINS                      | set the path to the plugins directory                                                                      |
+| $HELM_PROFILE                      | set the profile of the Helm configuration file to use.                                                     |
 | $HELM_REGISTRY_CONFIG              | set the path to the registry config file.                                                                  |
 | $HELM_REPOSITORY_CACHE             | set the path to the repository cache directory                                                             |
 | $HELM_REPOSITORY_CONFIG            | set the path to the repositories file.                                                                     |
@@ -147,15 +151,21 @@ func configureColorOutput(settings *cli.EnvSettings) {
 }
 
 func newRootCmdWithConfig(actionConfig *action.Configuration, out io.Writer, args []string, logSetup func(bool)) (*cobra.Command, error) {
+	var configErr error
 	cmd := &cobra.Command{
 		Use:          "helm",
 		Short:        "The Helm package manager for Kubernetes.",
 		Long:         globalUsage,
 		SilenceUsage: true,
-		PersistentPreRun: func(_ *cobra.Command, _ []string) {
+		PersistentPreRunE: func(c *cobra.Command, _ []string) error {
+			// 'helm config' has to work to repair a broken configuration.
+			if configErr != nil && !isConfigCmd(c) {
+				return configErr
+			}
 			if err := startProfiling(); err != nil {
 				log.Printf("Warning: Failed to start profiling: %v", err)
 			}
+			return nil
 		},
 		PersistentPostRun: func(_ *cobra.Command, _ []string) {
 			if err := stopProfiling(); err != nil {
@@ -176,6 +186,15 @@ func newRootCmdWithConfig(actionConfig *action.Configuration, out io.Writer, arg
 	flags.ParseErrorsWhitelist.UnknownFlags = true
 	flags.Parse(args)
 
+	// The defaults of the configuration file apply to the flags that were not
+	// given, the flags of the commands are set once they are added.
+	userConfig, configErr := loadUserConfig()
+	if configErr == nil {
+		applyGlobalConfig(flags, userConfig)
+	} else {
+		gates.SetDefaults(nil)
+	}
+
 	logSetup(settings.Debug)
 
 	// Validate color mode setting
@@ -251,6 +270,7 @@ func newRootCmdWithConfig(actionConfig *action.Configuration, out io.Writer, arg
 		return nil, err
 	}
 	actionConfig.RegistryClient = registryClient
+	actionConfig.PluginDirs = filepath.SplitList(settings.PluginsDirectory)
 
 	// Add subcommands
 	cmd.AddCommand(
@@ -262,12 +282,16 @@ func newRootCmdWithConfig(actionConfig *action.Configuration, out io.Writer, arg
 		newLintCmd(out),
 		newPackageCmd(out),
 		newRepoCmd(out),
+		newSchemaCmd(out),
 		newSearchCmd(out),
+		newUnittestCmd(out),
 		newVerifyCmd(out),
 
 		// release commands
+		newApplyCmd(actionConfig, out),
 		newGetCmd(actionConfig, out),
 		newHistoryCmd(actionConfig, out),
+		newHookCmd(actionConfig, out),
 		newInstallCmd(actionConfig, out),
 		newListCmd(actionConfig, out),
 		newReleaseTestCmd(actionConfig, out),
@@ -278,6 +302,7 @@ func newRootCmdWithConfig(actionConfig *action.Configuration, out io.Writer, arg
 		newUpgradeCmd(actionConfig, out),
 
 		newCompletionCmd(out),
+		newConfigCmd(out),
 		newEnvCmd(out),
 		newPluginCmd(out),
 		newVersionCmd(out),
@@ -294,6 +319,10 @@ func newRootCmdWithConfig(actionConfig *action.Configuration, out io.Writer, arg
 	// Find and add CLI plugins
 	loadCLIPlugins(cmd, out)
 
+	if configErr == nil {
+		applyCommandConfig(cmd, userConfig)
+	}
+
 	// Check for expired repositories
 	checkForExpiredRepos(settings.RepositoryConfig)
 
@@ -0,0 +1,43 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const schemaHelp = `
+This command consists of multiple subcommands to work with the JSON schema
+(values.schema.json) that validates the values of a chart.
+`
+
+func newSchemaCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "work with the values schema of a chart",
+		Long:  schemaHelp,
+		Args:  require.NoArgs,
+	}
+
+	cmd.AddCommand(newSchemaGenerateCmd(out))
+
+	return cmd
+}
@@ -0,0 +1,80 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const schemaGenerateDesc = `
+Generate a values.schema.json (JSON schema draft 2020-12) for a chart from its
+values.yaml.
+
+The type of every value is inferred from its default. Comments starting with
+'@schema' on a key refine its schema:
+
+    # @schema description: Number of replicas of the deployment
+    # @schema minimum: 1
+    # @schema required
+    replicaCount: 1
+
+    # @schema enum: [Always, IfNotPresent, Never]
+    pullPolicy: IfNotPresent
+
+    # @schema pattern: ^v[0-9]+
+    tag: v1
+
+The supported annotations are description, type, enum, required, pattern,
+minimum and maximum.
+
+The schemas of the subcharts, either their own values.schema.json or one
+generated from their values.yaml, are merged in under their name or alias.
+
+By default the schema is written to values.schema.json in the chart directory.
+Use '--stdout' to print it instead.
+`
+
+func newSchemaGenerateCmd(out io.Writer) *cobra.Command {
+	client := action.NewSchemaGenerate()
+
+	cmd := &cobra.Command{
+		Use:   "generate CHART",
+		Short: "generate values.schema.json from the values of a chart",
+		Long:  schemaGenerateDesc,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				// Allow file completion when completing the argument for the directory
+				return nil, cobra.ShellCompDirectiveDefault
+			}
+			// No more completions, so disable file completion
+			return noMoreArgsComp()
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			return client.Run(args[0], out)
+		},
+	}
+
+	cmd.Flags().BoolVar(&client.Stdout, "stdout", false, "print the schema instead of writing it to values.schema.json in the chart directory")
+
+	return cmd
+}
@@ -0,0 +1,40 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestSchemaGenerateCmd(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "generate the schema of a chart with a subchart",
+		cmd:    "schema generate testdata/testcharts/issue-9027 --stdout",
+		golden: "output/schema-generate.json",
+	}, {
+		name:      "generate without a chart",
+		cmd:       "schema generate",
+		golden:    "output/schema-generate-no-args.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestSchemaGenerateFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "schema generate", true)
+	checkFileCompletion(t, "schema generate mychart", false)
+}
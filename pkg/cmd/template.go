@@ -18,26 +18,33 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
+	"os/signal"
 	"path"
 	"path/filepath"
 	"regexp"
 	"slices"
 	"sort"
 	"strings"
+	"syscall"
+	"time"
 
 	release "helm.sh/helm/v4/pkg/release/v1"
 
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/pkg/action"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/engine"
+	"helm.sh/helm/v4/pkg/getter"
 	releaseutil "helm.sh/helm/v4/pkg/release/util"
 )
 
@@ -47,6 +54,30 @@ Render chart templates locally and display the output.
 Any values that would normally be looked up or retrieved in-cluster will be
 faked locally. Additionally, none of the server-side testing of chart validity
 (e.g. whether an API is supported) is done.
+
+With '--watch', the chart directory, the charts in its 'charts/' directory and
+the values files are watched, and the chart is rendered again each time they
+change. Only the documents whose rendering changed are printed.
+
+With '--snapshot-dir', each rendered document is also compared to a snapshot
+stored in the given directory, under the path of its template and its group,
+version, kind and name, in a file ending with '.snap.yaml'. Other files of the
+directory are left alone. The documents are normalized, so only changes to their
+content matter. When the directory has no snapshots yet they are written;
+otherwise a diff is shown and the command fails when a document changed.
+'--snapshot-update' accepts the changes, and '--snapshot-check' fails when
+there are no snapshots to compare to, e.g. in CI.
+
+With '--validate-schemas', the rendered resources are validated offline
+against the OpenAPI schemas of their kinds, instead of against a cluster as
+with '--validate'. The schemas of the built-in kinds are bundled with Helm, for
+the Kubernetes version it is built with. '--openapi-spec' replaces them with
+an OpenAPI spec, e.g. downloaded with 'kubectl get --raw /openapi/v2', or with
+the spec of the '--kube-version' in a directory of specs named after
+Kubernetes versions, e.g. 'v1.30.json'. Another '--kube-version' than the one
+of the bundled schemas requires '--openapi-spec'. Custom resources are
+validated against the CRDs of the chart and the ones given with '--crd'. The
+invalid fields are reported for each resource.
 `
 
 func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -58,6 +89,14 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	var kubeVersion string
 	var extraAPIs []string
 	var showFiles []string
+	var explainValues bool
+	var profileRender bool
+	var profileRenderPprof string
+	var lookupFixtures string
+	var concurrency int
+	var watch bool
+	var snapshot snapshotOptions
+	var schemaOpts manifestSchemaOptions
 
 	cmd := &cobra.Command{
 		Use:   "template [NAME] [CHART]",
@@ -67,7 +106,7 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			return compInstall(args, toComplete, client)
 		},
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if kubeVersion != "" {
 				parsedKubeVersion, err := chartutil.ParseKubeVersion(kubeVersion)
 				if err != nil {
@@ -95,7 +134,51 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			client.ClientOnly = !validate
 			client.APIVersions = chartutil.VersionSet(extraAPIs)
 			client.IncludeCRDs = includeCrds
+			if lookupFixtures != "" {
+				provider, err := engine.NewFixtureClientProvider(lookupFixtures)
+				if err != nil {
+					return err
+				}
+				cfg.LookupProvider = provider
+				defer func() {
+					cfg.LookupProvider = nil
+				}()
+			}
+			cfg.RenderConcurrency = renderConcurrency(concurrency)
+			cfg.RenderSourceMap = true
+			defer func() {
+				cfg.RenderConcurrency = 0
+				cfg.RenderSourceMap = false
+			}()
+			if err := snapshot.validate(); err != nil {
+				return err
+			}
+			if snapshot.dir != "" && client.OutputDir != "" {
+				return errors.New("--snapshot-dir cannot be used with --output-dir")
+			}
+			if watch {
+				if validate || client.OutputDir != "" || len(showFiles) > 0 || explainValues || profileRender || profileRenderPprof != "" || snapshot.dir != "" || schemaOpts.enabled() {
+					return errors.New("--watch cannot be used with --validate, --output-dir, --show-only, --explain-values, --profile-render, --snapshot-dir or --validate-schemas")
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+				defer cancel()
+				// The templates which did not change are not parsed again.
+				cfg.RenderParseCache = engine.NewParseCache()
+				defer func() {
+					cfg.RenderParseCache = nil
+				}()
+				return runTemplateWatch(ctx, args, client, valueOpts, skipTests, out, cmd.ErrOrStderr())
+			}
+			if profileRender || profileRenderPprof != "" {
+				cfg.RenderProfile = engine.NewProfile()
+				defer func() {
+					cfg.RenderProfile = nil
+				}()
+			}
 			rel, err := runInstall(args, client, valueOpts, out)
+			if perr := writeRenderProfile(cmd.ErrOrStderr(), cfg.RenderProfile, profileRender, profileRenderPprof); perr != nil {
+				return perr
+			}
 
 			if err != nil && !settings.Debug {
 				if rel != nil {
@@ -104,6 +187,16 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				return err
 			}
 
+			if schemaOpts.enabled() && rel != nil && err == nil {
+				if err := schemaOpts.validateRelease(rel, client.KubeVersion, skipTests); err != nil {
+					return err
+				}
+			}
+
+			if explainValues && rel != nil {
+				return explainReleaseValues(out, rel, valueOpts)
+			}
+
 			// We ignore a potential error here because, when the --debug flag was specified,
 			// we always want to print the YAML, even if it is not valid. The error is still returned afterwards.
 			if rel != nil {
@@ -136,6 +229,12 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 					}
 				}
 
+				if snapshot.dir != "" && err == nil {
+					if err := snapshotManifest(cmd.ErrOrStderr(), manifests.String(), snapshot); err != nil {
+						return err
+					}
+				}
+
 				// if we have a list of files to render, then check that each of the
 				// provided files exists in the chart.
 				if len(showFiles) > 0 {
@@ -194,6 +293,7 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 	f := cmd.Flags()
 	addInstallFlags(cmd, f, client, valueOpts)
+	addManifestSchemaFlags(f, &schemaOpts)
 	f.StringArrayVarP(&showFiles, "show-only", "s", []string{}, "only show manifests rendered from the given templates")
 	f.StringVar(&client.OutputDir, "output-dir", "", "writes the executed templates to files in output-dir instead of stdout")
 	f.BoolVar(&validate, "validate", false, "validate your manifests against the Kubernetes cluster you are currently pointing at. This is the same validation performed on an install")
@@ -203,11 +303,66 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.StringVar(&kubeVersion, "kube-version", "", "Kubernetes version used for Capabilities.KubeVersion")
 	f.StringSliceVarP(&extraAPIs, "api-versions", "a", []string{}, "Kubernetes api versions used for Capabilities.APIVersions (multiple can be specified)")
 	f.BoolVar(&client.UseReleaseName, "release-name", false, "use release name in the output-dir path.")
+	f.BoolVar(&explainValues, "explain-values", false, "instead of rendering the manifests, show where each computed value comes from")
+	f.BoolVar(&profileRender, "profile-render", false, "print the time spent rendering each template and named template to stderr")
+	f.StringVar(&profileRenderPprof, "profile-render-pprof", "", "write the time spent rendering each template to the given file in the pprof format")
+	addLookupFixturesFlag(f, &lookupFixtures)
+	addRenderConcurrencyFlag(f, &concurrency)
+	f.StringVar(&snapshot.dir, "snapshot-dir", "", "write a normalized snapshot of each rendered document to the given directory, or compare the documents to the snapshots it holds")
+	f.BoolVar(&snapshot.check, "snapshot-check", false, "fail when the rendered documents do not match the snapshots of --snapshot-dir, including when there are none")
+	f.BoolVar(&snapshot.update, "snapshot-update", false, "replace the snapshots of --snapshot-dir with the rendered documents")
+	f.BoolVar(&watch, "watch", false, "render the chart directory again whenever it, its subcharts or the values files change, and print the documents that changed")
 	bindPostRenderFlag(cmd, &client.PostRenderer, settings)
 
 	return cmd
 }
 
+// explainReleaseValues writes where each computed value of a rendered release
+// comes from, breaking the user supplied values down by file and flag.
+func explainReleaseValues(out io.Writer, rel *release.Release, valueOpts *values.Options) error {
+	sources, err := valueOpts.Sources(getter.All(settings))
+	if err != nil {
+		return err
+	}
+	computed, err := chartutil.CoalesceValues(rel.Chart, rel.Config)
+	if err != nil {
+		return err
+	}
+	explanations := chartutil.ExplainValues(rel.Chart, computed, sources)
+	return valuesExplanationWriter{explanations}.WriteTable(out)
+}
+
+// writeRenderProfile prints the render profile as a table, the slowest
+// templates first, and writes it to a pprof file.
+func writeRenderProfile(out io.Writer, profile *engine.Profile, table bool, pprofFile string) error {
+	if profile == nil {
+		return nil
+	}
+	if table {
+		tbl := uitable.New()
+		tbl.AddRow("TEMPLATE", "KIND", "CALLS", "INCLUDES", "TPLS", "SELF", "TOTAL")
+		for _, e := range profile.Entries() {
+			kind := "template"
+			if e.Define {
+				kind = "define"
+			}
+			tbl.AddRow(e.Name, kind, e.Calls, e.Includes, e.Tpls, e.Self.Round(time.Microsecond), e.Total.Round(time.Microsecond))
+		}
+		fmt.Fprintln(out, tbl)
+	}
+	if pprofFile != "" {
+		f, err := os.Create(pprofFile)
+		if err != nil {
+			return fmt.Errorf("could not create render profile: %w", err)
+		}
+		defer f.Close()
+		if err := profile.WritePprof(f); err != nil {
+			return fmt.Errorf("could not write render profile: %w", err)
+		}
+	}
+	return nil
+}
+
 func isTestHook(h *release.Hook) bool {
 	return slices.Contains(h.Events, release.HookTest)
 }
@@ -0,0 +1,258 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/yaml"
+
+	releaseutil "helm.sh/helm/v4/pkg/release/util"
+)
+
+// snapshotOptions configure how 'helm template' snapshots the rendered
+// documents.
+type snapshotOptions struct {
+	dir    string
+	check  bool
+	update bool
+}
+
+func (o snapshotOptions) validate() error {
+	if o.dir == "" && (o.check || o.update) {
+		return errors.New("--snapshot-check and --snapshot-update require --snapshot-dir")
+	}
+	if o.check && o.update {
+		return errors.New("--snapshot-check and --snapshot-update cannot be used together")
+	}
+	return nil
+}
+
+// snapshotSuffix ends the names of the snapshot files. Only the files with it
+// are read, written and removed, so the snapshot directory can hold other
+// files, e.g. when it is the chart directory.
+const snapshotSuffix = ".snap.yaml"
+
+// snapshotFileChars are the characters not replaced in the file names of
+// snapshots.
+var snapshotFileChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// snapshots splits a stream of rendered documents into normalized snapshots,
+// keyed by their path in the snapshot directory: the template they come from,
+// then their group, version, kind and name, e.g.
+// "mychart/templates/deployment.yaml/apps_v1_Deployment_web.snap.yaml".
+func snapshots(manifest string) map[string]string {
+	snaps := map[string]string{}
+	for _, doc := range releaseutil.SplitDocuments(manifest) {
+		var content map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc.Content), &content); err == nil && content == nil {
+			continue
+		}
+
+		var head struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Metadata   struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}
+		name := "document"
+		if err := yaml.Unmarshal([]byte(doc.Content), &head); err == nil && head.Kind != "" {
+			name = strings.Join([]string{strings.ReplaceAll(head.APIVersion, "/", "_"), head.Kind, head.Metadata.Name}, "_")
+			name = strings.Trim(snapshotFileChars.ReplaceAllString(name, "-"), "_")
+		}
+
+		src := documentKey(doc.Content)
+		if src == "" {
+			src = "manifest"
+		}
+		key := path.Join(src, name+snapshotSuffix)
+		for i := 2; ; i++ {
+			if _, ok := snaps[key]; !ok {
+				break
+			}
+			key = path.Join(src, fmt.Sprintf("%s_%d%s", name, i, snapshotSuffix))
+		}
+		snaps[key] = normalizeDocument(doc.Content)
+	}
+	return snaps
+}
+
+// normalizeDocument formats a document the same way whatever its indentation,
+// order of keys, quoting or comments. Documents which are not valid YAML are
+// kept as they are.
+func normalizeDocument(doc string) string {
+	var content interface{}
+	if err := yaml.Unmarshal([]byte(doc), &content); err != nil {
+		return strings.TrimSpace(doc) + "\n"
+	}
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return strings.TrimSpace(doc) + "\n"
+	}
+	return string(data)
+}
+
+// readSnapshots reads the snapshots of a directory, keyed by their path. A
+// missing directory has no snapshots, and files without the snapshot suffix
+// are not snapshots.
+func readSnapshots(dir string) (map[string]string, error) {
+	snaps := map[string]string{}
+	err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && name == dir {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(name, snapshotSuffix) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		snaps[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read snapshots: %w", err)
+	}
+	return snaps, nil
+}
+
+// writeSnapshots writes the snapshots to a directory, and removes the
+// existing snapshots, read by readSnapshots, that are not among them.
+func writeSnapshots(dir string, snaps, existing map[string]string) error {
+	for key, content := range snaps {
+		if existing[key] == content {
+			continue
+		}
+		name := filepath.Join(dir, filepath.FromSlash(key))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return fmt.Errorf("cannot write snapshot: %w", err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			return fmt.Errorf("cannot write snapshot: %w", err)
+		}
+	}
+	for key := range existing {
+		if _, ok := snaps[key]; ok {
+			continue
+		}
+		name := filepath.Join(dir, filepath.FromSlash(key))
+		if err := os.Remove(name); err != nil {
+			return fmt.Errorf("cannot remove snapshot: %w", err)
+		}
+		// Remove the directories left empty, up to the snapshot directory.
+		for d := filepath.Dir(name); d != filepath.Clean(dir); d = filepath.Dir(d) {
+			if os.Remove(d) != nil {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// snapshotDiff returns the unified diffs between the snapshots and the
+// rendered documents, ordered by path.
+func snapshotDiff(existing, snaps map[string]string) []string {
+	keys := map[string]bool{}
+	for key := range existing {
+		keys[key] = true
+	}
+	for key := range snaps {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	var diffs []string
+	for _, key := range sorted {
+		from, to := existing[key], snaps[key]
+		if from == to {
+			continue
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        snapshotLines(from),
+			B:        snapshotLines(to),
+			FromFile: "snapshot/" + key,
+			ToFile:   "rendered/" + key,
+			Context:  3,
+		})
+		if err != nil {
+			// The diff is only written to a buffer, which does not fail.
+			continue
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+func snapshotLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return difflib.SplitLines(strings.TrimSuffix(s, "\n"))
+}
+
+// snapshotManifest compares the rendered documents to the snapshots of the
+// snapshot directory. The snapshots are written when the directory has none
+// yet, or when updating them. Otherwise, documents that do not match their
+// snapshot are an error.
+func snapshotManifest(out io.Writer, manifest string, opts snapshotOptions) error {
+	snaps := snapshots(manifest)
+	existing, err := readSnapshots(opts.dir)
+	if err != nil {
+		return err
+	}
+	diffs := snapshotDiff(existing, snaps)
+
+	if opts.update || (!opts.check && len(existing) == 0) {
+		if err := writeSnapshots(opts.dir, snaps, existing); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "==> %d snapshot(s) written to %s, %d changed\n", len(snaps), opts.dir, len(diffs))
+		return nil
+	}
+
+	if len(diffs) > 0 {
+		for _, diff := range diffs {
+			fmt.Fprint(out, diff)
+		}
+		return fmt.Errorf("%d snapshot(s) in %s do not match the rendered documents. Use --snapshot-update to accept the changes", len(diffs), opts.dir)
+	}
+	fmt.Fprintf(out, "==> %d snapshot(s) in %s match the rendered documents\n", len(snaps), opts.dir)
+	return nil
+}
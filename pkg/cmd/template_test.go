@@ -17,9 +17,22 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/action"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/gates"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
 )
 
 var chartPath = "testdata/testcharts/subchart"
@@ -33,6 +46,16 @@ func TestTemplateCmd(t *testing.T) {
 			cmd:    fmt.Sprintf("template '%s'", chartPath),
 			golden: "output/template.txt",
 		},
+		{
+			name:   "check rendering one template after the other",
+			cmd:    fmt.Sprintf("template '%s' --render-concurrency 1", chartPath),
+			golden: "output/template.txt",
+		},
+		{
+			name:   "check rendering templates concurrently",
+			cmd:    fmt.Sprintf("template '%s' --render-concurrency 4", chartPath),
+			golden: "output/template.txt",
+		},
 		{
 			name:   "check set name",
 			cmd:    fmt.Sprintf("template '%s' --set service.name=apache", chartPath),
@@ -146,6 +169,11 @@ func TestTemplateCmd(t *testing.T) {
 			cmd:    fmt.Sprintf("template '%s'", deletevalchart),
 			golden: "output/issue-9027.txt",
 		},
+		{
+			name:   "explain values",
+			cmd:    fmt.Sprintf("template '%s' --set global.hash.key4=40 --set subchart.hash.key5=50 --explain-values", deletevalchart),
+			golden: "output/template-explain-values.txt",
+		},
 		{
 			// Ensure that parent chart values take precedence over imported values
 			name:   "template with imported subchart values ensuring import",
@@ -170,6 +198,42 @@ func TestTemplateCmd(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestTemplateChartV3(t *testing.T) {
+	t.Setenv(string(gates.ChartV3), "")
+	runTestCmd(t, []cmdTestCase{{
+		name:      "chart with apiVersion v3 without the feature gate",
+		cmd:       "template testdata/testcharts/chart-v3",
+		wantError: true,
+		golden:    "output/template-chart-v3-disabled.txt",
+	}})
+
+	t.Setenv(string(gates.ChartV3), "1")
+	runTestCmd(t, []cmdTestCase{{
+		name:   "chart with apiVersion v3",
+		cmd:    "template testdata/testcharts/chart-v3",
+		golden: "output/template-chart-v3.txt",
+	}})
+}
+
+func TestTemplateProfileRender(t *testing.T) {
+	pprofFile := filepath.Join(t.TempDir(), "render.pprof")
+	cmd := "template testdata/testcharts/chart-with-lib-dep --profile-render --profile-render-pprof " + pprofFile
+	_, out, err := executeActionCommand(cmd)
+	require.NoError(t, err)
+
+	assert.Contains(t, out, "kind: Service")
+	assert.Regexp(t, `TEMPLATE\s+KIND\s+CALLS\s+INCLUDES\s+TPLS\s+SELF\s+TOTAL`, out)
+	assert.Regexp(t, `chart-with-lib-dep/templates/service.yaml\s+template\s+1\s+2\s+0\s`, out)
+	assert.Regexp(t, `common.service.tpl\s+define\s+1\s+1\s+0\s`, out)
+
+	f, err := os.Open(pprofFile)
+	require.NoError(t, err)
+	defer f.Close()
+	prof, err := profile.Parse(f)
+	require.NoError(t, err)
+	assert.NotEmpty(t, prof.Sample)
+}
+
 func TestTemplateVersionCompletion(t *testing.T) {
 	repoFile := "testdata/helmhome/helm/repositories.yaml"
 	repoCache := "testdata/helmhome/helm/repository"
@@ -206,3 +270,246 @@ func TestTemplateFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "template myname", true)
 	checkFileCompletion(t, "template myname mychart", false)
 }
+
+func TestTemplateLookupFixtures(t *testing.T) {
+	tests := []cmdTestCase{
+		{
+			name:   "lookup finds the objects of the fixtures",
+			cmd:    "template testdata/testcharts/chart-with-lookup --lookup-fixtures testdata/lookup-fixtures",
+			golden: "output/template-lookup-fixtures.txt",
+		},
+		{
+			name:   "lookup without fixtures",
+			cmd:    "template testdata/testcharts/chart-with-lookup",
+			golden: "output/template-lookup-no-fixtures.txt",
+		},
+		{
+			name:      "missing fixtures directory",
+			cmd:       "template testdata/testcharts/chart-with-lookup --lookup-fixtures testdata/missing-fixtures",
+			golden:    "output/template-lookup-missing-fixtures.txt",
+			wantError: true,
+		},
+	}
+	runTestCmd(t, tests)
+}
+
+func TestTemplateManifestSchemas(t *testing.T) {
+	tests := []cmdTestCase{
+		{
+			name:      "manifests validated against the bundled schemas",
+			cmd:       "template testdata/testcharts/chart-with-invalid-manifests --validate-schemas",
+			golden:    "output/template-validate-schemas.txt",
+			wantError: true,
+		},
+		{
+			name:      "manifests validated against extra CRDs",
+			cmd:       "template testdata/testcharts/chart-with-invalid-manifests --crd testdata/crontab-crd.yaml",
+			golden:    "output/template-validate-schemas-crd.txt",
+			wantError: true,
+		},
+		{
+			name:   "manifests validated against the OpenAPI spec of the kube version",
+			cmd:    "template testdata/testcharts/chart-with-invalid-manifests --openapi-spec testdata/openapi --kube-version 1.30.0",
+			golden: "output/template-validate-schemas-spec.txt",
+		},
+	}
+	runTestCmd(t, tests)
+}
+
+func TestTemplateMissingPlugin(t *testing.T) {
+	tests := []cmdTestCase{
+		{
+			name:      "chart requiring a template function plugin which is not installed",
+			cmd:       "template testdata/testcharts/chart-with-template-plugins",
+			golden:    "output/template-missing-plugin.txt",
+			wantError: true,
+		},
+	}
+	runTestCmd(t, tests)
+}
+
+func TestTemplateWatch(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+	writeFile("Chart.yaml", "apiVersion: v2\nname: watched\nversion: 0.1.0\n")
+	writeFile("values.yaml", "color: red\n")
+	writeFile("templates/a.yaml", "kind: ConfigMap\nmetadata:\n  name: a\ndata:\n  color: {{ .Values.color }}\n")
+	writeFile("templates/b.yaml", "kind: ConfigMap\nmetadata:\n  name: b\n")
+	writeFile("charts/sub/Chart.yaml", "apiVersion: v2\nname: sub\nversion: 0.1.0\n")
+	writeFile("charts/sub/templates/c.yaml", "kind: ConfigMap\nmetadata:\n  name: c\n")
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	require.NoError(t, os.WriteFile(valuesFile, []byte("color: red\n"), 0o644))
+
+	cfg := &action.Configuration{
+		Releases:     storageFixture(),
+		KubeClient:   &kubefake.PrintingKubeClient{Out: io.Discard},
+		Capabilities: chartutil.DefaultCapabilities,
+	}
+	client := action.NewInstall(cfg)
+	client.DryRun = true
+	client.DryRunOption = "true"
+	client.Replace = true
+	client.ReleaseName = "release-name"
+	valueOpts := &values.Options{ValueFiles: []string{valuesFile}}
+	var out, errOut bytes.Buffer
+	w := newTemplateWatcher(client, valueOpts, dir, false, &out, &errOut)
+
+	w.poll(t.Context())
+	require.Empty(t, errOut.String())
+	assert.Contains(t, out.String(), "# Source: watched/templates/a.yaml\n")
+	assert.Contains(t, out.String(), "# Source: watched/templates/b.yaml\n")
+	assert.Contains(t, out.String(), "# Source: watched/charts/sub/templates/c.yaml\n")
+	sub := w.subcharts["sub"].chart
+
+	// Without changes nothing is rendered.
+	out.Reset()
+	w.poll(t.Context())
+	assert.Empty(t, out.String())
+	assert.Empty(t, errOut.String())
+
+	// Only the changed document is printed, and the unchanged subchart is
+	// not loaded again.
+	require.NoError(t, os.WriteFile(valuesFile, []byte("color: blue\n"), 0o644))
+	w.poll(t.Context())
+	assert.Equal(t, "---\n# Source: watched/templates/a.yaml\nkind: ConfigMap\nmetadata:\n  name: a\ndata:\n  color: blue\n", out.String())
+	assert.Equal(t, "==> Changed: "+valuesFile+"\n", errOut.String())
+	assert.Same(t, sub, w.subcharts["sub"].chart)
+
+	// A changed subchart is loaded again.
+	out.Reset()
+	errOut.Reset()
+	writeFile("charts/sub/templates/c.yaml", "kind: ConfigMap\nmetadata:\n  name: changed\n")
+	w.poll(t.Context())
+	assert.Equal(t, "---\n# Source: watched/charts/sub/templates/c.yaml\nkind: ConfigMap\nmetadata:\n  name: changed\n", out.String())
+	assert.NotSame(t, sub, w.subcharts["sub"].chart)
+
+	// Errors are printed, and watching goes on.
+	out.Reset()
+	errOut.Reset()
+	writeFile("templates/b.yaml", "kind: ConfigMap\nmetadata:\n  name: {{ .Values.missing.name }}\n")
+	w.poll(t.Context())
+	assert.Empty(t, out.String())
+	assert.Contains(t, errOut.String(), "Error: ")
+	assert.Contains(t, errOut.String(), "watched/templates/b.yaml:3")
+
+	// Removed documents are reported.
+	errOut.Reset()
+	require.NoError(t, os.Remove(filepath.Join(dir, "templates/b.yaml")))
+	w.poll(t.Context())
+	assert.Empty(t, out.String())
+	assert.Contains(t, errOut.String(), "==> Removed: watched/templates/b.yaml\n")
+}
+
+func TestTemplateWatchRequiresChartDirectory(t *testing.T) {
+	tests := []cmdTestCase{
+		{
+			name:      "watch a chart archive",
+			cmd:       "template --watch testdata/testcharts/compressedchart-0.1.0.tgz",
+			golden:    "output/template-watch-archive.txt",
+			wantError: true,
+		},
+		{
+			name:      "watch with output dir",
+			cmd:       fmt.Sprintf("template --watch '%s' --output-dir %s", chartPath, t.TempDir()),
+			golden:    "output/template-watch-output-dir.txt",
+			wantError: true,
+		},
+	}
+	runTestCmd(t, tests)
+}
+
+func TestTemplateSnapshots(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snapshots")
+	chart := "testdata/testcharts/chart-with-unittests"
+	deployment := filepath.Join(dir, "chart-with-unittests", "templates", "deployment.yaml", "apps_v1_Deployment_release-name-chart-with-unittests.snap.yaml")
+	template := func(flags string) (string, error) {
+		_, out, err := executeActionCommand(fmt.Sprintf("template %s --snapshot-dir %s %s", chart, dir, flags))
+		return out, err
+	}
+
+	// Without snapshots, --snapshot-check fails.
+	out, err := template("--snapshot-check")
+	require.ErrorContains(t, err, "2 snapshot(s) in "+dir+" do not match the rendered documents")
+	assert.Contains(t, out, "+++ rendered/chart-with-unittests/templates/service.yaml/v1_Service_release-name-chart-with-unittests.snap.yaml\n")
+	assert.NoDirExists(t, dir)
+
+	// The first run writes the snapshots.
+	out, err = template("")
+	require.NoError(t, err)
+	assert.Contains(t, out, "==> 2 snapshot(s) written to "+dir+", 2 changed\n")
+	assert.Contains(t, out, "kind: Deployment")
+	data, err := os.ReadFile(deployment)
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app.kubernetes.io/name: chart-with-unittests
+  name: release-name-chart-with-unittests
+  namespace: default
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - image: nginx:1.27
+        name: app
+`, string(data))
+
+	// Later runs compare to them.
+	out, err = template("--snapshot-check")
+	require.NoError(t, err)
+	assert.Contains(t, out, "==> 2 snapshot(s) in "+dir+" match the rendered documents\n")
+
+	out, err = template("--set replicaCount=2")
+	require.ErrorContains(t, err, "1 snapshot(s) in "+dir+" do not match the rendered documents. Use --snapshot-update to accept the changes")
+	assert.Contains(t, out, "@@ -6,7 +6,7 @@\n   name: release-name-chart-with-unittests\n   namespace: default\n spec:\n-  replicas: 1\n+  replicas: 2\n")
+
+	// Updating accepts the changes, and removes the snapshots of documents
+	// which are no longer rendered.
+	out, err = template("--snapshot-update --set replicaCount=2")
+	require.NoError(t, err)
+	assert.Contains(t, out, "==> 2 snapshot(s) written to "+dir+", 1 changed\n")
+	_, err = template("--snapshot-check --set replicaCount=2")
+	require.NoError(t, err)
+
+	_, err = template("--snapshot-update --set enabled=false")
+	require.NoError(t, err)
+	assert.NoDirExists(t, filepath.Dir(deployment))
+	assert.DirExists(t, filepath.Join(dir, "chart-with-unittests", "templates", "service.yaml"))
+}
+
+func TestTemplateSnapshotsKeepOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	other := filepath.Join(dir, "values.yaml")
+	require.NoError(t, os.WriteFile(other, []byte("replicaCount: 1\n"), 0o644))
+
+	for _, flags := range []string{"", "--snapshot-update --set enabled=false", "--snapshot-check --set enabled=false"} {
+		_, _, err := executeActionCommand(fmt.Sprintf("template testdata/testcharts/chart-with-unittests --snapshot-dir %s %s", dir, flags))
+		require.NoError(t, err, flags)
+	}
+	assert.FileExists(t, other)
+}
+
+func TestTemplateSnapshotFlags(t *testing.T) {
+	tests := []cmdTestCase{
+		{
+			name:      "check without a snapshot directory",
+			cmd:       "template testdata/testcharts/chart-with-unittests --snapshot-check",
+			golden:    "output/template-snapshot-no-dir.txt",
+			wantError: true,
+		},
+		{
+			name:      "check and update",
+			cmd:       "template testdata/testcharts/chart-with-unittests --snapshot-dir snapshots --snapshot-check --snapshot-update",
+			golden:    "output/template-snapshot-check-update.txt",
+			wantError: true,
+		},
+	}
+	runTestCmd(t, tests)
+}
@@ -0,0 +1,435 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/copystructure"
+
+	"helm.sh/helm/v4/internal/sympath"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chart/loader"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	v2loader "helm.sh/helm/v4/pkg/chart/v2/loader"
+	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/ignore"
+	releaseutil "helm.sh/helm/v4/pkg/release/util"
+)
+
+// watchInterval is how often the watched files are checked for changes.
+var watchInterval = 500 * time.Millisecond
+
+// fileState is what a change of a watched file is detected by.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// watchedSubchart is a subchart in the charts/ directory of the watched chart,
+// kept loaded for as long as its files do not change.
+type watchedSubchart struct {
+	fingerprint string
+	chart       *chart.Chart
+}
+
+// templateWatcher renders a chart directory each time the chart, its
+// subcharts or the values files change, and prints the documents whose
+// rendering changed.
+type templateWatcher struct {
+	client    *action.Install
+	valueOpts *values.Options
+	chartPath string
+	skipTests bool
+	out       io.Writer
+	errOut    io.Writer
+
+	// files is the state of the watched files, keyed by their path.
+	files     map[string]fileState
+	subcharts map[string]*watchedSubchart
+	documents map[string]string
+	lastErr   string
+}
+
+func newTemplateWatcher(client *action.Install, valueOpts *values.Options, chartPath string, skipTests bool, out, errOut io.Writer) *templateWatcher {
+	return &templateWatcher{
+		client:    client,
+		valueOpts: valueOpts,
+		chartPath: filepath.Clean(chartPath),
+		skipTests: skipTests,
+		out:       out,
+		errOut:    errOut,
+		subcharts: map[string]*watchedSubchart{},
+		documents: map[string]string{},
+	}
+}
+
+// run renders the chart, then renders it again on every change until the
+// context is done.
+func (w *templateWatcher) run(ctx context.Context) error {
+	w.poll(ctx)
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.poll(ctx)
+		}
+	}
+}
+
+// poll checks the watched files and renders the chart when any of them
+// changed since the last check.
+func (w *templateWatcher) poll(ctx context.Context) {
+	files, err := w.scan()
+	if err != nil {
+		w.reportError(err)
+		return
+	}
+	if w.files != nil {
+		changed := changedFiles(w.files, files)
+		if len(changed) == 0 {
+			return
+		}
+		fmt.Fprintf(w.errOut, "==> Changed: %s\n", strings.Join(changed, ", "))
+	}
+	w.files = files
+	if err := w.render(ctx); err != nil {
+		w.reportError(err)
+		return
+	}
+	w.lastErr = ""
+}
+
+// reportError prints an error unless it is the one printed last, so a broken
+// chart does not print the same error on every check.
+func (w *templateWatcher) reportError(err error) {
+	if err.Error() == w.lastErr {
+		return
+	}
+	w.lastErr = err.Error()
+	fmt.Fprintf(w.errOut, "Error: %s\n", err)
+}
+
+// scan returns the state of the watched files: the files of the chart that
+// are not ignored by its .helmignore file, and the values files.
+func (w *templateWatcher) scan() (map[string]fileState, error) {
+	rules := ignore.Empty()
+	ifile := filepath.Join(w.chartPath, ignore.HelmIgnore)
+	if _, err := os.Stat(ifile); err == nil {
+		r, err := ignore.ParseFile(ifile)
+		if err != nil {
+			return nil, err
+		}
+		rules = r
+	}
+	rules.AddDefaults()
+
+	files := map[string]fileState{}
+	walk := func(name string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(w.chartPath, name)
+		if err != nil || rel == "." {
+			return err
+		}
+		n := filepath.ToSlash(rel)
+		if fi.IsDir() {
+			if rules.Ignore(n, fi) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rules.Ignore(n, fi) {
+			return nil
+		}
+		files[name] = fileState{size: fi.Size(), modTime: fi.ModTime()}
+		return nil
+	}
+	if err := sympath.Walk(w.chartPath, walk); err != nil {
+		return nil, err
+	}
+
+	// Values files that are not local files, e.g. URLs, are not watched. A
+	// missing values file is reported by the render.
+	for _, f := range w.valueOpts.ValueFiles {
+		if fi, err := os.Stat(f); err == nil && fi.Mode().IsRegular() {
+			files[f] = fileState{size: fi.Size(), modTime: fi.ModTime()}
+		}
+	}
+	return files, nil
+}
+
+// changedFiles returns the sorted paths of the files that were added,
+// removed or modified.
+func changedFiles(before, after map[string]fileState) []string {
+	var changed []string
+	for name, st := range after {
+		if prev, ok := before[name]; !ok || prev != st {
+			changed = append(changed, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// render renders the chart and prints the documents that changed since the
+// previous render.
+func (w *templateWatcher) render(ctx context.Context) error {
+	vals, err := w.valueOpts.MergeValues(getter.All(settings))
+	if err != nil {
+		return err
+	}
+	w.client.ValuesFrom = w.valueOpts.ValuesFrom
+
+	chrt, err := w.loadChart()
+	if err != nil {
+		return err
+	}
+	if err := checkIfInstallable(chrt); err != nil {
+		return err
+	}
+	if req := chrt.Metadata.Dependencies; req != nil {
+		if err := action.CheckDependencies(chrt, req); err != nil {
+			return fmt.Errorf("an error occurred while checking for chart dependencies. You may need to run `helm dependency build` to fetch missing dependencies: %w", err)
+		}
+	}
+
+	w.client.ClientOnly = true
+	rel, err := w.client.RunWithContext(ctx, chrt, vals)
+	if err != nil {
+		return err
+	}
+
+	var manifests bytes.Buffer
+	fmt.Fprintln(&manifests, strings.TrimSpace(rel.Manifest))
+	if !w.client.DisableHooks {
+		for _, m := range rel.Hooks {
+			if w.skipTests && isTestHook(m) {
+				continue
+			}
+			fmt.Fprintf(&manifests, "---\n# Source: %s\n%s\n", m.Path, m.Manifest)
+		}
+	}
+
+	// Templates can render several documents, which are told apart by their
+	// position among the documents of the template.
+	documents := map[string]string{}
+	seen := map[string]int{}
+	var keys []string
+	for _, doc := range releaseutil.SplitDocuments(manifests.String()) {
+		key := documentKey(doc.Content)
+		seen[key]++
+		if n := seen[key]; n > 1 {
+			key = fmt.Sprintf("%s (%d)", key, n)
+		}
+		documents[key] = doc.Content
+		keys = append(keys, key)
+	}
+
+	changed := 0
+	for _, key := range keys {
+		if prev, ok := w.documents[key]; ok && prev == documents[key] {
+			continue
+		}
+		fmt.Fprintf(w.out, "---\n%s", documents[key])
+		changed++
+	}
+	var removed []string
+	for key := range w.documents {
+		if _, ok := documents[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+	for _, key := range removed {
+		fmt.Fprintf(w.errOut, "==> Removed: %s\n", key)
+	}
+	if changed == 0 && len(removed) == 0 {
+		fmt.Fprintln(w.errOut, "==> No changes to the rendered documents")
+	}
+	w.documents = documents
+	return nil
+}
+
+// documentKey identifies a rendered document by the template it comes from.
+func documentKey(doc string) string {
+	for line := range strings.SplitSeq(doc, "\n") {
+		if src, ok := strings.CutPrefix(line, "# Source: "); ok {
+			return strings.TrimSpace(src)
+		}
+	}
+	return ""
+}
+
+// loadChart loads the chart directory. The subcharts in the charts/ directory
+// are only loaded again when their files changed.
+func (w *templateWatcher) loadChart() (*chart.Chart, error) {
+	files, err := v2loader.LoadDirFiles(w.chartPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var chartFiles []*v2loader.BufferedFile
+	subchartFiles := map[string][]*v2loader.BufferedFile{}
+	for _, f := range files {
+		if rest, ok := strings.CutPrefix(f.Name, "charts/"); ok && filepath.Ext(f.Name) != ".prov" {
+			name := strings.SplitN(rest, "/", 2)[0]
+			if strings.IndexAny(name, "_.") != 0 {
+				subchartFiles[name] = append(subchartFiles[name], f)
+			}
+			continue
+		}
+		chartFiles = append(chartFiles, f)
+	}
+
+	c, err := loader.LoadFiles(chartFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(subchartFiles))
+	for name := range subchartFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	subcharts := map[string]*watchedSubchart{}
+	var deps []*chart.Chart
+	for _, name := range names {
+		fingerprint := fingerprint(subchartFiles[name])
+		sub, ok := w.subcharts[name]
+		if !ok || sub.fingerprint != fingerprint {
+			sc, err := loadSubchart(name, subchartFiles[name])
+			if err != nil {
+				return nil, fmt.Errorf("error unpacking subchart %s in %s: %w", name, c.Name(), err)
+			}
+			sub = &watchedSubchart{fingerprint: fingerprint, chart: sc}
+		}
+		subcharts[name] = sub
+		// Rendering processes the dependencies of the charts in place, so
+		// each render gets its own copy of the loaded subchart.
+		dep, err := copyChart(sub.chart)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, dep)
+	}
+	w.subcharts = subcharts
+	c.SetDependencies(deps...)
+	return c, nil
+}
+
+// fingerprint summarizes the given files.
+func fingerprint(files []*v2loader.BufferedFile) string {
+	h := sha256.New()
+	for _, f := range files {
+		fmt.Fprintf(h, "%s\x00%d\x00", f.Name, len(f.Data))
+		h.Write(f.Data)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// loadSubchart loads a subchart from the files of the charts/ directory of
+// its parent, the way the chart loader does.
+func loadSubchart(name string, files []*v2loader.BufferedFile) (*chart.Chart, error) {
+	if filepath.Ext(name) == ".tgz" {
+		if len(files) != 1 || files[0].Name != "charts/"+name {
+			return nil, fmt.Errorf("expected %s to be an archive", name)
+		}
+		return loader.LoadArchive(bytes.NewReader(files[0].Data))
+	}
+	prefix := "charts/" + name + "/"
+	subFiles := make([]*v2loader.BufferedFile, 0, len(files))
+	for _, f := range files {
+		if n, ok := strings.CutPrefix(f.Name, prefix); ok {
+			subFiles = append(subFiles, &v2loader.BufferedFile{Name: n, Data: f.Data})
+		}
+	}
+	return loader.LoadFiles(subFiles)
+}
+
+// copyChart copies the parts of a chart, and of its dependencies, that
+// processing the dependencies of a chart modifies.
+func copyChart(c *chart.Chart) (*chart.Chart, error) {
+	out := *c
+	if c.Metadata != nil {
+		md := *c.Metadata
+		md.Dependencies = make([]*chart.Dependency, 0, len(c.Metadata.Dependencies))
+		for _, d := range c.Metadata.Dependencies {
+			if d == nil {
+				md.Dependencies = append(md.Dependencies, nil)
+				continue
+			}
+			dep := *d
+			md.Dependencies = append(md.Dependencies, &dep)
+		}
+		out.Metadata = &md
+	}
+	if c.Values != nil {
+		v, err := copystructure.Copy(c.Values)
+		if err != nil {
+			return nil, fmt.Errorf("cannot copy the values of chart %q: %w", c.Name(), err)
+		}
+		out.Values = v.(map[string]interface{})
+	}
+	deps := make([]*chart.Chart, 0, len(c.Dependencies()))
+	for _, d := range c.Dependencies() {
+		dep, err := copyChart(d)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, dep)
+	}
+	out.SetDependencies(deps...)
+	return &out, nil
+}
+
+// runTemplateWatch watches the chart directory given in the arguments until
+// the context is done.
+func runTemplateWatch(ctx context.Context, args []string, client *action.Install, valueOpts *values.Options, skipTests bool, out, errOut io.Writer) error {
+	name, chartRef, err := client.NameAndChart(args)
+	if err != nil {
+		return err
+	}
+	if fi, err := os.Stat(chartRef); err != nil || !fi.IsDir() {
+		return errors.New("--watch requires the path to a chart directory")
+	}
+	client.ReleaseName = name
+	client.Namespace = settings.Namespace()
+	return newTemplateWatcher(client, valueOpts, chartRef, skipTests, out, errOut).run(ctx)
+}
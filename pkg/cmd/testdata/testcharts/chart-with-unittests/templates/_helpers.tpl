@@ -0,0 +1,3 @@
+{{- define "chart-with-unittests.fullname" -}}
+{{ .Release.Name }}-{{ .Chart.Name }}
+{{- end -}}
@@ -0,0 +1,228 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/unittest"
+)
+
+const unittestDesc = `
+This command runs the unit test suites stored in the 'tests/' directory of a
+chart. The templates are rendered locally, no cluster is needed.
+
+Each '*_test.yaml' file is a suite. It sets the values, capabilities and release
+info the templates are rendered with, and asserts on the rendered documents:
+
+	suite: deployment
+	templates:
+	  - templates/deployment.yaml
+	release:
+	  name: my-release
+	capabilities:
+	  kubeVersion: v1.30.0
+	set:
+	  replicaCount: 3
+	tests:
+	  - it: renders a deployment
+	    asserts:
+	      - isKind:
+	          of: Deployment
+	      - isAPIVersion:
+	          of: apps/v1
+	      - equal:
+	          path: spec.replicas
+	          value: 3
+	      - matchRegex:
+	          path: metadata.name
+	          pattern: ^my-release-
+	  - it: renders nothing when disabled
+	    set:
+	      enabled: false
+	    asserts:
+	      - hasDocuments:
+	          count: 0
+	  - it: requires an image
+	    set:
+	      image: ""
+	    asserts:
+	      - failedTemplate:
+	          errorMessage: image is required
+
+Every assertion can be restricted to a template with 'template' and to a
+document with 'documentIndex', and negated with 'not: true'. Only the templates
+the assertions of a test apply to are rendered, so a failing template does not
+fail the tests of the others.
+
+The 'tests/' directory is ignored when packaging the chart.
+`
+
+func newUnittestCmd(out io.Writer) *cobra.Command {
+	client := action.NewUnitTest()
+	var kubeVersion string
+	var junitReport string
+
+	cmd := &cobra.Command{
+		Use:   "unittest [CHART...]",
+		Short: "run the unit test suites of a chart",
+		Long:  unittestDesc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			paths := []string{"."}
+			if len(args) > 0 {
+				paths = args
+			}
+
+			if kubeVersion != "" {
+				parsedKubeVersion, err := chartutil.ParseKubeVersion(kubeVersion)
+				if err != nil {
+					return fmt.Errorf("invalid kube version '%s': %s", kubeVersion, err)
+				}
+				client.KubeVersion = parsedKubeVersion
+			}
+
+			results, err := client.Run(paths)
+			if err != nil {
+				return err
+			}
+
+			if junitReport != "" {
+				if err := writeReportFile(junitReport, func(w io.Writer) error {
+					return writeUnittestJUnitReport(w, results)
+				}); err != nil {
+					return err
+				}
+			}
+
+			suites, failedSuites, tests, failedTests := writeUnittestResults(out, results)
+			summary := fmt.Sprintf("%d suite(s) and %d test(s) run, %d suite(s) and %d test(s) failed", suites, tests, failedSuites, failedTests)
+			if failedSuites > 0 {
+				return errors.New(summary)
+			}
+			fmt.Fprintln(out, summary)
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&kubeVersion, "kube-version", "", "Kubernetes version used for Capabilities.KubeVersion, unless set by a suite")
+	f.StringVar(&junitReport, "junit-report", "", "write the test results as a JUnit XML report to the given file")
+
+	return cmd
+}
+
+// writeUnittestResults prints the results of the suites of each chart, and
+// returns the numbers of suites and tests run and failed.
+func writeUnittestResults(out io.Writer, results []*action.UnitTestResult) (suites, failedSuites, tests, failedTests int) {
+	for _, result := range results {
+		fmt.Fprintf(out, "==> Testing %s\n", result.Chart)
+		if len(result.Suites) == 0 {
+			fmt.Fprintf(out, "no test suites found in %s\n", filepath.Join(result.Chart, unittest.TestsDir))
+		}
+		for _, s := range result.Suites {
+			suites++
+			status := "PASS"
+			if s.Failed() {
+				status = "FAIL"
+				failedSuites++
+			}
+			fmt.Fprintf(out, "%s %s (%s)\n", status, s.Name, suiteFile(result.Chart, s.File))
+			if s.Err != nil {
+				fmt.Fprintf(out, "    Error: %s\n", s.Err)
+			}
+			for _, t := range s.Tests {
+				tests++
+				status := "PASS"
+				if t.Failed() {
+					status = "FAIL"
+					failedTests++
+				}
+				fmt.Fprintf(out, "  %s %s\n", status, t.Name)
+				for _, f := range t.Failures {
+					fmt.Fprintf(out, "      %s\n", f)
+				}
+			}
+		}
+		fmt.Fprintln(out)
+	}
+	return suites, failedSuites, tests, failedTests
+}
+
+// suiteFile returns the path of a suite file relative to its chart.
+func suiteFile(chartDir, file string) string {
+	if rel, err := filepath.Rel(chartDir, file); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return file
+}
+
+func writeUnittestJUnitReport(out io.Writer, results []*action.UnitTestResult) error {
+	var report junitTestSuites
+	for _, result := range results {
+		chartName := filepath.Base(filepath.Clean(result.Chart))
+		for _, s := range result.Suites {
+			suite := junitTestSuite{
+				Name: fmt.Sprintf("%s/%s", chartName, suiteFile(result.Chart, s.File)),
+				Time: fmt.Sprintf("%.3f", s.Duration.Seconds()),
+			}
+			classname := fmt.Sprintf("%s.%s", chartName, s.Name)
+			if s.Err != nil {
+				suite.Cases = append(suite.Cases, junitTestCase{
+					Name:      s.Name,
+					Classname: classname,
+					Time:      "0.000",
+					Failure:   &junitFailure{Message: s.Err.Error(), Type: "SuiteError"},
+				})
+				suite.Failures++
+			}
+			for _, t := range s.Tests {
+				tc := junitTestCase{
+					Name:      t.Name,
+					Classname: classname,
+					Time:      fmt.Sprintf("%.3f", t.Duration.Seconds()),
+				}
+				if t.Failed() {
+					tc.Failure = &junitFailure{Message: strings.Join(t.Failures, "\n"), Type: "AssertionFailed"}
+					suite.Failures++
+				}
+				suite.Cases = append(suite.Cases, tc)
+			}
+			suite.Tests = len(suite.Cases)
+			report.Suites = append(report.Suites, suite)
+		}
+	}
+
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(out)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(out)
+	return err
+}
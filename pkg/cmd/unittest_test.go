@@ -0,0 +1,75 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnittestCmd(t *testing.T) {
+	tests := []cmdTestCase{
+		{
+			name:   "passing suites",
+			cmd:    "unittest testdata/testcharts/chart-with-unittests",
+			golden: "output/unittest.txt",
+		},
+		{
+			name:      "failing suites",
+			cmd:       "unittest testdata/testcharts/chart-with-failing-unittests",
+			golden:    "output/unittest-failing.txt",
+			wantError: true,
+		},
+		{
+			name:   "chart without suites",
+			cmd:    "unittest testdata/testcharts/empty",
+			golden: "output/unittest-no-suites.txt",
+		},
+	}
+	runTestCmd(t, tests)
+}
+
+func TestUnittestCmdJUnitReport(t *testing.T) {
+	report := filepath.Join(t.TempDir(), "report.xml")
+	_, _, err := executeActionCommand("unittest testdata/testcharts/chart-with-failing-unittests --junit-report " + report)
+	require.Error(t, err)
+
+	data, err := os.ReadFile(report)
+	require.NoError(t, err)
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(data, &suites))
+	require.Len(t, suites.Suites, 2)
+
+	broken := suites.Suites[0]
+	assert.Equal(t, "chart-with-failing-unittests/tests/broken_test.yaml", broken.Name)
+	assert.Equal(t, 1, broken.Failures)
+	require.Len(t, broken.Cases, 1)
+	assert.Equal(t, "SuiteError", broken.Cases[0].Failure.Type)
+
+	service := suites.Suites[1]
+	assert.Equal(t, 3, service.Tests)
+	assert.Equal(t, 2, service.Failures)
+	assert.Equal(t, "chart-with-failing-unittests.service", service.Cases[0].Classname)
+	assert.Nil(t, service.Cases[0].Failure)
+	require.NotNil(t, service.Cases[1].Failure)
+	assert.Contains(t, service.Cases[1].Failure.Message, "expected spec.ports[0].port to equal 80, got 8080")
+}
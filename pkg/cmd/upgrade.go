@@ -30,7 +30,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/pkg/action"
-	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	"helm.sh/helm/v4/pkg/chart/loader"
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/cmd/require"
@@ -67,6 +67,16 @@ set for a key called 'foo', the 'newbar' value would take precedence:
 
     $ helm upgrade --set foo=bar --set foo=newbar redis ./redis
 
+Values kept in a Secret or ConfigMap in the namespace of the release can be
+read with '--values-from'. They are merged after the '--values' files and
+before the '--set' flags:
+
+    $ helm upgrade --values-from configmap/redis-overrides:prod.yaml redis ./redis
+
+Values files encrypted with SOPS, or encrypted with age as a whole, are
+decrypted in memory with the keys sops would use, e.g. the age identities of
+SOPS_AGE_KEY or SOPS_AGE_KEY_FILE.
+
 You can update the values for an existing release with this command as well via the
 '--reuse-values' flag. The 'RELEASE' and 'CHART' arguments should be set to the original
 parameters, and existing values will be merged with any values set via '--values'/'-f'
@@ -84,6 +94,7 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	valueOpts := &values.Options{}
 	var outfmt output.Format
 	var createNamespace bool
+	var sourceMap bool
 
 	cmd := &cobra.Command{
 		Use:   "upgrade [RELEASE] [CHART]",
@@ -101,6 +112,11 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
 			client.Namespace = settings.Namespace()
+			valueOpts.Reader = action.NewClusterValuesReader(cfg, client.Namespace)
+			cfg.RenderSourceMap = sourceMap
+			defer func() {
+				cfg.RenderSourceMap = false
+			}()
 
 			registryClient, err := newRegistryClient(client.CertFile, client.KeyFile, client.CaFile,
 				client.InsecureSkipTLSverify, client.PlainHTTP, client.Username, client.Password)
@@ -192,6 +208,9 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if client.ValuesFrom, err = values.NormalizeReferences(valueOpts.ValuesFrom); err != nil {
+				return err
+			}
 
 			// Check chart dependencies to make sure all are present in /charts
 			ch, err := loader.Load(chartPath)
@@ -299,6 +318,8 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.TakeOwnership, "take-ownership", false, "if set, upgrade will ignore the check for helm annotations and take ownership of the existing resources")
 	addChartPathOptionsFlags(f, &client.ChartPathOptions)
 	addValueOptionsFlags(f, valueOpts)
+	addValuesFromFlag(f, valueOpts)
+	addSourceMapFlag(f, &sourceMap)
 	bindOutputFlag(cmd, &outfmt)
 	bindPostRenderFlag(cmd, &client.PostRenderer, settings)
 	AddWaitFlag(cmd, &client.WaitStrategy)
@@ -26,8 +26,12 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"text/template"
+	"time"
 
+	"github.com/mitchellh/copystructure"
 	"k8s.io/client-go/rest"
 
 	chart "helm.sh/helm/v4/pkg/chart/v2"
@@ -59,6 +63,33 @@ type Engine struct {
 	EnableDNS bool
 	// CustomTemplateFuncs is defined by users to provide custom template funcs
 	CustomTemplateFuncs template.FuncMap
+	// SourceMap, when set, is filled with the template lines the lines of the
+	// rendered templates come from.
+	SourceMap SourceMap
+	// Profile, when set, records the time spent rendering each template.
+	Profile *Profile
+	// Concurrency is the maximum number of templates executed at the same
+	// time. With 0 or 1, the templates are executed one after the other, as
+	// they are when a Profile is recorded.
+	//
+	// Templates executed concurrently each get a copy of the values, so that
+	// the changes a template makes to .Values, e.g. with 'set', are not seen
+	// by the others, and CustomTemplateFuncs must be safe for concurrent use.
+	// Templates executed one after the other share the values, and see the
+	// changes the templates before them made.
+	Concurrency int
+	// PluginDirs are the directories the templatefuncs/v1 plugins required by
+	// the charts are loaded from.
+	PluginDirs []string
+	// PluginTimeout bounds each call of a template function of a plugin,
+	// DefaultPluginTimeout when 0.
+	PluginTimeout time.Duration
+	// ParseCache, when set, keeps the parse trees of the template files for
+	// the next renders, see ParseCache.
+	ParseCache *ParseCache
+	// pluginFuncs are the template functions of the plugins required by the
+	// chart being rendered.
+	pluginFuncs template.FuncMap
 }
 
 // New creates a new instance of Engine using the passed in rest config.
@@ -69,6 +100,14 @@ func New(config *rest.Config) Engine {
 	}
 }
 
+// NewWithClientProvider creates a new instance of Engine whose template
+// functions interacting with the cluster use the clients of clientProvider.
+func NewWithClientProvider(clientProvider ClientProvider) Engine {
+	return Engine{
+		clientProvider: &clientProvider,
+	}
+}
+
 // Render takes a chart, optional values, and value overrides, and attempts to render the Go templates.
 //
 // Render can be called repeatedly on the same engine.
@@ -89,6 +128,11 @@ func New(config *rest.Config) Engine {
 // section contains a value named "bar", that value will be passed on to the
 // bar chart during render time.
 func (e Engine) Render(chrt *chart.Chart, values chartutil.Values) (map[string]string, error) {
+	funcs, err := pluginFuncs(chrt, e.PluginDirs, e.PluginTimeout)
+	if err != nil {
+		return nil, err
+	}
+	e.pluginFuncs = funcs
 	tmap := allTemplates(chrt, values)
 	return e.render(tmap)
 }
@@ -141,7 +185,7 @@ func warnWrap(warn string) string {
 
 // 'include' needs to be defined in the scope of a 'tpl' template as
 // well as regular file-loaded templates.
-func includeFun(t *template.Template, includedNames map[string]int) func(string, interface{}) (string, error) {
+func includeFun(t *template.Template, includedNames map[string]int, profile *Profile) func(string, interface{}) (string, error) {
 	return func(name string, data interface{}) (string, error) {
 		var buf strings.Builder
 		if v, ok := includedNames[name]; ok {
@@ -154,6 +198,15 @@ func includeFun(t *template.Template, includedNames map[string]int) func(string,
 		} else {
 			includedNames[name] = 1
 		}
+		if profile != nil {
+			profile.call("include")
+			var file string
+			if tt := t.Lookup(name); tt != nil && tt.Tree != nil {
+				file = tt.Tree.ParseName
+			}
+			profile.push(name, true, file)
+			defer profile.pop()
+		}
 		err := t.ExecuteTemplate(&buf, name, data)
 		includedNames[name]--
 		return buf.String(), err
@@ -162,8 +215,11 @@ func includeFun(t *template.Template, includedNames map[string]int) func(string,
 
 // As does 'tpl', so that nested calls to 'tpl' see the templates
 // defined by their enclosing contexts.
-func tplFun(parent *template.Template, includedNames map[string]int, strict bool) func(string, interface{}) (string, error) {
+func tplFun(parent *template.Template, includedNames map[string]int, strict bool, profile *Profile) func(string, interface{}) (string, error) {
 	return func(tpl string, vals interface{}) (string, error) {
+		if profile != nil {
+			profile.call("tpl")
+		}
 		t, err := parent.Clone()
 		if err != nil {
 			return "", fmt.Errorf("cannot clone template: %w", err)
@@ -181,8 +237,8 @@ func tplFun(parent *template.Template, includedNames map[string]int, strict bool
 		// Re-inject 'include' so that it can close over our clone of t;
 		// this lets any 'define's inside tpl be 'include'd.
 		t.Funcs(template.FuncMap{
-			"include": includeFun(t, includedNames),
-			"tpl":     tplFun(t, includedNames, strict),
+			"include": includeFun(t, includedNames, profile),
+			"tpl":     tplFun(t, includedNames, strict, profile),
 		})
 
 		// We need a .New template, as template text which is just blanks
@@ -207,13 +263,20 @@ func tplFun(parent *template.Template, includedNames map[string]int, strict bool
 }
 
 // initFunMap creates the Engine's FuncMap and adds context-specific functions.
-func (e Engine) initFunMap(t *template.Template) {
+// It returns the functions set on the template.
+func (e Engine) initFunMap(t *template.Template, sources *sourceRecorder) template.FuncMap {
 	funcMap := funcMap()
 	includedNames := make(map[string]int)
 
 	// Add the template-rendering functions here so we can close over t.
-	funcMap["include"] = includeFun(t, includedNames)
-	funcMap["tpl"] = tplFun(t, includedNames, e.Strict)
+	funcMap["include"] = includeFun(t, includedNames, e.Profile)
+	funcMap["tpl"] = tplFun(t, includedNames, e.Strict, e.Profile)
+
+	if sources != nil {
+		funcMap[sourceMarkFunc] = sources.mark
+		funcMap["include"] = sources.nest(funcMap["include"].(func(string, interface{}) (string, error)))
+		funcMap["tpl"] = sources.nest(funcMap["tpl"].(func(string, interface{}) (string, error)))
+	}
 
 	// Add the `required` function here so we can use lintMode
 	funcMap["required"] = func(warn string, val interface{}) (interface{}, error) {
@@ -248,8 +311,8 @@ func (e Engine) initFunMap(t *template.Template) {
 	}
 
 	// If we are not linting and have a cluster connection, provide a Kubernetes-backed
-	// implementation.
-	if !e.LintMode && e.clientProvider != nil {
+	// implementation. Lookup fixtures do not need a cluster and are used when linting.
+	if e.clientProvider != nil && (!e.LintMode || isOffline(*e.clientProvider)) {
 		funcMap["lookup"] = newLookupFunction(*e.clientProvider)
 	}
 
@@ -261,10 +324,26 @@ func (e Engine) initFunMap(t *template.Template) {
 		}
 	}
 
+	// Set the template funcs of the plugins required by the chart
+	maps.Copy(funcMap, e.pluginFuncs)
+
 	// Set custom template funcs
 	maps.Copy(funcMap, e.CustomTemplateFuncs)
 
 	t.Funcs(funcMap)
+	return funcMap
+}
+
+// setMissingKey sets the missingkey option of a template for the strict mode
+// of the engine.
+func (e Engine) setMissingKey(t *template.Template) {
+	if e.Strict {
+		t.Option("missingkey=error")
+	} else {
+		// Not that zero will attempt to add default values for types it knows,
+		// but will still emit <no value> for others. We mitigate that later.
+		t.Option("missingkey=zero")
+	}
 }
 
 // render takes a map of templates/values and renders them.
@@ -282,51 +361,171 @@ func (e Engine) render(tpls map[string]renderable) (rendered map[string]string,
 		}
 	}()
 	t := template.New("gotpl")
-	if e.Strict {
-		t.Option("missingkey=error")
-	} else {
-		// Not that zero will attempt to add default values for types it knows,
-		// but will still emit <no value> for others. We mitigate that later.
-		t.Option("missingkey=zero")
-	}
+	e.setMissingKey(t)
 
-	e.initFunMap(t)
+	var sources *sourceRecorder
+	if e.SourceMap != nil {
+		sources = new(sourceRecorder)
+	}
+	funcs := e.initFunMap(t, sources)
 
 	// We want to parse the templates in a predictable order. The order favors
 	// higher-level (in file system) templates over deeply nested templates.
 	keys := sortTemplates(tpls)
 
+	// The parse trees of files parsed by earlier renders are reused.
+	parser := newTemplateParser(e.ParseCache, funcs, sources != nil)
 	for _, filename := range keys {
-		r := tpls[filename]
-		if _, err := t.New(filename).Parse(r.tpl); err != nil {
+		trees, err := parser.parse(filename, tpls[filename].tpl)
+		if err != nil {
 			return map[string]string{}, cleanupParseError(filename, err)
 		}
+		for name, tree := range trees {
+			if _, err := t.AddParseTree(name, tree); err != nil {
+				return map[string]string{}, cleanupParseError(filename, err)
+			}
+		}
 	}
 
-	rendered = make(map[string]string, len(keys))
+	// Don't render partials. We don't care out the direct output of partials.
+	// They are only included from other templates.
+	names := make([]string, 0, len(keys))
 	for _, filename := range keys {
-		// Don't render partials. We don't care out the direct output of partials.
-		// They are only included from other templates.
-		if strings.HasPrefix(path.Base(filename), "_") {
-			continue
-		}
-		// At render time, add information about the template that is being rendered.
-		vals := tpls[filename].vals
-		vals["Template"] = chartutil.Values{"Name": filename, "BasePath": tpls[filename].basePath}
-		var buf strings.Builder
-		if err := t.ExecuteTemplate(&buf, filename, vals); err != nil {
-			return map[string]string{}, reformatExecErrorMsg(filename, err)
+		if !strings.HasPrefix(path.Base(filename), "_") {
+			names = append(names, filename)
 		}
+	}
 
-		// Work around the issue where Go will emit "<no value>" even if Options(missing=zero)
-		// is set. Since missing=error will never get here, we do not need to handle
-		// the Strict case.
-		rendered[filename] = strings.ReplaceAll(buf.String(), "<no value>", "")
+	results, err := e.execute(t, sources, tpls, names)
+	if err != nil {
+		return map[string]string{}, err
 	}
 
+	rendered = make(map[string]string, len(names))
+	for i, filename := range names {
+		if results[i].err != nil {
+			return map[string]string{}, results[i].err
+		}
+		if sources != nil {
+			e.SourceMap[filename] = results[i].lines
+		}
+		rendered[filename] = results[i].out
+	}
 	return rendered, nil
 }
 
+// renderResult is the outcome of the execution of a template.
+type renderResult struct {
+	out   string
+	lines []Location
+	err   error
+}
+
+// execute executes the templates, concurrently when the engine allows it.
+// The results are in the order of the templates, and the templates after the
+// first one failing may not be executed.
+func (e Engine) execute(t *template.Template, sources *sourceRecorder, tpls map[string]renderable, names []string) ([]renderResult, error) {
+	results := make([]renderResult, len(names))
+	workers := min(e.Concurrency, len(names))
+	if workers <= 1 || e.Profile != nil {
+		for i, filename := range names {
+			results[i] = e.executeTemplate(t, sources, filename, tpls[filename], false)
+			if results[i].err != nil {
+				break
+			}
+		}
+		return results, nil
+	}
+
+	// Each worker executes the templates with its own copy of the template
+	// set, as the template functions keep the state of an execution.
+	var next atomic.Int64
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for range workers {
+		clone, err := t.Clone()
+		if err != nil {
+			return nil, fmt.Errorf("cannot clone template: %w", err)
+		}
+		// Re-inject the missingkey option, see tplFun.
+		e.setMissingKey(clone)
+		var workerSources *sourceRecorder
+		if sources != nil {
+			workerSources = new(sourceRecorder)
+		}
+		e.initFunMap(clone, workerSources)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// The templates are taken in order, so that all the templates
+			// before one failing are executed and the error reported is the
+			// same as when executing them one after the other.
+			for !failed.Load() {
+				i := int(next.Add(1)) - 1
+				if i >= len(names) {
+					return
+				}
+				results[i] = e.executeTemplate(clone, workerSources, names[i], tpls[names[i]], true)
+				if results[i].err != nil {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// executeTemplate executes a template file. With copyValues, the template
+// gets a copy of the values, so that it can be executed concurrently with the
+// other templates of the chart.
+func (e Engine) executeTemplate(t *template.Template, sources *sourceRecorder, filename string, r renderable, copyValues bool) (result renderResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = renderResult{err: fmt.Errorf("rendering template failed: %v", r)}
+		}
+	}()
+
+	// At render time, add information about the template that is being rendered.
+	vals := r.vals
+	if copyValues {
+		vals = maps.Clone(r.vals)
+		if vals["Values"] != nil {
+			values, err := copystructure.Copy(vals["Values"])
+			if err != nil {
+				return renderResult{err: fmt.Errorf("cannot copy values for %s: %w", filename, err)}
+			}
+			vals["Values"] = values
+		}
+	}
+	vals["Template"] = chartutil.Values{"Name": filename, "BasePath": r.basePath}
+
+	var buf strings.Builder
+	if sources != nil {
+		sources.start(&buf)
+	}
+	if e.Profile != nil {
+		e.Profile.push(filename, false, filename)
+	}
+	err := t.ExecuteTemplate(&buf, filename, vals)
+	if e.Profile != nil {
+		e.Profile.pop()
+	}
+	if err != nil {
+		return renderResult{err: reformatExecErrorMsg(filename, err)}
+	}
+	if sources != nil {
+		result.lines = sources.finish()
+	}
+
+	// Work around the issue where Go will emit "<no value>" even if Options(missing=zero)
+	// is set. Since missing=error will never get here, we do not need to handle
+	// the Strict case.
+	result.out = strings.ReplaceAll(buf.String(), "<no value>", "")
+	return result
+}
+
 func cleanupParseError(filename string, err error) error {
 	tokens := strings.Split(err.Error(), ": ")
 	if len(tokens) == 1 {
@@ -25,6 +25,7 @@ import (
 	"text/template"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -1428,3 +1429,76 @@ func TestRenderCustomTemplateFuncs(t *testing.T) {
 		t.Errorf("Expected %q, got %q", expected, rendered)
 	}
 }
+
+func TestRenderConcurrently(t *testing.T) {
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "parent", Version: "1.2.3"},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{- define "name" -}}{{ .Chart.Name }}-{{ .Values.suffix }}{{- end -}}`)},
+		},
+	}
+	for i := range 20 {
+		parent.Templates = append(parent.Templates, &chart.File{
+			Name: fmt.Sprintf("templates/cm%d.yaml", i),
+			Data: []byte(fmt.Sprintf("{{- $_ := set .Values \"suffix\" \"%d\" -}}\nname: {{ include \"name\" . }}\ntemplate: {{ .Template.Name }}\ntpl: {{ tpl \"{{ .Values.suffix }}\" . }}\n", i)),
+		})
+	}
+	child := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "child", Version: "1.2.3"},
+		Templates: []*chart.File{
+			{Name: "templates/cm.yaml", Data: []byte("name: {{ .Values.suffix }}\n")},
+		},
+	}
+	parent.AddDependency(child)
+
+	values := func() chartutil.Values {
+		return chartutil.Values{"Values": map[string]interface{}{"suffix": "x", "child": map[string]interface{}{"suffix": "y"}}}
+	}
+
+	sourceMap := SourceMap{}
+	sequential, err := Engine{SourceMap: sourceMap}.Render(parent, values())
+	require.NoError(t, err)
+
+	for range 10 {
+		e := Engine{Concurrency: 8, SourceMap: SourceMap{}}
+		out, err := e.Render(parent, values())
+		require.NoError(t, err)
+		assert.Equal(t, sequential, out)
+		assert.Equal(t, "name: parent-7\ntemplate: parent/templates/cm7.yaml\ntpl: 7\n", out["parent/templates/cm7.yaml"])
+		assert.Equal(t, "name: y\n", out["parent/charts/child/templates/cm.yaml"])
+		assert.Equal(t, sourceMap, e.SourceMap)
+		loc, ok := e.SourceMap.Lookup("parent/templates/cm7.yaml", 2)
+		assert.True(t, ok)
+		assert.Equal(t, Location{File: "parent/templates/cm7.yaml", Line: 3}, loc)
+	}
+
+	vals := values()
+	_, err = Engine{Concurrency: 8}.Render(parent, vals)
+	require.NoError(t, err)
+	assert.Equal(t, "x", vals["Values"].(map[string]interface{})["suffix"], "concurrent templates change copies of the values")
+
+	vals = values()
+	_, err = Engine{}.Render(parent, vals)
+	require.NoError(t, err)
+	assert.NotEqual(t, "x", vals["Values"].(map[string]interface{})["suffix"], "sequential templates share the values")
+}
+
+func TestRenderConcurrentlyReportsFirstError(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+	}
+	for i := range 20 {
+		tpl := "ok"
+		if i%5 == 3 {
+			tpl = fmt.Sprintf(`{{ fail "broken %d" }}`, i)
+		}
+		c.Templates = append(c.Templates, &chart.File{Name: fmt.Sprintf("templates/cm%02d.yaml", i), Data: []byte(tpl)})
+	}
+
+	_, expected := Render(c, chartutil.Values{})
+	require.Error(t, expected)
+	for range 10 {
+		_, err := Engine{Concurrency: 8}.Render(c, chartutil.Values{})
+		assert.Equal(t, expected, err)
+	}
+}
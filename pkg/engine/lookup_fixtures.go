@@ -0,0 +1,160 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+// fixtureClientProvider serves 'lookup' from objects read from files instead
+// of a cluster.
+type fixtureClientProvider struct {
+	objects []*unstructured.Unstructured
+}
+
+// NewFixtureClientProvider returns a ClientProvider serving the objects of the
+// YAML or JSON files in a directory, so that templates using 'lookup' can be
+// rendered and tested without a cluster.
+//
+// A file may hold several objects, separated by '---' or in a List. Objects
+// looked up by name are returned when they are found, and an empty map
+// otherwise, as with a cluster. A kind is namespaced unless all of its
+// objects are cluster scoped.
+func NewFixtureClientProvider(dir string) (ClientProvider, error) {
+	p := &fixtureClientProvider{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		objs, err := readFixtures(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		p.objects = append(p.objects, objs...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read lookup fixtures: %w", err)
+	}
+	return p, nil
+}
+
+func readFixtures(path string) ([]*unstructured.Unstructured, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var objs []*unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var content map[string]interface{}
+		if err := decoder.Decode(&content); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objs, nil
+			}
+			return nil, err
+		}
+		if len(content) == 0 {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: content}
+		if obj.IsList() {
+			err := obj.EachListItem(func(item runtime.Object) error {
+				u := item.(*unstructured.Unstructured)
+				if err := validateFixture(u); err != nil {
+					return err
+				}
+				objs = append(objs, u)
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := validateFixture(obj); err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+}
+
+func validateFixture(obj *unstructured.Unstructured) error {
+	if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+		return errors.New("object has no apiVersion or kind")
+	}
+	if obj.GetName() == "" {
+		return fmt.Errorf("%s object has no name", obj.GetKind())
+	}
+	return nil
+}
+
+func (p *fixtureClientProvider) GetClientFor(apiVersion, kind string) (dynamic.NamespaceableResourceInterface, bool, error) {
+	gvk := schema.FromAPIVersionAndKind(apiVersion, kind)
+	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+
+	var objects []runtime.Object
+	namespaced := false
+	for _, obj := range p.objects {
+		if obj.GroupVersionKind() != gvk {
+			continue
+		}
+		objects = append(objects, obj.DeepCopy())
+		if obj.GetNamespace() != "" {
+			namespaced = true
+		}
+	}
+	if len(objects) == 0 {
+		namespaced = true
+	}
+
+	listKinds := map[schema.GroupVersionResource]string{gvr: kind + "List"}
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+	return client.Resource(gvr), namespaced, nil
+}
+
+// isOffline tells whether a client provider serves fixtures rather than a
+// cluster.
+func isOffline(p ClientProvider) bool {
+	_, ok := p.(*fixtureClientProvider)
+	return ok
+}
+
+var _ ClientProvider = &fixtureClientProvider{}
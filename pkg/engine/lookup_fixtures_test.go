@@ -0,0 +1,90 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+const lookupFixturesTemplate = `{{- $existing := lookup "v1" "Secret" "default" "existing" -}}
+existing: {{ dig "data" "password" "generated" $existing }}
+{{- $missing := lookup "v1" "Secret" "default" "missing" }}
+missing: {{ dig "data" "password" "generated" $missing }}
+{{- $other := lookup "v1" "Secret" "kube-system" "existing" }}
+wrongNamespace: {{ empty $other }}
+secrets:
+{{- range (lookup "v1" "Secret" "default" "").items }}
+  - {{ .metadata.name }}
+{{- end }}
+allSecrets: {{ len (lookup "v1" "Secret" "" "").items }}
+namespaces: {{ len (lookup "v1" "Namespace" "" "").items }}
+namespace: {{ (lookup "v1" "Namespace" "" "kube-system").metadata.name }}
+configMaps: {{ len (lookup "v1" "ConfigMap" "default" "").items }}
+`
+
+func TestRenderWithLookupFixtures(t *testing.T) {
+	provider, err := NewFixtureClientProvider("testdata/lookup-fixtures")
+	require.NoError(t, err)
+
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*chart.File{
+			{Name: "templates/lookup", Data: []byte(lookupFixturesTemplate)},
+		},
+	}
+	vals, err := chartutil.ToRenderValues(c, map[string]interface{}{}, chartutil.ReleaseOptions{}, nil)
+	require.NoError(t, err)
+
+	expected := `existing: aHVudGVyMg==
+missing: generated
+wrongNamespace: true
+secrets:
+  - existing
+  - other
+allSecrets: 3
+namespaces: 2
+namespace: kube-system
+configMaps: 0
+`
+	out, err := NewWithClientProvider(provider).Render(c, vals)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out["moby/templates/lookup"])
+
+	e := NewWithClientProvider(provider)
+	e.LintMode = true
+	out, err = e.Render(c, vals)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out["moby/templates/lookup"], "fixtures are used when linting")
+}
+
+func TestNewFixtureClientProviderErrors(t *testing.T) {
+	_, err := NewFixtureClientProvider("testdata/missing")
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.yaml"), []byte("apiVersion: v1\nkind: Secret\n"), 0o644))
+	_, err = NewFixtureClientProvider(dir)
+	assert.ErrorContains(t, err, "bad.yaml: Secret object has no name")
+}
@@ -0,0 +1,155 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"maps"
+	"slices"
+	"sync"
+	"text/template"
+	"text/template/parse"
+)
+
+// parseCacheSize is the number of template files whose parse trees are kept.
+const parseCacheSize = 4096
+
+// ParseCache keeps the parse trees of template files across renders, so that
+// rendering the same chart again, e.g. after one of its files changed or with
+// other values, only parses the files that changed. The trees are kept by the
+// digest of the name and content of their file, so the files of different
+// charts, even with the same content, do not share them. The least recently
+// used files are evicted first.
+//
+// A ParseCache may be shared by engines rendering at the same time. The
+// cached parse trees are shared by the templates of all the renders and must
+// not be modified.
+type ParseCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List
+}
+
+type parseCacheEntry struct {
+	key   [sha256.Size]byte
+	trees map[string]*parse.Tree
+}
+
+// NewParseCache returns an empty parse cache.
+func NewParseCache() *ParseCache {
+	return newParseCache(parseCacheSize)
+}
+
+func newParseCache(size int) *ParseCache {
+	return &ParseCache{
+		size:    size,
+		entries: map[[sha256.Size]byte]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+func (c *ParseCache) get(key [sha256.Size]byte) (map[string]*parse.Tree, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*parseCacheEntry).trees, true
+}
+
+func (c *ParseCache) add(key [sha256.Size]byte, trees map[string]*parse.Tree) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&parseCacheEntry{key: key, trees: trees})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*parseCacheEntry).key)
+	}
+}
+
+// templateParser parses the template files of a render, reusing the parse
+// trees of earlier renders when it has a cache.
+type templateParser struct {
+	cache *ParseCache
+	funcs template.FuncMap
+	// digest identifies what the parse trees depend on besides the template
+	// files: the names of the functions, and whether source marks are inserted.
+	digest []byte
+	marks  bool
+}
+
+func newTemplateParser(cache *ParseCache, funcs template.FuncMap, marks bool) *templateParser {
+	p := &templateParser{cache: cache, funcs: funcs, marks: marks}
+	if cache == nil {
+		return p
+	}
+	h := sha256.New()
+	for _, name := range slices.Sorted(maps.Keys(funcs)) {
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+	}
+	if marks {
+		h.Write([]byte{1})
+	}
+	p.digest = h.Sum(nil)
+	return p
+}
+
+// parse returns the parse trees of a template file by template name, that is
+// the tree of the file itself and those of the templates it defines.
+func (p *templateParser) parse(filename, text string) (map[string]*parse.Tree, error) {
+	var key [sha256.Size]byte
+	if p.cache != nil {
+		h := sha256.New()
+		h.Write(p.digest)
+		h.Write([]byte(filename))
+		h.Write([]byte{0})
+		h.Write([]byte(text))
+		h.Sum(key[:0])
+
+		if trees, ok := p.cache.get(key); ok {
+			return trees, nil
+		}
+	}
+
+	t, err := template.New(filename).Funcs(p.funcs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	trees := map[string]*parse.Tree{}
+	for _, tt := range t.Templates() {
+		if tt.Tree != nil {
+			trees[tt.Name()] = tt.Tree
+		}
+	}
+	if p.marks {
+		insertSourceMarks(trees, text)
+	}
+	if p.cache != nil {
+		p.cache.add(key, trees)
+	}
+	return trees, nil
+}
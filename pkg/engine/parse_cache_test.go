@@ -0,0 +1,79 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"crypto/sha256"
+	"testing"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateParserCachesTrees(t *testing.T) {
+	funcs := template.FuncMap{"upper": func(s string) string { return s }}
+	const text = `{{ define "moby.name" }}moby{{ end }}name: {{ upper "moby" }}`
+
+	cache := NewParseCache()
+	p := newTemplateParser(cache, funcs, false)
+	trees, err := p.parse("moby/templates/a.yaml", text)
+	require.NoError(t, err)
+	assert.Len(t, trees, 2)
+	assert.Contains(t, trees, "moby.name")
+
+	again, err := newTemplateParser(cache, funcs, false).parse("moby/templates/a.yaml", text)
+	require.NoError(t, err)
+	assert.Same(t, trees["moby/templates/a.yaml"], again["moby/templates/a.yaml"], "the trees of the same file are reused")
+
+	other, err := p.parse("moby/templates/b.yaml", text)
+	require.NoError(t, err)
+	assert.NotSame(t, trees["moby/templates/a.yaml"], other["moby/templates/b.yaml"], "the trees depend on the name of the file")
+
+	marked, err := newTemplateParser(cache, funcs, true).parse("moby/templates/a.yaml", text)
+	require.NoError(t, err)
+	assert.NotSame(t, trees["moby/templates/a.yaml"], marked["moby/templates/a.yaml"], "the trees depend on the source marks")
+	assert.Equal(t, "name: {{upper \"moby\"}}", trees["moby/templates/a.yaml"].Root.String(), "the cached trees are not marked")
+
+	_, err = newTemplateParser(cache, template.FuncMap{}, false).parse("moby/templates/a.yaml", text)
+	assert.ErrorContains(t, err, `function "upper" not defined`, "the trees depend on the functions")
+
+	uncached, err := newTemplateParser(nil, funcs, false).parse("moby/templates/a.yaml", text)
+	require.NoError(t, err)
+	assert.NotSame(t, trees["moby/templates/a.yaml"], uncached["moby/templates/a.yaml"], "the trees are not reused without a cache")
+}
+
+func TestParseCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newParseCache(2)
+	keys := [][sha256.Size]byte{{1}, {2}, {3}}
+	trees := []map[string]*parse.Tree{{"a": nil}, {"b": nil}, {"c": nil}}
+
+	c.add(keys[0], trees[0])
+	c.add(keys[1], trees[1])
+	_, ok := c.get(keys[0])
+	assert.True(t, ok)
+
+	c.add(keys[2], trees[2])
+	_, ok = c.get(keys[1])
+	assert.False(t, ok, "the least recently used entry is evicted")
+	got, ok := c.get(keys[0])
+	assert.True(t, ok)
+	assert.Equal(t, trees[0], got)
+	_, ok = c.get(keys[2])
+	assert.True(t, ok)
+}
@@ -0,0 +1,172 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+
+	"helm.sh/helm/v4/internal/plugin"
+	"helm.sh/helm/v4/internal/plugin/schema"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// templateFuncsPluginType is the type of the plugins providing template
+// functions.
+const templateFuncsPluginType = "templatefuncs/v1"
+
+// DefaultPluginTimeout bounds each call of a template function of a plugin
+// when Engine.PluginTimeout is not set.
+const DefaultPluginTimeout = 30 * time.Second
+
+// pluginRequirement is a plugin required by a chart.
+type pluginRequirement struct {
+	chart string
+	*chart.Plugin
+}
+
+// requiredPlugins adds the plugins required by a chart and its dependencies
+// to required, by plugin name.
+func requiredPlugins(c *chart.Chart, required map[string][]pluginRequirement) {
+	if c.Metadata != nil {
+		for _, p := range c.Metadata.Plugins {
+			required[p.Name] = append(required[p.Name], pluginRequirement{chart: c.Name(), Plugin: p})
+		}
+	}
+	for _, dep := range c.Dependencies() {
+		requiredPlugins(dep, required)
+	}
+}
+
+// pluginFuncs returns the template functions of the templatefuncs/v1 plugins
+// required by a chart and its dependencies, loaded from the plugin
+// directories.
+//
+// The functions run in the Extism Wasm runtime, each call in a new instance
+// of the plugin, so that they cannot keep state between calls. A call is
+// aborted after the timeout.
+func pluginFuncs(c *chart.Chart, dirs []string, timeout time.Duration) (template.FuncMap, error) {
+	required := map[string][]pluginRequirement{}
+	requiredPlugins(c, required)
+	if len(required) == 0 {
+		return nil, nil
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultPluginTimeout
+	}
+
+	builtins := funcMap()
+	for _, name := range []string{"include", "tpl", "required", "lookup"} {
+		builtins[name] = nil
+	}
+
+	funcs := template.FuncMap{}
+	providers := map[string]string{}
+	names := make([]string, 0, len(required))
+	for name := range required {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		reqs := required[name]
+		plugins, err := plugin.FindPlugins(dirs, plugin.Descriptor{Name: name, Type: templateFuncsPluginType})
+		if err != nil {
+			return nil, fmt.Errorf("unable to load plugin %q required by chart %q: %w", name, reqs[0].chart, err)
+		}
+		if len(plugins) == 0 {
+			return nil, fmt.Errorf("chart %q requires the %s plugin %q, which is not installed", reqs[0].chart, templateFuncsPluginType, name)
+		}
+		p := plugins[0]
+		md := p.Metadata()
+
+		for _, req := range reqs {
+			if err := checkPluginVersion(md.Version, req.Version); err != nil {
+				return nil, fmt.Errorf("chart %q requires plugin %q version %q: %w", req.chart, name, req.Version, err)
+			}
+		}
+
+		config, ok := md.Config.(*plugin.ConfigTemplateFuncs)
+		if !ok {
+			return nil, fmt.Errorf("plugin %q has an invalid config type: %T", name, md.Config)
+		}
+		for _, fn := range config.Functions {
+			if _, ok := builtins[fn]; ok {
+				return nil, fmt.Errorf("plugin %q cannot replace the template function %q", name, fn)
+			}
+			if other, ok := providers[fn]; ok {
+				return nil, fmt.Errorf("template function %q is provided by both plugins %q and %q", fn, other, name)
+			}
+			providers[fn] = name
+			funcs[fn] = pluginFunc(p, fn, timeout)
+		}
+	}
+	return funcs, nil
+}
+
+func checkPluginVersion(version, constraint string) error {
+	if constraint == "" {
+		return nil
+	}
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return err
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return fmt.Errorf("installed version %q is invalid", version)
+	}
+	if !c.Check(v) {
+		return fmt.Errorf("installed version is %s", version)
+	}
+	return nil
+}
+
+// pluginFunc returns a template function calling the function of a plugin.
+func pluginFunc(p plugin.Plugin, name string, timeout time.Duration) func(...interface{}) (interface{}, error) {
+	return func(args ...interface{}) (interface{}, error) {
+		input := &plugin.Input{
+			Message: schema.InputMessageTemplateFuncsV1{
+				Function: name,
+				Args:     args,
+			},
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		output, err := p.Invoke(ctx, input)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("plugin %q did not return from %s within %s", p.Metadata().Name, name, timeout)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to invoke plugin %q: %w", p.Metadata().Name, err)
+		}
+		msg, ok := output.Message.(schema.OutputMessageTemplateFuncsV1)
+		if !ok {
+			return nil, fmt.Errorf("plugin %q returned an invalid output type: %T", p.Metadata().Name, output.Message)
+		}
+		if msg.Error != "" {
+			return nil, fmt.Errorf("%s: %s", name, msg.Error)
+		}
+		return msg.Result, nil
+	}
+}
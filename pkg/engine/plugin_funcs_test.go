@@ -0,0 +1,105 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/internal/plugin"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+const pluginFuncsTemplate = `name: {{ dnsLabel .Chart.Name "Web" 1 }}
+gateway: {{ cidrHost .Values.subnet 1 }}
+`
+
+func buildPluginFuncsTestPlugin(t *testing.T) string {
+	t.Helper()
+	cmd := exec.Command("make", "-C", "testdata/plugins/netfuncs")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	require.NoError(t, cmd.Run(), "failed to build the netfuncs plugin")
+	return "testdata/plugins"
+}
+
+func pluginFuncsChart(plugins ...*chart.Plugin) *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3", Plugins: plugins},
+		Templates: []*chart.File{
+			{Name: "templates/net", Data: []byte(pluginFuncsTemplate)},
+		},
+	}
+}
+
+func TestRenderWithPluginFuncs(t *testing.T) {
+	t.Setenv("HELM_CACHE_HOME", t.TempDir())
+	dir := buildPluginFuncsTestPlugin(t)
+
+	c := pluginFuncsChart(&chart.Plugin{Name: "netfuncs", Version: "^1.0.0"})
+	vals, err := chartutil.ToRenderValues(c, map[string]interface{}{"subnet": "10.2.0.0/16"}, chartutil.ReleaseOptions{}, nil)
+	require.NoError(t, err)
+
+	out, err := Engine{PluginDirs: []string{dir}}.Render(c, vals)
+	require.NoError(t, err)
+	assert.Equal(t, "name: moby-web-1\ngateway: 10.2.0.1\n", out["moby/templates/net"])
+
+	vals["Values"] = map[string]interface{}{"subnet": "bogus"}
+	_, err = Engine{PluginDirs: []string{dir}}.Render(c, vals)
+	assert.ErrorContains(t, err, `error calling cidrHost: cidrHost: netip.ParsePrefix("bogus"): no '/'`)
+}
+
+func TestRenderWithMissingPluginFuncs(t *testing.T) {
+	t.Setenv("HELM_CACHE_HOME", t.TempDir())
+	dir := buildPluginFuncsTestPlugin(t)
+	vals := chartutil.Values{"Values": map[string]interface{}{"subnet": "10.2.0.0/16"}}
+
+	_, err := Engine{PluginDirs: []string{"testdata/missing"}}.Render(pluginFuncsChart(&chart.Plugin{Name: "netfuncs"}), vals)
+	assert.EqualError(t, err, `chart "moby" requires the templatefuncs/v1 plugin "netfuncs", which is not installed`)
+
+	_, err = Engine{PluginDirs: []string{dir}}.Render(pluginFuncsChart(&chart.Plugin{Name: "netfuncs", Version: ">=2.0.0"}), vals)
+	assert.EqualError(t, err, `chart "moby" requires plugin "netfuncs" version ">=2.0.0": installed version is 1.2.0`)
+
+	parent := &chart.Chart{Metadata: &chart.Metadata{Name: "parent", Version: "0.1.0"}}
+	parent.AddDependency(pluginFuncsChart(&chart.Plugin{Name: "netfuncs"}))
+	_, err = Engine{}.Render(parent, vals)
+	assert.EqualError(t, err, `chart "moby" requires the templatefuncs/v1 plugin "netfuncs", which is not installed`, "dependencies require plugins too")
+}
+
+// blockingPlugin is a plugin whose calls only return once they are cancelled.
+type blockingPlugin struct{}
+
+func (blockingPlugin) Dir() string { return "" }
+
+func (blockingPlugin) Metadata() plugin.Metadata { return plugin.Metadata{Name: "sleepy"} }
+
+func (blockingPlugin) Invoke(ctx context.Context, _ *plugin.Input) (*plugin.Output, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestPluginFuncTimeout(t *testing.T) {
+	_, err := pluginFunc(blockingPlugin{}, "nap", 10*time.Millisecond)()
+	assert.EqualError(t, err, `plugin "sleepy" did not return from nap within 10ms`)
+}
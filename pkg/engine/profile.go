@@ -0,0 +1,223 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"cmp"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/pprof/profile"
+)
+
+// Profile records where the time rendering templates is spent.
+//
+// The time of a named template executed with 'include' is recorded for that
+// named template, the time of one executed with the 'template' action and of
+// 'tpl' counts towards the template calling it.
+type Profile struct {
+	entries map[profileKey]*ProfileEntry
+	stack   []*profileFrame
+	samples map[string]*profileSample
+	// now returns the current time, it is replaced by the tests.
+	now func() time.Time
+}
+
+// ProfileEntry is the time spent rendering a template or named template.
+type ProfileEntry struct {
+	// Name is the name of the template, e.g. "mychart/templates/service.yaml",
+	// or of the named template.
+	Name string `json:"name"`
+	// Define is set for named templates.
+	Define bool `json:"define,omitempty"`
+	// File is the file the template is defined in, if known.
+	File string `json:"file,omitempty"`
+	// Calls is the number of times it was rendered.
+	Calls int `json:"calls"`
+	// Includes is the number of 'include' calls it made.
+	Includes int `json:"includes"`
+	// Tpls is the number of 'tpl' calls it made.
+	Tpls int `json:"tpls"`
+	// Self is the time spent rendering it, excluding the named templates it
+	// included.
+	Self time.Duration `json:"self"`
+	// Total is the time spent rendering it, including the named templates it
+	// included.
+	Total time.Duration `json:"total"`
+}
+
+type profileKey struct {
+	name   string
+	define bool
+}
+
+type profileFrame struct {
+	entry    *ProfileEntry
+	start    time.Time
+	children time.Duration
+}
+
+// profileSample is the time spent in a stack of templates, for pprof.
+type profileSample struct {
+	stack []*ProfileEntry
+	calls int64
+	self  time.Duration
+}
+
+// NewProfile returns an empty render profile.
+func NewProfile() *Profile {
+	return &Profile{
+		entries: map[profileKey]*ProfileEntry{},
+		samples: map[string]*profileSample{},
+		now:     time.Now,
+	}
+}
+
+// push starts recording the time spent rendering a template.
+func (p *Profile) push(name string, define bool, file string) {
+	key := profileKey{name: name, define: define}
+	entry, ok := p.entries[key]
+	if !ok {
+		entry = &ProfileEntry{Name: name, Define: define, File: file}
+		p.entries[key] = entry
+	}
+	entry.Calls++
+	p.stack = append(p.stack, &profileFrame{entry: entry, start: p.now()})
+}
+
+// pop stops recording the time spent rendering the last template pushed.
+func (p *Profile) pop() {
+	frame := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+
+	elapsed := p.now().Sub(frame.start)
+	self := elapsed - frame.children
+	frame.entry.Self += self
+	// Count the time of recursive templates once.
+	if !slices.ContainsFunc(p.stack, func(f *profileFrame) bool { return f.entry == frame.entry }) {
+		frame.entry.Total += elapsed
+	}
+	if len(p.stack) > 0 {
+		p.stack[len(p.stack)-1].children += elapsed
+	}
+
+	stack := make([]*ProfileEntry, 0, len(p.stack)+1)
+	names := make([]string, 0, len(p.stack)+1)
+	for _, f := range append(p.stack, frame) {
+		stack = append(stack, f.entry)
+		names = append(names, f.entry.Name)
+	}
+	key := strings.Join(names, "\x00")
+	sample, ok := p.samples[key]
+	if !ok {
+		sample = &profileSample{stack: stack}
+		p.samples[key] = sample
+	}
+	sample.calls++
+	sample.self += self
+}
+
+// call counts an 'include' or 'tpl' call of the template being rendered.
+func (p *Profile) call(fn string) {
+	if len(p.stack) == 0 {
+		return
+	}
+	entry := p.stack[len(p.stack)-1].entry
+	switch fn {
+	case "include":
+		entry.Includes++
+	case "tpl":
+		entry.Tpls++
+	}
+}
+
+// Entries returns the templates and named templates rendered, the slowest
+// first.
+func (p *Profile) Entries() []ProfileEntry {
+	entries := make([]ProfileEntry, 0, len(p.entries))
+	for _, e := range p.entries {
+		entries = append(entries, *e)
+	}
+	slices.SortFunc(entries, func(a, b ProfileEntry) int {
+		return cmp.Or(
+			cmp.Compare(b.Self, a.Self),
+			cmp.Compare(b.Total, a.Total),
+			strings.Compare(a.Name, b.Name),
+		)
+	})
+	return entries
+}
+
+// WritePprof writes the profile in the pprof format, with the templates as
+// functions, e.g. for 'go tool pprof'.
+func (p *Profile) WritePprof(w io.Writer) error {
+	prof := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "calls", Unit: "count"},
+			{Type: "time", Unit: "nanoseconds"},
+		},
+		PeriodType: &profile.ValueType{Type: "time", Unit: "nanoseconds"},
+		Period:     1,
+	}
+
+	locations := map[*ProfileEntry]*profile.Location{}
+	location := func(e *ProfileEntry) *profile.Location {
+		if l, ok := locations[e]; ok {
+			return l
+		}
+		fn := &profile.Function{
+			ID:         uint64(len(prof.Function) + 1),
+			Name:       e.Name,
+			SystemName: e.Name,
+			Filename:   e.File,
+		}
+		prof.Function = append(prof.Function, fn)
+		l := &profile.Location{
+			ID:   uint64(len(prof.Location) + 1),
+			Line: []profile.Line{{Function: fn}},
+		}
+		prof.Location = append(prof.Location, l)
+		locations[e] = l
+		return l
+	}
+
+	keys := make([]string, 0, len(p.samples))
+	for key := range p.samples {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		s := p.samples[key]
+		// pprof lists the locations of a sample from the leaf to the root.
+		locs := make([]*profile.Location, 0, len(s.stack))
+		for i := len(s.stack) - 1; i >= 0; i-- {
+			locs = append(locs, location(s.stack[i]))
+		}
+		prof.Sample = append(prof.Sample, &profile.Sample{
+			Location: locs,
+			Value:    []int64{s.calls, int64(s.self)},
+		})
+		prof.DurationNanos += int64(s.self)
+	}
+
+	if err := prof.CheckValid(); err != nil {
+		return err
+	}
+	return prof.Write(w)
+}
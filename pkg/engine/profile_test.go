@@ -0,0 +1,91 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+func TestRenderProfile(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{- define "moby.labels" -}}app: {{ include "moby.name" . }}{{- end }}
+{{- define "moby.name" -}}{{ .Chart.Name }}{{- end }}`)},
+			{Name: "templates/cm.yaml", Data: []byte(`labels: {{ include "moby.labels" . }}
+other: {{ include "moby.labels" . }}
+name: {{ tpl "{{ include \"moby.name\" . }}" . }}`)},
+		},
+	}
+	vals, err := chartutil.ToRenderValues(c, map[string]interface{}{}, chartutil.ReleaseOptions{}, nil)
+	require.NoError(t, err)
+
+	p := NewProfile()
+	var clock time.Time
+	p.now = func() time.Time {
+		clock = clock.Add(time.Millisecond)
+		return clock
+	}
+	e := Engine{Profile: p}
+	out, err := e.Render(c, vals)
+	require.NoError(t, err)
+	assert.Equal(t, "labels: app: moby\nother: app: moby\nname: moby", out["moby/templates/cm.yaml"])
+
+	entries := map[string]ProfileEntry{}
+	for _, entry := range p.Entries() {
+		entries[entry.Name] = entry
+	}
+	require.Len(t, entries, 3)
+
+	cm := entries["moby/templates/cm.yaml"]
+	assert.False(t, cm.Define)
+	assert.Equal(t, 1, cm.Calls)
+	assert.Equal(t, 3, cm.Includes, "the include of tpl counts for the template calling tpl")
+	assert.Equal(t, 1, cm.Tpls)
+
+	labels := entries["moby.labels"]
+	assert.True(t, labels.Define)
+	assert.Equal(t, "moby/templates/_helpers.tpl", labels.File)
+	assert.Equal(t, 2, labels.Calls)
+	assert.Equal(t, 2, labels.Includes)
+
+	name := entries["moby.name"]
+	assert.Equal(t, 3, name.Calls)
+	assert.Equal(t, name.Self, name.Total)
+	assert.Equal(t, labels.Self+2*name.Total/3, labels.Total)
+	assert.Equal(t, cm.Total, cm.Self+labels.Total+name.Total/3)
+
+	var buf bytes.Buffer
+	require.NoError(t, p.WritePprof(&buf))
+	prof, err := profile.Parse(&buf)
+	require.NoError(t, err)
+	assert.Len(t, prof.Function, 3)
+	var total int64
+	for _, s := range prof.Sample {
+		total += s.Value[1]
+	}
+	assert.Equal(t, int64(cm.Total), total)
+}
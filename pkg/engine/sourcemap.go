@@ -0,0 +1,222 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"text/template/parse"
+)
+
+// sourceMarkFunc is the function the engine calls from the parse trees of the
+// templates to record which template line the output is written from.
+const sourceMarkFunc = "__helmSourceMark"
+
+// Location is a line of a template.
+type Location struct {
+	// File is the name of the template, e.g. "mychart/templates/deployment.yaml".
+	File string
+	// Line is the line in the template, starting at 1.
+	Line int
+}
+
+// String returns the location as file:line.
+func (l Location) String() string {
+	return l.File + ":" + strconv.Itoa(l.Line)
+}
+
+// SourceMap maps the lines of the rendered templates back to the lines of the
+// templates that produced them, by rendered template name.
+//
+// A line written by a template action, e.g. '{{ toYaml .Values.resources }}',
+// maps to the line of that action. Lines written by 'include' and 'tpl' map to
+// the line of the call, those written by 'template' to the line of the
+// defined template.
+type SourceMap map[string][]Location
+
+// Lookup returns the location of the template line that produced a line,
+// starting at 1, of a rendered template.
+func (m SourceMap) Lookup(name string, line int) (Location, bool) {
+	lines := m[name]
+	if line < 1 || line > len(lines) || lines[line-1].File == "" {
+		return Location{}, false
+	}
+	return lines[line-1], true
+}
+
+// sourceRecorder records the location of the first template line written to
+// each line of the output of a template.
+type sourceRecorder struct {
+	out *strings.Builder
+	// written is the part of out whose lines have been counted.
+	written int
+	lines   []Location
+	// last is the location of the last mark.
+	last Location
+	// marked is set once a mark recorded the location of the current line.
+	marked bool
+	// nested is set while include and tpl write to their own buffers.
+	nested int
+}
+
+func (r *sourceRecorder) start(out *strings.Builder) {
+	r.out = out
+	r.written = 0
+	r.lines = []Location{{}}
+	r.last = Location{}
+	r.marked = false
+}
+
+// sync accounts for the lines written since the last mark. A line without a
+// mark of its own was written from the location of the last one.
+func (r *sourceRecorder) sync() {
+	s := r.out.String()
+	for range strings.Count(s[r.written:], "\n") {
+		r.lines = append(r.lines, r.last)
+		r.marked = false
+	}
+	r.written = len(s)
+}
+
+func (r *sourceRecorder) mark(file string, line int) string {
+	if r.out == nil || r.nested > 0 {
+		return ""
+	}
+	r.sync()
+	r.last = Location{File: file, Line: line}
+	if !r.marked {
+		r.lines[len(r.lines)-1] = r.last
+		r.marked = true
+	}
+	return ""
+}
+
+func (r *sourceRecorder) finish() []Location {
+	r.sync()
+	r.out = nil
+	return r.lines
+}
+
+// nest wraps a template function writing to its own buffer so that the marks
+// of the templates it executes are ignored.
+func (r *sourceRecorder) nest(fn func(string, interface{}) (string, error)) func(string, interface{}) (string, error) {
+	return func(s string, data interface{}) (string, error) {
+		r.nested++
+		defer func() { r.nested-- }()
+		return fn(s, data)
+	}
+}
+
+// insertSourceMarks adds a call to the mark function before every node of the
+// parse trees of a template file, and after every newline of their text, so
+// that executing them records the template lines.
+func insertSourceMarks(trees map[string]*parse.Tree, src string) {
+	starts := lineStarts(src)
+	for _, tree := range trees {
+		if tree.Root == nil {
+			continue
+		}
+		m := marker{file: tree.ParseName, lineStarts: starts}
+		m.list(tree.Root)
+	}
+}
+
+type marker struct {
+	file       string
+	lineStarts []int
+}
+
+// line returns the line of a position of the template.
+func (m marker) line(pos parse.Pos) int {
+	return sort.SearchInts(m.lineStarts, int(pos)+1)
+}
+
+func (m marker) list(list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+	nodes := make([]parse.Node, 0, 2*len(list.Nodes))
+	for _, n := range list.Nodes {
+		line := m.line(n.Position())
+		nodes = append(nodes, m.mark(n.Position(), line))
+		switch n := n.(type) {
+		case *parse.TextNode:
+			// Split the text after each newline, the lines of the output
+			// then start with a mark.
+			text, pos := n.Text, n.Pos
+			for i := strings.IndexByte(string(text), '\n'); i >= 0 && i < len(text)-1; i = strings.IndexByte(string(text), '\n') {
+				nodes = append(nodes, m.text(n, text[:i+1], pos))
+				text, pos, line = text[i+1:], pos+parse.Pos(i+1), line+1
+				nodes = append(nodes, m.mark(pos, line))
+			}
+			nodes = append(nodes, m.text(n, text, pos))
+			continue
+		case *parse.IfNode:
+			m.list(n.List)
+			m.list(n.ElseList)
+		case *parse.RangeNode:
+			m.list(n.List)
+			m.list(n.ElseList)
+		case *parse.WithNode:
+			m.list(n.List)
+			m.list(n.ElseList)
+		}
+		nodes = append(nodes, n)
+	}
+	list.Nodes = nodes
+}
+
+func (m marker) text(n *parse.TextNode, text []byte, pos parse.Pos) parse.Node {
+	c := n.Copy().(*parse.TextNode)
+	c.Text, c.Pos = text, pos
+	return c
+}
+
+// mark returns the action {{ __helmSourceMark "file" line }}.
+func (m marker) mark(pos parse.Pos, line int) parse.Node {
+	return &parse.ActionNode{
+		NodeType: parse.NodeAction,
+		Pos:      pos,
+		Line:     line,
+		Pipe: &parse.PipeNode{
+			NodeType: parse.NodePipe,
+			Pos:      pos,
+			Line:     line,
+			Cmds: []*parse.CommandNode{{
+				NodeType: parse.NodeCommand,
+				Pos:      pos,
+				Args: []parse.Node{
+					parse.NewIdentifier(sourceMarkFunc).SetPos(pos),
+					&parse.StringNode{NodeType: parse.NodeString, Pos: pos, Quoted: strconv.Quote(m.file), Text: m.file},
+					&parse.NumberNode{NodeType: parse.NodeNumber, Pos: pos, IsInt: true, Int64: int64(line), Text: strconv.Itoa(line)},
+				},
+			}},
+		},
+	}
+}
+
+// lineStarts returns the offsets at which the lines of s start.
+func lineStarts(s string) []int {
+	starts := []int{0}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
@@ -0,0 +1,130 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+const sourceMapDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "moby.name" . }}
+  labels:
+    {{- include "moby.labels" . | nindent 4 }}
+spec:
+  {{- if .Values.replicas }}
+  replicas: {{ .Values.replicas }}
+  {{- end }}
+  template:
+    spec:
+      containers:
+      {{- range .Values.containers }}
+      - name: {{ .name }}
+        image: {{ .image | quote }}
+      {{- end }}
+{{ template "moby.resources" . }}
+`
+
+const sourceMapHelpers = `{{- define "moby.name" -}}
+{{ .Chart.Name }}
+{{- end }}
+
+{{- define "moby.labels" -}}
+app: {{ .Chart.Name }}
+version: {{ .Chart.Version }}
+{{- end }}
+
+{{- define "moby.resources" -}}
+      resources:
+        {{- toYaml .Values.resources | nindent 8 }}
+{{- end }}
+`
+
+func TestRenderSourceMap(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*chart.File{
+			{Name: "templates/deployment.yaml", Data: []byte(sourceMapDeployment)},
+			{Name: "templates/_helpers.tpl", Data: []byte(sourceMapHelpers)},
+		},
+		Values: map[string]interface{}{
+			"replicas": 2,
+			"containers": []interface{}{
+				map[string]interface{}{"name": "web", "image": "nginx"},
+				map[string]interface{}{"name": "sidecar", "image": "envoy"},
+			},
+			"resources": map[string]interface{}{
+				"limits": map[string]interface{}{"cpu": "1", "memory": "1Gi"},
+			},
+		},
+	}
+	vals, err := chartutil.ToRenderValues(c, c.Values, chartutil.ReleaseOptions{}, nil)
+	require.NoError(t, err)
+
+	expected, err := Render(c, vals)
+	require.NoError(t, err)
+
+	e := Engine{SourceMap: SourceMap{}}
+	out, err := e.Render(c, vals)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out, "the source map must not change the output")
+
+	const name = "moby/templates/deployment.yaml"
+	const helpers = "moby/templates/_helpers.tpl"
+	want := map[string]Location{
+		"apiVersion: apps/v1":      {name, 1},
+		"  name: moby":             {name, 4},
+		"    app: moby":            {name, 6},
+		"    version: 1.2.3":       {name, 6},
+		"spec:":                    {name, 7},
+		"  replicas: 2":            {name, 9},
+		"  template:":              {name, 11},
+		"      - name: web":        {name, 15},
+		"        image: \"nginx\"": {name, 16},
+		"      - name: sidecar":    {name, 15},
+		"        image: \"envoy\"": {name, 16},
+		"resources:":               {name, 18},
+		"          cpu: \"1\"":     {helpers, 12},
+	}
+	lines := strings.Split(out[name], "\n")
+	assert.Len(t, e.SourceMap[name], len(lines))
+	for i, line := range lines {
+		loc, ok := want[line]
+		if !ok {
+			continue
+		}
+		got, found := e.SourceMap.Lookup(name, i+1)
+		assert.True(t, found, line)
+		assert.Equal(t, loc, got, "line %d %q", i+1, line)
+		delete(want, line)
+	}
+	assert.Empty(t, want, "lines missing from the output")
+
+	_, ok := e.SourceMap.Lookup(name, 0)
+	assert.False(t, ok)
+	_, ok = e.SourceMap.Lookup(helpers, 1)
+	assert.False(t, ok, "partials are not rendered")
+	assert.Equal(t, "moby/templates/deployment.yaml:4", Location{name, 4}.String())
+}
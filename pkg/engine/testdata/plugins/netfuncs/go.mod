@@ -0,0 +1,5 @@
+module helm.sh/helm/v4/pkg/engine/testdata/plugins/netfuncs
+
+go 1.25.0
+
+require github.com/extism/go-pdk v1.1.3
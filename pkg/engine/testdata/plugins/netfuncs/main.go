@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	pdk "github.com/extism/go-pdk"
+)
+
+type InputMessageTemplateFuncsV1 struct {
+	Function string `json:"function"`
+	Args     []any  `json:"args"`
+}
+
+type OutputMessageTemplateFuncsV1 struct {
+	Result any    `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// cidrHost returns the address of the host number n in a prefix.
+func cidrHost(args []any) (any, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("expected 2 arguments, got %d", len(args))
+	}
+	s, _ := args[0].(string)
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return nil, err
+	}
+	n, _ := args[1].(float64)
+	addr := prefix.Masked().Addr()
+	for range int(n) {
+		addr = addr.Next()
+	}
+	if !prefix.Contains(addr) {
+		return nil, fmt.Errorf("prefix %s has no host number %d", prefix, int(n))
+	}
+	return addr.String(), nil
+}
+
+// dnsLabel joins its arguments into a lowercase DNS label.
+func dnsLabel(args []any) (any, error) {
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		parts = append(parts, strings.ToLower(fmt.Sprint(arg)))
+	}
+	label := strings.Join(parts, "-")
+	if len(label) > 63 {
+		label = strings.TrimRight(label[:63], "-")
+	}
+	return label, nil
+}
+
+func run(input InputMessageTemplateFuncsV1) OutputMessageTemplateFuncsV1 {
+	var result any
+	var err error
+	switch input.Function {
+	case "cidrHost":
+		result, err = cidrHost(input.Args)
+	case "dnsLabel":
+		result, err = dnsLabel(input.Args)
+	default:
+		err = fmt.Errorf("unknown function %q", input.Function)
+	}
+	if err != nil {
+		return OutputMessageTemplateFuncsV1{Error: err.Error()}
+	}
+	return OutputMessageTemplateFuncsV1{Result: result}
+}
+
+//go:wasmexport helm_plugin_main
+func HelmPlugin() uint32 {
+	var input InputMessageTemplateFuncsV1
+	if err := pdk.InputJSON(&input); err != nil {
+		pdk.SetError(err)
+		return 1
+	}
+	if err := pdk.OutputJSON(run(input)); err != nil {
+		pdk.SetError(err)
+		return 1
+	}
+	return 0
+}
+
+func main() {}
@@ -18,19 +18,48 @@ package gates
 import (
 	"fmt"
 	"os"
+	"sync"
+)
+
+var (
+	mu       sync.RWMutex
+	defaults = map[Gate]bool{}
 )
 
 // Gate is the name of the feature gate.
 type Gate string
 
+// ChartV3 enables charts with apiVersion v3.
+const ChartV3 Gate = "HELM_EXPERIMENTAL_CHART_V3"
+
 // String returns the string representation of this feature gate.
 func (g Gate) String() string {
 	return string(g)
 }
 
 // IsEnabled determines whether a certain feature gate is enabled.
+//
+// The environment variable of the gate takes precedence, a gate is enabled
+// when it is set to a non-empty value. Without it, the default set with
+// SetDefaults applies.
 func (g Gate) IsEnabled() bool {
-	return os.Getenv(string(g)) != ""
+	if v, ok := os.LookupEnv(string(g)); ok {
+		return v != ""
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaults[g]
+}
+
+// SetDefaults replaces the defaults of the gates whose environment variable
+// is not set, e.g. with the gates enabled in the Helm configuration file.
+func SetDefaults(gates map[Gate]bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaults = make(map[Gate]bool, len(gates))
+	for g, enabled := range gates {
+		defaults[g] = enabled
+	}
 }
 
 func (g Gate) Error() error {
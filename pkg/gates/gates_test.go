@@ -53,3 +53,19 @@ func TestString(t *testing.T) {
 		t.Errorf("incorrect string representation. Received %s", g.String())
 	}
 }
+
+func TestSetDefaults(t *testing.T) {
+	os.Unsetenv(name)
+	g := Gate(name)
+	defer SetDefaults(nil)
+
+	SetDefaults(map[Gate]bool{g: true})
+	if !g.IsEnabled() {
+		t.Errorf("feature gate shows as disabled, but it is enabled by default")
+	}
+
+	t.Setenv(name, "")
+	if g.IsEnabled() {
+		t.Errorf("feature gate shows as available, but the environment variable %s is empty", name)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/structured-merge-diff/v4/value"
+)
+
+// keyName matches the names of the keys of a list item selector, e.g. "name"
+// and "protocol" in `name="web",protocol="TCP"`.
+var keyName = regexp.MustCompile(`(?:^|,)([A-Za-z0-9_-]+)=`)
+
+// fieldLinePath converts the path of a field of an object, as reported by
+// structured-merge-diff, e.g. `.spec.containers[name="web"].image`, to the
+// path format of releaseutil.FieldLine, e.g. "spec.containers[0].image". List
+// items selected by keys or values are looked up in the object. The path is
+// cut at the first element which cannot be resolved.
+func fieldLinePath(path string, obj interface{}) string {
+	var segments []string
+	cur := obj
+	for path != "" {
+		switch path[0] {
+		case '.':
+			end := strings.IndexAny(path[1:], ".[")
+			if end < 0 {
+				end = len(path) - 1
+			}
+			name := path[1 : end+1]
+			path = path[end+1:]
+			segments = append(segments, name)
+			m, _ := cur.(map[string]interface{})
+			cur = m[name]
+		case '[':
+			end := closingBracket(path)
+			if end < 0 || len(segments) == 0 {
+				return strings.Join(segments, ".")
+			}
+			selector := path[1:end]
+			path = path[end+1:]
+			list, _ := cur.([]interface{})
+			index := listIndex(list, selector)
+			if index < 0 {
+				return strings.Join(segments, ".")
+			}
+			segments[len(segments)-1] += fmt.Sprintf("[%d]", index)
+			cur = list[index]
+		default:
+			return strings.Join(segments, ".")
+		}
+	}
+	return strings.Join(segments, ".")
+}
+
+// closingBracket returns the index of the bracket closing the one a path
+// starts with, skipping quoted values.
+func closingBracket(path string) int {
+	quoted := false
+	for i := 1; i < len(path); i++ {
+		switch {
+		case quoted && path[i] == '\\':
+			i++
+		case path[i] == '"':
+			quoted = !quoted
+		case !quoted && path[i] == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// listIndex returns the index of the item of a list a selector refers to: an
+// index, e.g. "0", a value, e.g. `="a"`, or keys, e.g. `name="web"`. It
+// returns -1 when there is no such item.
+func listIndex(list []interface{}, selector string) int {
+	if i, err := strconv.Atoi(selector); err == nil {
+		if i >= 0 && i < len(list) {
+			return i
+		}
+		return -1
+	}
+	if v, ok := strings.CutPrefix(selector, "="); ok {
+		for i, item := range list {
+			if value.ToString(value.NewValueInterface(item)) == v {
+				return i
+			}
+		}
+		return -1
+	}
+
+	// Keys with a default value, e.g. the protocol of a port, are part of
+	// the selector even when the item does not set them.
+	keys := map[string]string{}
+	matches := keyName.FindAllStringSubmatchIndex(selector, -1)
+	for j, m := range matches {
+		end := len(selector)
+		if j+1 < len(matches) {
+			end = matches[j+1][0]
+		}
+		keys[selector[m[2]:m[3]]] = selector[m[1]:end]
+	}
+	for i, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		match := true
+		for name, v := range keys {
+			if field, ok := m[name]; ok && value.ToString(value.NewValueInterface(field)) != v {
+				match = false
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
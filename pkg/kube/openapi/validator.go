@@ -0,0 +1,400 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package openapi validates Kubernetes objects offline against the OpenAPI
+schemas of their kinds.
+
+The schemas of the built-in kinds are bundled with Helm for a single
+Kubernetes version, the one of the client-go module it is built with. Other
+versions require an OpenAPI spec, e.g. downloaded from a cluster with
+'kubectl get --raw /openapi/v2', which replaces them. The schemas of custom
+resources come from CustomResourceDefinitions.
+*/
+package openapi // import "helm.sh/helm/v4/pkg/kube/openapi"
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/managedfields"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/applyconfigurations"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/structured-merge-diff/v4/typed"
+	"sigs.k8s.io/yaml"
+
+	releaseutil "helm.sh/helm/v4/pkg/release/util"
+)
+
+// ErrNoSchema is returned when validating an object of a kind without a
+// schema.
+var ErrNoSchema = errors.New("no schema")
+
+// Options configures the schemas of a Validator.
+type Options struct {
+	// Spec is an OpenAPI v2 or v3 spec, in JSON or YAML, replacing the
+	// bundled schemas. When it is a directory, the spec of the Kubernetes
+	// version is read from it, e.g. "v1.30.json".
+	Spec string
+	// KubeVersion is the Kubernetes version, e.g. "v1.30.0", selecting the
+	// spec of a directory. Without a spec, it must be the version of the
+	// bundled schemas. When empty, the version of the bundled schemas is
+	// used.
+	KubeVersion string
+	// CRDFiles are files of CustomResourceDefinitions whose schemas are
+	// added.
+	CRDFiles []string
+}
+
+// Validator validates Kubernetes objects against the schemas of their kinds.
+type Validator struct {
+	// bundled validates the built-in kinds when no spec is given.
+	bundled managedfields.TypeConverter
+	// kinds validate the kinds of the spec and of the CRDs.
+	kinds map[schema.GroupVersionKind]managedfields.TypeConverter
+}
+
+// New returns a Validator with the bundled schemas, or the schemas of the
+// spec of the options, and the schemas of their CRD files.
+func New(opts Options) (*Validator, error) {
+	v := &Validator{kinds: map[schema.GroupVersionKind]managedfields.TypeConverter{}}
+	if opts.Spec == "" {
+		if err := checkBundledKubeVersion(opts.KubeVersion); err != nil {
+			return nil, err
+		}
+		v.bundled = applyconfigurations.NewTypeConverter(scheme.Scheme)
+	} else {
+		filename, err := specFile(opts.Spec, opts.KubeVersion)
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		if err := v.addSpec(data); err != nil {
+			return nil, fmt.Errorf("unable to load OpenAPI spec %s: %w", filename, err)
+		}
+	}
+
+	for _, filename := range opts.CRDFiles {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		if err := v.addCRDs(data); err != nil {
+			return nil, fmt.Errorf("unable to load CRDs %s: %w", filename, err)
+		}
+	}
+	return v, nil
+}
+
+// specFile returns the spec file of a Kubernetes version given a file or a
+// directory of specs.
+func specFile(path, kubeVersion string) (string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !fi.IsDir() {
+		return path, nil
+	}
+	if kubeVersion == "" {
+		kubeVersion = BundledKubeVersion()
+	}
+	ver, err := semver.NewVersion(kubeVersion)
+	if err != nil {
+		return "", fmt.Errorf("invalid kube version %q: %w", kubeVersion, err)
+	}
+	for _, ext := range []string{".json", ".yaml"} {
+		filename := filepath.Join(path, fmt.Sprintf("v%d.%d%s", ver.Major(), ver.Minor(), ext))
+		if _, err := os.Stat(filename); err == nil {
+			return filename, nil
+		}
+	}
+	return "", fmt.Errorf("no OpenAPI spec for Kubernetes v%d.%d in %s", ver.Major(), ver.Minor(), path)
+}
+
+// BundledKubeVersion returns the Kubernetes version of the bundled schemas,
+// e.g. "v1.33", or an empty string when it is unknown.
+func BundledKubeVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != "k8s.io/client-go" {
+			continue
+		}
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		// client-go v0.x.y is released with Kubernetes v1.x.y.
+		ver, err := semver.NewVersion(dep.Version)
+		if err != nil {
+			return ""
+		}
+		return fmt.Sprintf("v1.%d", ver.Minor())
+	}
+	return ""
+}
+
+// checkBundledKubeVersion returns an error when a Kubernetes version is not
+// the one of the bundled schemas.
+func checkBundledKubeVersion(kubeVersion string) error {
+	bundled := BundledKubeVersion()
+	if kubeVersion == "" || bundled == "" {
+		return nil
+	}
+	ver, err := semver.NewVersion(kubeVersion)
+	if err != nil {
+		return fmt.Errorf("invalid kube version %q: %w", kubeVersion, err)
+	}
+	if want := fmt.Sprintf("v%d.%d", ver.Major(), ver.Minor()); want != bundled {
+		return fmt.Errorf("the bundled OpenAPI schemas are for Kubernetes %s, not %s: an OpenAPI spec of %s is required", bundled, want, want)
+	}
+	return nil
+}
+
+// WithCRDs returns a copy of the validator with the schemas of the
+// CustomResourceDefinitions of a YAML stream. Other documents, and CRDs of
+// older API versions, are ignored.
+func (v *Validator) WithCRDs(data []byte) (*Validator, error) {
+	c := &Validator{bundled: v.bundled, kinds: maps.Clone(v.kinds)}
+	if err := c.addCRDs(data); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// addSpec adds the schemas of the kinds of an OpenAPI v2 or v3 spec.
+func (v *Validator) addSpec(data []byte) error {
+	var doc struct {
+		Definitions map[string]*spec.Schema `json:"definitions"`
+		Components  struct {
+			Schemas map[string]*spec.Schema `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	schemas := doc.Definitions
+	if len(schemas) == 0 {
+		schemas = doc.Components.Schemas
+	}
+	if len(schemas) == 0 {
+		return errors.New("no schema definitions found")
+	}
+	return v.addSchemas(schemas)
+}
+
+// addCRDs adds the schemas of the served versions of the
+// CustomResourceDefinitions of a YAML stream.
+func (v *Validator) addCRDs(data []byte) error {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		var crd apiextensionsv1.CustomResourceDefinition
+		err := decoder.Decode(&crd)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// CRDs of apiextensions.k8s.io/v1beta1 are no longer served.
+		if crd.Kind != "CustomResourceDefinition" || crd.APIVersion != apiextensionsv1.SchemeGroupVersion.String() {
+			continue
+		}
+
+		schemas := map[string]*spec.Schema{}
+		for _, ver := range crd.Spec.Versions {
+			if !ver.Served || ver.Schema == nil || ver.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			s, err := crdSchema(ver.Schema.OpenAPIV3Schema)
+			if err != nil {
+				return fmt.Errorf("CustomResourceDefinition %q version %q: %w", crd.Name, ver.Name, err)
+			}
+			s.AddExtension("x-kubernetes-group-version-kind", []interface{}{map[string]interface{}{
+				"group":   crd.Spec.Group,
+				"version": ver.Name,
+				"kind":    crd.Spec.Names.Kind,
+			}})
+			schemas[fmt.Sprintf("%s.%s.%s", crd.Spec.Group, ver.Name, crd.Spec.Names.Kind)] = s
+		}
+		if err := v.addSchemas(schemas); err != nil {
+			return fmt.Errorf("CustomResourceDefinition %q: %w", crd.Name, err)
+		}
+	}
+}
+
+// crdSchema returns the schema of a version of a CRD, with the fields every
+// object has.
+func crdSchema(props *apiextensionsv1.JSONSchemaProps) (*spec.Schema, error) {
+	data, err := json.Marshal(props)
+	if err != nil {
+		return nil, err
+	}
+	s := &spec.Schema{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Properties == nil {
+		s.Properties = map[string]spec.Schema{}
+	}
+	for _, name := range []string{"apiVersion", "kind"} {
+		if _, ok := s.Properties[name]; !ok {
+			s.Properties[name] = *spec.StringProperty()
+		}
+	}
+	// The API server validates the metadata of custom resources as the one
+	// of any object.
+	metadata := spec.MapProperty(nil)
+	metadata.AddExtension("x-kubernetes-preserve-unknown-fields", true)
+	s.Properties["metadata"] = *metadata
+	return s, nil
+}
+
+// addSchemas adds the schemas of the kinds of OpenAPI definitions.
+func (v *Validator) addSchemas(schemas map[string]*spec.Schema) error {
+	converter, err := managedfields.NewTypeConverter(schemas, false)
+	if err != nil {
+		return err
+	}
+	for _, s := range schemas {
+		gvks, ok := s.Extensions["x-kubernetes-group-version-kind"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, gvk := range gvks {
+			m, ok := gvk.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			group, _ := m["group"].(string)
+			version, _ := m["version"].(string)
+			kind, _ := m["kind"].(string)
+			if kind != "" {
+				v.kinds[schema.GroupVersionKind{Group: group, Version: version, Kind: kind}] = converter
+			}
+		}
+	}
+	return nil
+}
+
+// unstructuredValue matches the values structured-merge-diff prints in its
+// errors, e.g. "&value.valueUnstructured{Value:3}" or "&{no}".
+var unstructuredValue = regexp.MustCompile(`&(?:value\.valueUnstructured)?\{(?:Value:)?(.*?)\}`)
+
+// FieldError is an error about a field of an object.
+type FieldError struct {
+	// Path is the path of the field, e.g.
+	// `.spec.template.spec.containers[name="web"].image`.
+	Path string
+	// Field is the path of the field in the format of
+	// releaseutil.FieldLine, e.g. "spec.template.spec.containers[0].image".
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Validate validates an object against the schema of its kind. ErrNoSchema
+// is returned for a kind without a schema.
+func (v *Validator) Validate(obj map[string]interface{}) ([]FieldError, error) {
+	u := &unstructured.Unstructured{Object: obj}
+	gvk := u.GroupVersionKind()
+	converter, ok := v.kinds[gvk]
+	if !ok && v.bundled != nil && scheme.Scheme.Recognizes(gvk) {
+		converter, ok = v.bundled, true
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w for %s", ErrNoSchema, gvk)
+	}
+
+	_, err := converter.ObjectToTyped(u)
+	if err == nil {
+		return nil, nil
+	}
+	var verrs typed.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return []FieldError{{Message: err.Error()}}, nil
+	}
+	errs := make([]FieldError, 0, len(verrs))
+	for _, e := range verrs {
+		errs = append(errs, FieldError{Path: e.Path, Field: fieldLinePath(e.Path, obj), Message: unstructuredValue.ReplaceAllString(e.ErrorMessage, "$1")})
+	}
+	slices.SortStableFunc(errs, func(a, b FieldError) int { return strings.Compare(a.Path, b.Path) })
+	return errs, nil
+}
+
+// ValidateManifest validates the objects of a rendered manifest. The errors
+// of each object are reported with its source template. Objects without a
+// kind, or of a kind without a schema, are not validated.
+func (v *Validator) ValidateManifest(manifest string) error {
+	var errs []error
+	for _, doc := range releaseutil.SplitDocuments(manifest) {
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc.Content), &obj); err != nil || obj == nil {
+			continue
+		}
+		ferrs, err := v.Validate(obj)
+		if errors.Is(err, ErrNoSchema) {
+			continue
+		}
+		u := &unstructured.Unstructured{Object: obj}
+		name := fmt.Sprintf("%s %q", u.GetKind(), u.GetName())
+		if source := documentSource(doc.Content); source != "" {
+			name = source + ": " + name
+		}
+		for _, e := range ferrs {
+			errs = append(errs, fmt.Errorf("%s: %w", name, e))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// documentSource returns the template a rendered document comes from, given
+// by its "# Source:" comment.
+func documentSource(doc string) string {
+	for line := range strings.SplitSeq(doc, "\n") {
+		if source, ok := strings.CutPrefix(line, "# Source: "); ok {
+			return strings.TrimSpace(source)
+		}
+	}
+	return ""
+}
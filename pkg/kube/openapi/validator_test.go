@@ -0,0 +1,218 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func object(t *testing.T, doc string) map[string]interface{} {
+	t.Helper()
+	var obj map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(doc), &obj))
+	return obj
+}
+
+func TestValidateBundled(t *testing.T) {
+	v, err := New(Options{})
+	require.NoError(t, err)
+
+	errs, err := v.Validate(object(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: "3"
+  paused: "no"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []FieldError{
+		{Path: ".spec.paused", Field: "spec.paused", Message: "expected boolean, got no"},
+		{Path: ".spec.replicas", Field: "spec.replicas", Message: "expected numeric (int or float), got string"},
+	}, errs)
+
+	errs, err = v.Validate(object(t, "apiVersion: apps/v1\nkind: Deployment\nspec:\n  templat: {}\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []FieldError{{Path: ".spec.templat", Field: "spec.templat", Message: "field not declared in schema"}}, errs)
+
+	errs, err = v.Validate(object(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: sidecar
+          image: busybox
+        - name: web
+          image: 3
+          ports:
+            - containerPort: 80
+              hostPrt: 8080
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []FieldError{
+		{Path: `.spec.template.spec.containers[name="web"].image`, Field: "spec.template.spec.containers[1].image", Message: "expected string, got 3"},
+		{Path: `.spec.template.spec.containers[name="web"].ports[containerPort=80,protocol="TCP"].hostPrt`, Field: "spec.template.spec.containers[1].ports[0].hostPrt", Message: "field not declared in schema"},
+	}, errs)
+
+	errs, err = v.Validate(object(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\ndata:\n  key: value\n"))
+	assert.NoError(t, err)
+	assert.Empty(t, errs)
+
+	_, err = v.Validate(object(t, "apiVersion: stable.example.com/v1\nkind: CronTab\n"))
+	assert.ErrorIs(t, err, ErrNoSchema)
+}
+
+func TestValidateCRDs(t *testing.T) {
+	v, err := New(Options{CRDFiles: []string{"testdata/crontab-crd.yaml"}})
+	require.NoError(t, err)
+
+	errs, err := v.Validate(object(t, `
+apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: backup
+  labels:
+    app: backup
+spec:
+  cronSpec: "* * * * */5"
+  replicas: two
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []FieldError{
+		{Path: ".spec.replicas", Field: "spec.replicas", Message: "expected numeric (int or float), got string"},
+	}, errs)
+
+	errs, err = v.Validate(object(t, "apiVersion: stable.example.com/v1\nkind: CronTab\nspec:\n  image: backup\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []FieldError{{Path: ".spec.image", Field: "spec.image", Message: "field not declared in schema"}}, errs)
+
+	_, err = v.Validate(object(t, "apiVersion: stable.example.com/v2\nkind: CronTab\n"))
+	assert.ErrorIs(t, err, ErrNoSchema)
+}
+
+func TestWithCRDs(t *testing.T) {
+	v, err := New(Options{})
+	require.NoError(t, err)
+	data, err := os.ReadFile("testdata/crontab-crd.yaml")
+	require.NoError(t, err)
+
+	withCRDs, err := v.WithCRDs(data)
+	require.NoError(t, err)
+	crontab := object(t, "apiVersion: stable.example.com/v1\nkind: CronTab\nmetadata:\n  name: backup\n")
+	_, err = withCRDs.Validate(crontab)
+	assert.NoError(t, err)
+	_, err = v.Validate(crontab)
+	assert.ErrorIs(t, err, ErrNoSchema, "the CRDs must not be added to the original validator")
+}
+
+func TestValidateSpec(t *testing.T) {
+	_, err := New(Options{Spec: "testdata/specs", KubeVersion: "v1.29.0"})
+	assert.ErrorContains(t, err, "no OpenAPI spec for Kubernetes v1.29 in testdata/specs")
+
+	for _, opts := range []Options{
+		{Spec: "testdata/specs", KubeVersion: "v1.30.2"},
+		{Spec: "testdata/specs/v1.30.yaml"},
+	} {
+		v, err := New(opts)
+		require.NoError(t, err)
+
+		errs, err := v.Validate(object(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  nme: typo\ndata:\n  key: 1\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []FieldError{
+			{Path: ".data.key", Field: "data.key", Message: "expected string, got 1"},
+			{Path: ".metadata.nme", Field: "metadata.nme", Message: "field not declared in schema"},
+		}, errs)
+
+		_, err = v.Validate(object(t, "apiVersion: apps/v1\nkind: Deployment\n"))
+		assert.ErrorIs(t, err, ErrNoSchema, "a spec replaces the bundled schemas")
+	}
+}
+
+func TestValidateManifest(t *testing.T) {
+	v, err := New(Options{})
+	require.NoError(t, err)
+
+	manifest := `---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+    - port: "http"
+---
+# Source: web/templates/crontab.yaml
+apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: backup
+spec:
+  unknown: true
+---
+# Source: web/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`
+	err = v.ValidateManifest(manifest)
+	assert.EqualError(t, err, `web/templates/service.yaml: Service "web": .spec.ports[port="http",protocol="TCP"].port: expected numeric (int or float), got string`)
+}
+
+func TestFieldLinePath(t *testing.T) {
+	obj := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"finalizers": []interface{}{"a", "b"},
+			"ports": []interface{}{
+				map[string]interface{}{"port": int64(80), "protocol": "UDP"},
+				map[string]interface{}{"port": int64(80)},
+			},
+		},
+	}
+	for path, want := range map[string]string{
+		".spec.finalizers[=\"b\"]":                 "spec.finalizers[1]",
+		".spec.finalizers[1]":                      "spec.finalizers[1]",
+		".spec.ports[port=80,protocol=\"TCP\"].x":  "spec.ports[1].x",
+		".spec.ports[port=80,protocol=\"UDP\"]":    "spec.ports[0]",
+		".spec.ports[port=443,protocol=\"TCP\"].x": "spec.ports",
+		".spec.unknown[0]":                         "spec.unknown",
+	} {
+		assert.Equal(t, want, fieldLinePath(path, obj), path)
+	}
+}
+
+func TestBundledKubeVersion(t *testing.T) {
+	bundled := BundledKubeVersion()
+	require.Regexp(t, `^v1\.\d+$`, bundled)
+
+	_, err := New(Options{KubeVersion: bundled + ".3"})
+	assert.NoError(t, err)
+
+	_, err = New(Options{KubeVersion: "v1.20.0"})
+	assert.EqualError(t, err, "the bundled OpenAPI schemas are for Kubernetes "+bundled+", not v1.20: an OpenAPI spec of v1.20 is required")
+}
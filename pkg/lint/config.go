@@ -0,0 +1,187 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint // import "helm.sh/helm/v4/pkg/lint"
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/lint/rules"
+	"helm.sh/helm/v4/pkg/lint/support"
+)
+
+// ConfigFileName is the name of the lint configuration file of a chart.
+const ConfigFileName = ".helmlint.yaml"
+
+// Config configures how a chart is linted:
+//
+//	rules:
+//	  icon-missing:
+//	    severity: error
+//	  template-indent:
+//	    enabled: false
+//	  privileged-container:
+//	    enabled: true
+//	matrix:
+//	  values:
+//	    - ci/small-values.yaml
+//	    - ci/large-values.yaml
+//	  kubeVersions:
+//	    - 1.29.0
+//	    - 1.33.0
+//	ignore:
+//	  - templates/legacy/
+//	  - templates/*-test.yaml
+type Config struct {
+	// Rules configures the rules, by identifier.
+	Rules map[string]RuleConfig `json:"rules,omitempty"`
+	// Matrix lists the values files and Kubernetes versions the chart is
+	// linted against, in every combination.
+	Matrix Matrix `json:"matrix,omitempty"`
+	// Ignore lists the files of the chart messages are ignored for, as
+	// patterns of path.Match. A pattern ending with a slash ignores a
+	// directory.
+	Ignore []string `json:"ignore,omitempty"`
+}
+
+// RuleConfig configures a rule.
+type RuleConfig struct {
+	// Enabled disables a rule when false, and enables an opt-in rule, e.g.
+	// a workload rule, when true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Severity replaces the severity of the messages of the rule: "info",
+	// "warning" or "error".
+	Severity string `json:"severity,omitempty"`
+}
+
+// Matrix lists the values files and Kubernetes versions a chart is linted
+// against. Without values files, the chart is linted with the given values
+// only, and without Kubernetes versions, for the default one.
+type Matrix struct {
+	// Values are values files, relative to the configuration file. The
+	// values given on the command line take precedence.
+	Values []string `json:"values,omitempty"`
+	// KubeVersions are Kubernetes versions, e.g. "1.30.0". A Kubernetes
+	// version given on the command line replaces them.
+	KubeVersions []string `json:"kubeVersions,omitempty"`
+}
+
+// LoadConfig reads a lint configuration from a file. The values files of its
+// matrix are resolved relative to the directory of the file.
+func LoadConfig(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Config{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("unable to parse lint configuration %s: %w", filename, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("invalid lint configuration %s: %w", filename, err)
+	}
+
+	dir := filepath.Dir(filename)
+	for i, v := range c.Matrix.Values {
+		if !filepath.IsAbs(v) {
+			c.Matrix.Values[i] = filepath.Join(dir, v)
+		}
+	}
+	return c, nil
+}
+
+// validate checks that the rules are known and their severities valid.
+// Identifiers of rules of lint plugins, "<plugin>/<rule>", are not checked.
+func (c *Config) validate() error {
+	for id, r := range c.Rules {
+		if _, ok := rules.RuleDescriptions[id]; !ok && !strings.Contains(id, "/") {
+			return fmt.Errorf("unknown rule %q", id)
+		}
+		if r.Severity != "" {
+			if _, err := support.ParseSeverity(r.Severity); err != nil {
+				return fmt.Errorf("rule %q: %w", id, err)
+			}
+		}
+	}
+	for _, v := range c.Matrix.KubeVersions {
+		if _, err := chartutil.ParseKubeVersion(v); err != nil {
+			return fmt.Errorf("invalid kube version %q: %w", v, err)
+		}
+	}
+	for _, p := range c.Ignore {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid ignore pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// EnablesWorkloadRules reports whether any of the workload rules is enabled.
+func (c *Config) EnablesWorkloadRules() bool {
+	for id, r := range c.Rules {
+		if r.Enabled != nil && *r.Enabled && slices.Contains(rules.WorkloadRuleIDs, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply applies the configuration to lint messages: the messages of disabled
+// rules and of ignored files are dropped, and the severities of the rules
+// are replaced. When not all workload rules are enabled, only the messages of
+// the workload rules the configuration enables are kept.
+func (c *Config) Apply(msgs []support.Message, workloadRules bool) []support.Message {
+	var out []support.Message
+	for _, msg := range msgs {
+		r, configured := c.Rules[msg.RuleID]
+		switch {
+		case configured && r.Enabled != nil && !*r.Enabled:
+			continue
+		case !workloadRules && slices.Contains(rules.WorkloadRuleIDs, msg.RuleID) && (!configured || r.Enabled == nil || !*r.Enabled):
+			continue
+		case c.ignores(msg):
+			continue
+		}
+		if r.Severity != "" {
+			msg.Severity, _ = support.ParseSeverity(r.Severity)
+		}
+		out = append(out, msg)
+	}
+	return out
+}
+
+// ignores reports whether the file of a message is ignored.
+func (c *Config) ignores(msg support.Message) bool {
+	file, _, _ := msg.Location()
+	for _, p := range c.Ignore {
+		if strings.HasSuffix(p, "/") && strings.HasPrefix(file, p) {
+			return true
+		}
+		if ok, _ := path.Match(p, file); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/lint/rules"
+	"helm.sh/helm/v4/pkg/lint/support"
+)
+
+func TestLoadConfig(t *testing.T) {
+	c, err := LoadConfig("testdata/config/valid.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join("testdata", "config", "ci", "values.yaml")}, c.Matrix.Values)
+	assert.Equal(t, []string{"1.30.0"}, c.Matrix.KubeVersions)
+	assert.True(t, c.EnablesWorkloadRules())
+
+	for name, msg := range map[string]string{
+		"unknown-rule":         `unknown rule "no-such-rule"`,
+		"invalid-severity":     `rule "icon-missing": invalid severity "fatal"`,
+		"invalid-kube-version": `invalid kube version "not-a-version"`,
+		"unknown-field":        `unknown field "rule"`,
+	} {
+		_, err := LoadConfig(filepath.Join("testdata", "config", name+".yaml"))
+		assert.ErrorContains(t, err, msg, name)
+	}
+}
+
+func TestConfigApply(t *testing.T) {
+	c, err := LoadConfig("testdata/config/valid.yaml")
+	require.NoError(t, err)
+
+	msg := func(rule string, severity int, path string) support.Message {
+		return support.Message{RuleID: rule, Severity: severity, Path: path, Err: errors.New(rule)}
+	}
+	msgs := []support.Message{
+		msg(rules.RuleIconMissing, support.InfoSev, "Chart.yaml"),
+		msg(rules.RuleTemplateIndent, support.WarningSev, "templates/deployment.yaml"),
+		msg(rules.RuleHostNetwork, support.WarningSev, "templates/deployment.yaml:12"),
+		msg(rules.RuleRunAsRoot, support.WarningSev, "templates/deployment.yaml:20"),
+		msg("my-plugin/my-rule", support.ErrorSev, "templates/"),
+		msg(rules.RuleMetadataName, support.WarningSev, "templates/legacy/configmap.yaml:3"),
+		msg(rules.RuleMetadataName, support.WarningSev, "templates/web-test.yaml"),
+		msg(rules.RuleMetadataName, support.WarningSev, "templates/web.yaml"),
+	}
+
+	assert.Equal(t, []support.Message{
+		msg(rules.RuleIconMissing, support.ErrorSev, "Chart.yaml"),
+		msg(rules.RuleHostNetwork, support.WarningSev, "templates/deployment.yaml:12"),
+		msg("my-plugin/my-rule", support.InfoSev, "templates/"),
+		msg(rules.RuleMetadataName, support.WarningSev, "templates/web.yaml"),
+	}, c.Apply(msgs, false))
+
+	// With all the workload rules enabled, only the disabled ones are dropped.
+	assert.Equal(t, []support.Message{
+		msg(rules.RuleIconMissing, support.ErrorSev, "Chart.yaml"),
+		msg(rules.RuleHostNetwork, support.WarningSev, "templates/deployment.yaml:12"),
+		msg(rules.RuleRunAsRoot, support.WarningSev, "templates/deployment.yaml:20"),
+		msg("my-plugin/my-rule", support.InfoSev, "templates/"),
+		msg(rules.RuleMetadataName, support.WarningSev, "templates/web.yaml"),
+	}, c.Apply(msgs, true))
+}
@@ -0,0 +1,364 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint // import "helm.sh/helm/v4/pkg/lint"
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	v3 "helm.sh/helm/v4/internal/chart/v3"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/lint/rules"
+)
+
+// Fix is a change FixChart made to a file of a chart.
+type Fix struct {
+	// RuleID is the identifier of the rule whose finding the change fixes.
+	RuleID string
+	// Path is the file and line changed, e.g. "Chart.yaml:3".
+	Path string
+	// Description describes the change.
+	Description string
+}
+
+func (f Fix) String() string {
+	return fmt.Sprintf("[FIXED] %s: %s", f.Path, f.Description)
+}
+
+// FixChart rewrites the Chart.yaml and values.yaml files of a chart
+// directory in place, fixing the findings of the rules which are mechanical:
+//
+//   - a version or appVersion which is not a string is quoted,
+//   - an apiVersion spelled differently is normalized, e.g. "V2" to "v2", and
+//     a missing one set to v1 when the chart has a requirements.yaml file, and
+//     v2 otherwise. An unknown apiVersion is left to be reported,
+//   - an icon under a misspelled key, e.g. "iconURL", is moved to icon,
+//   - a value of values.yaml the values schema wants of another type, a
+//     string, integer, number or boolean, is converted when that keeps its
+//     meaning, e.g. the number 1.10 is quoted when a string is wanted.
+//
+// Only the fixed tokens are rewritten: the comments, order and formatting of
+// the files are kept. Files which cannot be parsed, and the findings which
+// cannot be fixed safely, are left as they are. Missing files are skipped;
+// the other errors reading them are returned.
+func FixChart(chartDir string) ([]Fix, error) {
+	fixes, err := fixFile(chartDir, "Chart.yaml", chartFileEdits)
+	if err != nil {
+		return nil, err
+	}
+	valuesFixes, err := fixFile(chartDir, "values.yaml", valuesFileEdits)
+	if err != nil {
+		return nil, err
+	}
+	return append(fixes, valuesFixes...), nil
+}
+
+// edit replaces a token of a file, at a line and column starting at 1.
+type edit struct {
+	line, column int
+	old, new     string
+	fix          Fix
+}
+
+// fixFile applies the edits of a file of a chart, and returns the fixes made.
+func fixFile(chartDir, name string, edits func(chartDir string, root *yaml.Node) ([]edit, error)) ([]Fix, error) {
+	filename := filepath.Join(chartDir, name)
+	fi, err := os.Stat(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	fileEdits, err := edits(chartDir, doc.Content[0])
+	if err != nil {
+		return nil, err
+	}
+	out, fixes := applyEdits(string(data), fileEdits)
+	if len(fixes) == 0 {
+		return nil, nil
+	}
+	for i := range fixes {
+		fixes[i].Path = fmt.Sprintf("%s:%s", name, fixes[i].Path)
+	}
+	if err := os.WriteFile(filename, []byte(out), fi.Mode().Perm()); err != nil {
+		return nil, err
+	}
+	return fixes, nil
+}
+
+// applyEdits applies edits to the content of a file. An edit is skipped when
+// the content does not have the expected token at its position, e.g. for a
+// value spanning several lines. The fixes of the edits made are returned in
+// the order of the file, with their line in the new content as path.
+func applyEdits(content string, edits []edit) (string, []Fix) {
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].line != edits[j].line {
+			return edits[i].line > edits[j].line
+		}
+		return edits[i].column > edits[j].column
+	})
+	lines := strings.SplitAfter(content, "\n")
+	var applied []edit
+	for _, e := range edits {
+		if e.line < 1 || e.line > len(lines) {
+			continue
+		}
+		line := []rune(lines[e.line-1])
+		if e.column < 1 || e.column > len(line)+1 {
+			continue
+		}
+		before, after := string(line[:e.column-1]), string(line[e.column-1:])
+		if !strings.HasPrefix(after, e.old) {
+			continue
+		}
+		lines[e.line-1] = before + e.new + strings.TrimPrefix(after, e.old)
+		applied = append([]edit{e}, applied...)
+	}
+
+	fixes := make([]Fix, 0, len(applied))
+	added := 0
+	for _, e := range applied {
+		e.fix.Path = fmt.Sprint(e.line + added)
+		fixes = append(fixes, e.fix)
+		added += strings.Count(e.new, "\n") - strings.Count(e.old, "\n")
+	}
+	return strings.Join(lines, ""), fixes
+}
+
+// scalarToken returns the token of a scalar in its file, as far as it can
+// be known from the node.
+func scalarToken(n *yaml.Node) string {
+	switch n.Style {
+	case yaml.DoubleQuotedStyle:
+		return `"` + n.Value + `"`
+	case yaml.SingleQuotedStyle:
+		return `'` + n.Value + `'`
+	}
+	return n.Value
+}
+
+// replaceScalar returns the edit replacing a scalar with a new token.
+func replaceScalar(n *yaml.Node, token string, fix Fix) edit {
+	return edit{line: n.Line, column: n.Column, old: scalarToken(n), new: token, fix: fix}
+}
+
+// mappingValue returns the key and value nodes of a key of a mapping.
+func mappingValue(m *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i], m.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// isPlainScalar reports whether a node is an unquoted scalar of one of the
+// given tags.
+func isPlainScalar(n *yaml.Node, tags ...string) bool {
+	if n.Kind != yaml.ScalarNode || n.Style != 0 || strings.Contains(n.Value, "\n") {
+		return false
+	}
+	for _, tag := range tags {
+		if n.ShortTag() == tag {
+			return true
+		}
+	}
+	return false
+}
+
+var iconKeys = map[string]bool{"icon": true, "iconurl": true}
+
+func chartFileEdits(chartDir string, root *yaml.Node) ([]edit, error) {
+	var edits []edit
+
+	for _, field := range []struct{ key, rule string }{
+		{"version", rules.RuleChartVersionType},
+		{"appVersion", rules.RuleChartAppVersionType},
+	} {
+		if _, v := mappingValue(root, field.key); v != nil && isPlainScalar(v, "!!int", "!!float", "!!bool") {
+			edits = append(edits, replaceScalar(v, `"`+v.Value+`"`, Fix{
+				RuleID:      field.rule,
+				Description: fmt.Sprintf("%s %s quoted as a string", field.key, v.Value),
+			}))
+		}
+	}
+
+	switch k, v := mappingValue(root, "apiVersion"); {
+	case k == nil:
+		// A key is inserted before the first one, when it starts its line.
+		if len(root.Content) > 0 && root.Content[0].Column == 1 {
+			apiVersion, err := inferAPIVersion(chartDir, root)
+			if err != nil {
+				return nil, err
+			}
+			edits = append(edits, edit{line: root.Content[0].Line, column: 1, new: "apiVersion: " + apiVersion + "\n", fix: Fix{
+				RuleID:      rules.RuleChartAPIVersion,
+				Description: fmt.Sprintf("apiVersion %s added", apiVersion),
+			}})
+		}
+	case v.Kind == yaml.ScalarNode && v.ShortTag() != "!!null":
+		if v.Value == chart.APIVersionV1 || v.Value == chart.APIVersionV2 || v.Value == v3.APIVersionV3 {
+			break
+		}
+		var apiVersion string
+		switch strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(v.Value)), "v"), ".0") {
+		case "1":
+			apiVersion = chart.APIVersionV1
+		case "2":
+			apiVersion = chart.APIVersionV2
+		}
+		// Which version an unknown one was meant to be cannot be told, so it
+		// is left to be reported.
+		if apiVersion != "" {
+			edits = append(edits, replaceScalar(v, apiVersion, Fix{
+				RuleID:      rules.RuleChartAPIVersion,
+				Description: fmt.Sprintf("apiVersion %q replaced with %s", v.Value, apiVersion),
+			}))
+		}
+	}
+
+	if k, _ := mappingValue(root, "icon"); k == nil {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			k, v := root.Content[i], root.Content[i+1]
+			normalized := strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(k.Value))
+			if iconKeys[normalized] && isPlainOrQuotedString(v) && isPlainScalar(k, "!!str") {
+				edits = append(edits, replaceScalar(k, "icon", Fix{
+					RuleID:      rules.RuleIconMissing,
+					Description: fmt.Sprintf("icon under the key %q moved to icon", k.Value),
+				}))
+				break
+			}
+		}
+	}
+	return edits, nil
+}
+
+func isPlainOrQuotedString(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.ShortTag() == "!!str" && n.Value != ""
+}
+
+// inferAPIVersion returns the apiVersion of a chart without one: v1 when it
+// has a requirements.yaml file and uses no feature of v2, v2 otherwise. Both
+// behave the same for charts without dependencies.
+func inferAPIVersion(chartDir string, root *yaml.Node) (string, error) {
+	_, err := os.Stat(filepath.Join(chartDir, "requirements.yaml"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return chart.APIVersionV2, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	for _, key := range []string{"dependencies", "type"} {
+		if k, _ := mappingValue(root, key); k != nil {
+			return chart.APIVersionV2, nil
+		}
+	}
+	return chart.APIVersionV1, nil
+}
+
+var (
+	integerLiteral = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+	numberLiteral  = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+)
+
+func valuesFileEdits(chartDir string, root *yaml.Node) ([]edit, error) {
+	data, err := os.ReadFile(filepath.Join(chartDir, "values.schema.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, nil
+	}
+	var edits []edit
+	valueEdits("", root, schema, &edits)
+	return edits, nil
+}
+
+// valueEdits adds the edits converting the values of a node, at a path, to
+// the types their schema wants.
+func valueEdits(path string, n *yaml.Node, schema map[string]interface{}, edits *[]edit) {
+	if schema == nil {
+		return
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		properties, _ := schema["properties"].(map[string]interface{})
+		additional, _ := schema["additionalProperties"].(map[string]interface{})
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+			s, ok := properties[key].(map[string]interface{})
+			if !ok {
+				s = additional
+			}
+			valueEdits(strings.TrimPrefix(path+"."+key, "."), n.Content[i+1], s, edits)
+		}
+	case yaml.SequenceNode:
+		items, _ := schema["items"].(map[string]interface{})
+		for i, item := range n.Content {
+			valueEdits(fmt.Sprintf("%s[%d]", path, i), item, items, edits)
+		}
+	case yaml.ScalarNode:
+		want := schemaType(schema)
+		fix := Fix{RuleID: rules.RuleValuesInvalid, Description: fmt.Sprintf("%s %s converted to type %s, as the values schema wants", path, scalarToken(n), want)}
+		switch {
+		case want == "string" && isPlainScalar(n, "!!int", "!!float", "!!bool"):
+			*edits = append(*edits, replaceScalar(n, `"`+n.Value+`"`, fix))
+		case n.ShortTag() != "!!str" || n.Style == 0:
+		case want == "integer" && integerLiteral.MatchString(n.Value),
+			want == "number" && numberLiteral.MatchString(n.Value),
+			want == "boolean" && (n.Value == "true" || n.Value == "false"):
+			*edits = append(*edits, replaceScalar(n, n.Value, fix))
+		}
+	}
+}
+
+// schemaType returns the type a schema wants, when it wants a single one.
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		if len(t) == 1 {
+			s, _ := t[0].(string)
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,134 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/lint/rules"
+)
+
+func TestFixChart(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.CopyFS(dir, os.DirFS("testdata/fix")))
+
+	fixes, err := FixChart(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []Fix{
+		{RuleID: rules.RuleChartAPIVersion, Path: "Chart.yaml:2", Description: "apiVersion v2 added"},
+		{RuleID: rules.RuleChartVersionType, Path: "Chart.yaml:6", Description: "version 1.0 quoted as a string"},
+		{RuleID: rules.RuleChartAppVersionType, Path: "Chart.yaml:7", Description: "appVersion 1.10 quoted as a string"},
+		{RuleID: rules.RuleIconMissing, Path: "Chart.yaml:8", Description: `icon under the key "iconURL" moved to icon`},
+		{RuleID: rules.RuleValuesInvalid, Path: "values.yaml:4", Description: "image.tag 1.27 converted to type string, as the values schema wants"},
+		{RuleID: rules.RuleValuesInvalid, Path: "values.yaml:6", Description: `service.port "8080" converted to type integer, as the values schema wants`},
+		{RuleID: rules.RuleValuesInvalid, Path: "values.yaml:7", Description: `service.enabled "true" converted to type boolean, as the values schema wants`},
+		{RuleID: rules.RuleValuesInvalid, Path: "values.yaml:10", Description: "replicas '3' converted to type integer, as the values schema wants"},
+	}, fixes)
+
+	chartfile, err := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `# The chart of the web application.
+apiVersion: v2
+name: web
+description: A web application
+# Bumped on every release.
+version: "1.0"
+appVersion: "1.10"   # the version of the image
+icon: https://example.com/icon.png
+maintainers:
+  - name: web team
+    email: not-an-email
+`, string(chartfile))
+
+	valuesfile, err := os.ReadFile(filepath.Join(dir, "values.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `# Image settings.
+image:
+  repository: nginx
+  tag: "1.27" # pinned
+service:
+  port: 8080
+  enabled: true
+  # Either a name or nothing.
+  name: 42
+replicas: 3
+`, string(valuesfile))
+
+	// What cannot be fixed safely is left as a finding.
+	linter := RunAll(dir, values, namespace)
+	var got []string
+	for _, msg := range linter.Messages {
+		got = append(got, msg.RuleID)
+	}
+	assert.Equal(t, []string{rules.RuleChartMaintainer, rules.RuleValuesInvalid, rules.RuleValuesSchema}, got)
+
+	fixes, err = FixChart(dir)
+	require.NoError(t, err)
+	assert.Empty(t, fixes, "a fixed chart must not be changed again")
+}
+
+func TestFixChartAPIVersion(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		chartfile    string
+		requirements bool
+		want         string
+	}{
+		{"valid", "apiVersion: v1\nname: web\n", false, "apiVersion: v1\nname: web\n"},
+		{"upper case", "apiVersion: V2\nname: web\n", false, "apiVersion: v2\nname: web\n"},
+		{"number", "apiVersion: 1\nname: web\n", true, "apiVersion: v1\nname: web\n"},
+		{"quoted", "apiVersion: \"v2.0\"\nname: web\n", false, "apiVersion: v2\nname: web\n"},
+		{"unknown", "apiVersion: foo\nname: web\n", false, "apiVersion: foo\nname: web\n"},
+		{"unknown with requirements", "apiVersion: v4\nname: web\n", true, "apiVersion: v4\nname: web\n"},
+		{"missing with requirements", "name: web\n", true, "apiVersion: v1\nname: web\n"},
+		{"missing with dependencies", "name: web\ndependencies: []\n", true, "apiVersion: v2\nname: web\ndependencies: []\n"},
+		{"empty", "apiVersion:\nname: web\n", false, "apiVersion:\nname: web\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte(tt.chartfile), 0644))
+			if tt.requirements {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "requirements.yaml"), []byte("dependencies: []\n"), 0644))
+			}
+			_, err := FixChart(dir)
+			require.NoError(t, err)
+			chartfile, err := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(chartfile))
+		})
+	}
+}
+
+func TestFixChartErrors(t *testing.T) {
+	// A chart without the files is left as it is.
+	fixes, err := FixChart(t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, fixes)
+
+	// Other errors reading them are returned.
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte("name: web\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "values.yaml"), []byte("replicas: 1\n"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "values.schema.json"), 0755))
+	_, err = FixChart(dir)
+	assert.ErrorContains(t, err, "values.schema.json")
+}
@@ -20,6 +20,8 @@ import (
 	"path/filepath"
 
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/engine"
+	"helm.sh/helm/v4/pkg/kube/openapi"
 	"helm.sh/helm/v4/pkg/lint/rules"
 	"helm.sh/helm/v4/pkg/lint/support"
 )
@@ -27,6 +29,11 @@ import (
 type linterOptions struct {
 	KubeVersion          *chartutil.KubeVersion
 	SkipSchemaValidation bool
+	LookupProvider       engine.ClientProvider
+	PluginDirs           []string
+	RenderConcurrency    int
+	WorkloadRules        bool
+	ManifestSchemas      *openapi.Validator
 }
 
 type LinterOption func(lo *linterOptions)
@@ -43,6 +50,47 @@ func WithSkipSchemaValidation(skipSchemaValidation bool) LinterOption {
 	}
 }
 
+// WithLookupProvider serves the 'lookup' template function from a client
+// provider, e.g. one of fixtures, while linting the templates.
+func WithLookupProvider(clientProvider engine.ClientProvider) LinterOption {
+	return func(lo *linterOptions) {
+		lo.LookupProvider = clientProvider
+	}
+}
+
+// WithPluginDirs loads the template function plugins required by the charts
+// from the given directories while linting the templates, and runs the lint
+// plugins installed there.
+func WithPluginDirs(pluginDirs []string) LinterOption {
+	return func(lo *linterOptions) {
+		lo.PluginDirs = pluginDirs
+	}
+}
+
+// WithRenderConcurrency sets the maximum number of templates rendered at the
+// same time while linting the templates.
+func WithRenderConcurrency(renderConcurrency int) LinterOption {
+	return func(lo *linterOptions) {
+		lo.RenderConcurrency = renderConcurrency
+	}
+}
+
+// WithWorkloadRules checks the rendered workloads for common risks, e.g.
+// privileged containers or images using the latest tag.
+func WithWorkloadRules(workloadRules bool) LinterOption {
+	return func(lo *linterOptions) {
+		lo.WorkloadRules = workloadRules
+	}
+}
+
+// WithManifestSchemas validates the rendered resources against the OpenAPI
+// schemas of their kinds, and the schemas of the CRDs of the charts.
+func WithManifestSchemas(schemas *openapi.Validator) LinterOption {
+	return func(lo *linterOptions) {
+		lo.ManifestSchemas = schemas
+	}
+}
+
 func RunAll(baseDir string, values map[string]interface{}, namespace string, options ...LinterOption) support.Linter {
 
 	chartDir, _ := filepath.Abs(baseDir)
@@ -58,7 +106,15 @@ func RunAll(baseDir string, values map[string]interface{}, namespace string, opt
 
 	rules.Chartfile(&result)
 	rules.ValuesWithOverrides(&result, values)
-	rules.TemplatesWithSkipSchemaValidation(&result, values, namespace, lo.KubeVersion, lo.SkipSchemaValidation)
+	rules.TemplatesWithOptions(&result, values, namespace, rules.TemplateOptions{
+		KubeVersion:          lo.KubeVersion,
+		SkipSchemaValidation: lo.SkipSchemaValidation,
+		LookupProvider:       lo.LookupProvider,
+		PluginDirs:           lo.PluginDirs,
+		RenderConcurrency:    lo.RenderConcurrency,
+		WorkloadRules:        lo.WorkloadRules,
+		ManifestSchemas:      lo.ManifestSchemas,
+	})
 	rules.Dependencies(&result)
 	rules.Crds(&result)
 
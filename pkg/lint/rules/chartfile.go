@@ -26,8 +26,10 @@ import (
 	"github.com/asaskevich/govalidator"
 	"sigs.k8s.io/yaml"
 
+	v3 "helm.sh/helm/v4/internal/chart/v3"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/gates"
 	"helm.sh/helm/v4/pkg/lint/support"
 )
 
@@ -36,10 +38,10 @@ func Chartfile(linter *support.Linter) {
 	chartFileName := "Chart.yaml"
 	chartPath := filepath.Join(linter.ChartDir, chartFileName)
 
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartYamlNotDirectory(chartPath))
+	linter.RunLinterRuleWithID(RuleChartYamlDirectory, support.ErrorSev, chartFileName, validateChartYamlNotDirectory(chartPath))
 
 	chartFile, err := chartutil.LoadChartfile(chartPath)
-	validChartFile := linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartYamlFormat(err))
+	validChartFile := linter.RunLinterRuleWithID(RuleChartYamlFormat, support.ErrorSev, chartFileName, validateChartYamlFormat(err))
 
 	// Guard clause. Following linter rules require a parsable ChartFile
 	if !validChartFile {
@@ -47,26 +49,26 @@ func Chartfile(linter *support.Linter) {
 	}
 
 	_, err = chartutil.StrictLoadChartfile(chartPath)
-	linter.RunLinterRule(support.WarningSev, chartFileName, validateChartYamlStrictFormat(err))
+	linter.RunLinterRuleWithID(RuleChartYamlStrict, support.WarningSev, chartFileName, validateChartYamlStrictFormat(err))
 
 	// type check for Chart.yaml . ignoring error as any parse
 	// errors would already be caught in the above load function
 	chartFileForTypeCheck, _ := loadChartFileForTypeCheck(chartPath)
 
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartName(chartFile))
+	linter.RunLinterRuleWithID(RuleChartName, support.ErrorSev, chartFileName, validateChartName(chartFile))
 
 	// Chart metadata
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartAPIVersion(chartFile))
+	linter.RunLinterRuleWithID(RuleChartAPIVersion, support.ErrorSev, chartFileName, validateChartAPIVersion(chartFile))
 
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartVersionType(chartFileForTypeCheck))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartVersion(chartFile))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartAppVersionType(chartFileForTypeCheck))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartMaintainer(chartFile))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartSources(chartFile))
-	linter.RunLinterRule(support.InfoSev, chartFileName, validateChartIconPresence(chartFile))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartIconURL(chartFile))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartType(chartFile))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartDependencies(chartFile))
+	linter.RunLinterRuleWithID(RuleChartVersionType, support.ErrorSev, chartFileName, validateChartVersionType(chartFileForTypeCheck))
+	linter.RunLinterRuleWithID(RuleChartVersion, support.ErrorSev, chartFileName, validateChartVersion(chartFile))
+	linter.RunLinterRuleWithID(RuleChartAppVersionType, support.ErrorSev, chartFileName, validateChartAppVersionType(chartFileForTypeCheck))
+	linter.RunLinterRuleWithID(RuleChartMaintainer, support.ErrorSev, chartFileName, validateChartMaintainer(chartFile))
+	linter.RunLinterRuleWithID(RuleChartSources, support.ErrorSev, chartFileName, validateChartSources(chartFile))
+	linter.RunLinterRuleWithID(RuleIconMissing, support.InfoSev, chartFileName, validateChartIconPresence(chartFile))
+	linter.RunLinterRuleWithID(RuleIconURL, support.ErrorSev, chartFileName, validateChartIconURL(chartFile))
+	linter.RunLinterRuleWithID(RuleChartType, support.ErrorSev, chartFileName, validateChartType(chartFile))
+	linter.RunLinterRuleWithID(RuleChartDependencies, support.ErrorSev, chartFileName, validateChartDependencies(chartFile))
 }
 
 func validateChartVersionType(data map[string]interface{}) error {
@@ -128,6 +130,13 @@ func validateChartAPIVersion(cf *chart.Metadata) error {
 		return errors.New("apiVersion is required. The value must be either \"v1\" or \"v2\"")
 	}
 
+	if cf.APIVersion == v3.APIVersionV3 {
+		if !gates.ChartV3.IsEnabled() {
+			return fmt.Errorf("apiVersion '%s' is experimental: %w", cf.APIVersion, gates.ChartV3.Error())
+		}
+		return nil
+	}
+
 	if cf.APIVersion != chart.APIVersionV1 && cf.APIVersion != chart.APIVersionV2 {
 		return fmt.Errorf("apiVersion '%s' is not valid. The value must be either \"v1\" or \"v2\"", cf.APIVersion)
 	}
@@ -198,14 +207,14 @@ func validateChartIconURL(cf *chart.Metadata) error {
 }
 
 func validateChartDependencies(cf *chart.Metadata) error {
-	if len(cf.Dependencies) > 0 && cf.APIVersion != chart.APIVersionV2 {
+	if len(cf.Dependencies) > 0 && cf.APIVersion != chart.APIVersionV2 && cf.APIVersion != v3.APIVersionV3 {
 		return fmt.Errorf("dependencies are not valid in the Chart file with apiVersion '%s'. They are valid in apiVersion '%s'", cf.APIVersion, chart.APIVersionV2)
 	}
 	return nil
 }
 
 func validateChartType(cf *chart.Metadata) error {
-	if len(cf.Type) > 0 && cf.APIVersion != chart.APIVersionV2 {
+	if len(cf.Type) > 0 && cf.APIVersion != chart.APIVersionV2 && cf.APIVersion != v3.APIVersionV3 {
 		return fmt.Errorf("chart type is not valid in apiVersion '%s'. It is valid in apiVersion '%s'", cf.APIVersion, chart.APIVersionV2)
 	}
 	return nil
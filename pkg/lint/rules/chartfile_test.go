@@ -25,6 +25,7 @@ import (
 
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/gates"
 	"helm.sh/helm/v4/pkg/lint/support"
 )
 
@@ -78,6 +79,38 @@ func TestValidateChartName(t *testing.T) {
 	}
 }
 
+func TestValidateChartAPIVersion(t *testing.T) {
+	t.Setenv(string(gates.ChartV3), "")
+
+	for _, apiVersion := range []string{chart.APIVersionV1, chart.APIVersionV2} {
+		if err := validateChartAPIVersion(&chart.Metadata{APIVersion: apiVersion}); err != nil {
+			t.Errorf("validateChartAPIVersion(%s) to return no error, got %s", apiVersion, err)
+		}
+	}
+
+	err := validateChartAPIVersion(&chart.Metadata{APIVersion: "v3"})
+	if err == nil || !strings.Contains(err.Error(), "apiVersion 'v3' is experimental") {
+		t.Errorf("validateChartAPIVersion(v3) to return an error about the feature gate, got %v", err)
+	}
+
+	t.Setenv(string(gates.ChartV3), "1")
+	if err := validateChartAPIVersion(&chart.Metadata{APIVersion: "v3"}); err != nil {
+		t.Errorf("validateChartAPIVersion(v3) to return no error, got %s", err)
+	}
+	cf := &chart.Metadata{APIVersion: "v3", Type: "application", Dependencies: []*chart.Dependency{{Name: "feather"}}}
+	if err := validateChartType(cf); err != nil {
+		t.Errorf("validateChartType to return no error, got %s", err)
+	}
+	if err := validateChartDependencies(cf); err != nil {
+		t.Errorf("validateChartDependencies to return no error, got %s", err)
+	}
+
+	err = validateChartAPIVersion(&chart.Metadata{APIVersion: "v4"})
+	if err == nil || !strings.Contains(err.Error(), "apiVersion 'v4' is not valid") {
+		t.Errorf("validateChartAPIVersion(v4) to return a linter error, got %v", err)
+	}
+}
+
 func TestValidateChartVersion(t *testing.T) {
 	var failTest = []struct {
 		Version  string
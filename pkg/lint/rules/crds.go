@@ -28,7 +28,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/util/yaml"
 
-	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	"helm.sh/helm/v4/pkg/chart/loader"
 	"helm.sh/helm/v4/pkg/lint/support"
 )
 
@@ -42,7 +42,7 @@ func Crds(linter *support.Linter) {
 		return
 	}
 
-	crdsDirValid := linter.RunLinterRule(support.ErrorSev, fpath, validateCrdsDir(crdsPath))
+	crdsDirValid := linter.RunLinterRuleWithID(RuleCrdsDirInvalid, support.ErrorSev, fpath, validateCrdsDir(crdsPath))
 	if !crdsDirValid {
 		return
 	}
@@ -50,7 +50,7 @@ func Crds(linter *support.Linter) {
 	// Load chart and parse CRDs
 	chart, err := loader.Load(linter.ChartDir)
 
-	chartLoaded := linter.RunLinterRule(support.ErrorSev, fpath, err)
+	chartLoaded := linter.RunLinterRuleWithID(RuleChartLoad, support.ErrorSev, fpath, err)
 
 	if !chartLoaded {
 		return
@@ -76,12 +76,12 @@ func Crds(linter *support.Linter) {
 
 			// If YAML parsing fails here, it will always fail in the next block as well, so we should return here.
 			// This also confirms the YAML is not a template, since templates can't be decoded into a K8sYamlStruct.
-			if !linter.RunLinterRule(support.ErrorSev, fpath, validateYamlContent(err)) {
+			if !linter.RunLinterRuleWithID(RuleYAMLSyntax, support.ErrorSev, fpath, validateYamlContent(err)) {
 				return
 			}
 
-			linter.RunLinterRule(support.ErrorSev, fpath, validateCrdAPIVersion(yamlStruct))
-			linter.RunLinterRule(support.ErrorSev, fpath, validateCrdKind(yamlStruct))
+			linter.RunLinterRuleWithID(RuleCrdAPIVersion, support.ErrorSev, fpath, validateCrdAPIVersion(yamlStruct))
+			linter.RunLinterRuleWithID(RuleCrdKind, support.ErrorSev, fpath, validateCrdKind(yamlStruct))
 		}
 	}
 }
@@ -20,8 +20,8 @@ import (
 	"fmt"
 	"strings"
 
+	"helm.sh/helm/v4/pkg/chart/loader"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
-	"helm.sh/helm/v4/pkg/chart/v2/loader"
 	"helm.sh/helm/v4/pkg/lint/support"
 )
 
@@ -30,13 +30,13 @@ import (
 // See https://github.com/helm/helm/issues/7910
 func Dependencies(linter *support.Linter) {
 	c, err := loader.LoadDir(linter.ChartDir)
-	if !linter.RunLinterRule(support.ErrorSev, "", validateChartFormat(err)) {
+	if !linter.RunLinterRuleWithID(RuleChartLoad, support.ErrorSev, "", validateChartFormat(err)) {
 		return
 	}
 
-	linter.RunLinterRule(support.ErrorSev, linter.ChartDir, validateDependencyInMetadata(c))
-	linter.RunLinterRule(support.ErrorSev, linter.ChartDir, validateDependenciesUnique(c))
-	linter.RunLinterRule(support.WarningSev, linter.ChartDir, validateDependencyInChartsDir(c))
+	linter.RunLinterRuleWithID(RuleDependencyMetadata, support.ErrorSev, linter.ChartDir, validateDependencyInMetadata(c))
+	linter.RunLinterRuleWithID(RuleDependencyDuplicate, support.ErrorSev, linter.ChartDir, validateDependenciesUnique(c))
+	linter.RunLinterRuleWithID(RuleDependencyChartsDir, support.WarningSev, linter.ChartDir, validateDependencyInChartsDir(c))
 }
 
 func validateChartFormat(chartError error) error {
@@ -0,0 +1,141 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules // import "helm.sh/helm/v4/pkg/lint/rules"
+
+// Identifiers of the built-in lint rules, set as the RuleID of the messages
+// they raise. They are stable: tools may refer to them, e.g. to suppress or
+// configure a rule, so a rule keeps its identifier once released.
+const (
+	RuleChartYamlDirectory  = "chart-yaml-directory"
+	RuleChartYamlFormat     = "chart-yaml-format"
+	RuleChartYamlStrict     = "chart-yaml-strict"
+	RuleChartName           = "chart-name"
+	RuleChartAPIVersion     = "chart-api-version"
+	RuleChartVersionType    = "chart-version-type"
+	RuleChartVersion        = "chart-version"
+	RuleChartAppVersionType = "chart-app-version-type"
+	RuleChartMaintainer     = "chart-maintainer"
+	RuleChartSources        = "chart-sources"
+	RuleIconMissing         = "icon-missing"
+	RuleIconURL             = "icon-url"
+	RuleChartType           = "chart-type"
+	RuleChartDependencies   = "chart-dependencies"
+	RuleValuesMissing       = "values-missing"
+	RuleValuesInvalid       = "values-invalid"
+	RuleValuesSchemaDrift   = "values-schema-drift"
+	RuleTemplatesDirMissing = "templates-dir-missing"
+	RuleTemplatesDirInvalid = "templates-dir-invalid"
+	RuleChartLoad           = "chart-load"
+	RuleValuesSchema        = "values-schema"
+	RuleTemplateRender      = "template-render"
+	RuleTemplateExtension   = "template-extension"
+	RuleTemplateIndent      = "template-indent"
+	RuleYAMLSyntax          = "yaml-syntax"
+	RuleMetadataName        = "metadata-name"
+	RuleDeprecatedAPI       = "deprecated-api"
+	RuleSelectorMissing     = "selector-missing"
+	RuleListResourcePolicy  = "list-resource-policy"
+	RuleDependencyMetadata  = "dependency-not-in-metadata"
+	RuleDependencyDuplicate = "dependency-duplicate"
+	RuleDependencyChartsDir = "dependency-not-in-charts-dir"
+	RuleCrdsDirInvalid      = "crds-dir-invalid"
+	RuleCrdAPIVersion       = "crd-api-version"
+	RuleCrdKind             = "crd-kind"
+	RuleLintPlugin          = "lint-plugin"
+	RuleManifestSchema      = "manifest-schema"
+
+	// The workload rules, only run when enabled.
+	RulePrivilegedContainer = "privileged-container"
+	RuleHostPath            = "host-path"
+	RuleHostNetwork         = "host-network"
+	RuleHostPID             = "host-pid"
+	RuleRunAsRoot           = "run-as-root"
+	RuleResourceRequests    = "resource-requests"
+	RuleResourceLimits      = "resource-limits"
+	RuleImageTag            = "image-tag"
+	RuleLivenessProbe       = "liveness-probe"
+	RuleReadinessProbe      = "readiness-probe"
+	RuleRBACWildcard        = "rbac-wildcard"
+)
+
+// WorkloadRuleIDs are the identifiers of the workload rules, which are opt-in.
+var WorkloadRuleIDs = []string{
+	RulePrivilegedContainer,
+	RuleHostPath,
+	RuleHostNetwork,
+	RuleHostPID,
+	RuleRunAsRoot,
+	RuleResourceRequests,
+	RuleResourceLimits,
+	RuleImageTag,
+	RuleLivenessProbe,
+	RuleReadinessProbe,
+	RuleRBACWildcard,
+}
+
+// RuleDescriptions describes the built-in lint rules, by identifier.
+var RuleDescriptions = map[string]string{
+	RuleChartYamlDirectory:  "Chart.yaml must be a file, not a directory",
+	RuleChartYamlFormat:     "Chart.yaml must be valid YAML",
+	RuleChartYamlStrict:     "Chart.yaml must only contain known fields, without duplicates",
+	RuleChartName:           "the chart must have a valid name",
+	RuleChartAPIVersion:     "the chart must have a supported apiVersion",
+	RuleChartVersionType:    "the version of the chart must be a string",
+	RuleChartVersion:        "the version of the chart must be a valid semantic version",
+	RuleChartAppVersionType: "the appVersion of the chart must be a string",
+	RuleChartMaintainer:     "the maintainers of the chart must have a name and valid email addresses and URLs",
+	RuleChartSources:        "the sources of the chart must be valid URLs",
+	RuleIconMissing:         "the chart should have an icon",
+	RuleIconURL:             "the icon of the chart must be a valid URL",
+	RuleChartType:           "the type of the chart must only be set by apiVersion v2 charts",
+	RuleChartDependencies:   "the dependencies of the chart must only be declared by apiVersion v2 charts",
+	RuleValuesMissing:       "the chart should have a values.yaml file",
+	RuleValuesInvalid:       "values.yaml must be valid YAML and match the values schema",
+	RuleValuesSchemaDrift:   "values.yaml and the values schema should describe the same keys",
+	RuleTemplatesDirMissing: "the chart should have a templates directory",
+	RuleTemplatesDirInvalid: "templates must be a directory",
+	RuleChartLoad:           "the chart must load",
+	RuleValuesSchema:        "the values must match the values schema",
+	RuleTemplateRender:      "the templates must render",
+	RuleTemplateExtension:   "templates must have a .yaml, .yml, .tpl or .txt extension",
+	RuleTemplateIndent:      "rendered documents must not start with an indent",
+	RuleYAMLSyntax:          "rendered documents and CRDs must be valid YAML",
+	RuleMetadataName:        "resource names must conform to the Kubernetes naming requirements",
+	RuleDeprecatedAPI:       "resources should not use deprecated or removed API versions",
+	RuleSelectorMissing:     "workloads must have a selector",
+	RuleListResourcePolicy:  "the helm.sh/resource-policy annotation is ignored within List objects",
+	RuleDependencyMetadata:  "the charts in the charts directory must be declared as dependencies",
+	RuleDependencyDuplicate: "the dependencies must have unique names or aliases",
+	RuleDependencyChartsDir: "the dependencies should be in the charts directory",
+	RuleCrdsDirInvalid:      "crds must be a directory",
+	RuleCrdAPIVersion:       "CRDs must use the apiextensions.k8s.io API group",
+	RuleCrdKind:             "CRDs must be of kind CustomResourceDefinition",
+	RuleLintPlugin:          "lint plugins must run and return valid messages",
+	RuleManifestSchema:      "resources must match the OpenAPI schemas of their kinds",
+
+	RulePrivilegedContainer: "containers should not be privileged",
+	RuleHostPath:            "pods should not mount host paths",
+	RuleHostNetwork:         "pods should not use the network namespace of the host",
+	RuleHostPID:             "pods should not use the process namespace of the host",
+	RuleRunAsRoot:           "containers should not run as root",
+	RuleResourceRequests:    "containers should request cpu and memory",
+	RuleResourceLimits:      "containers should have cpu and memory limits",
+	RuleImageTag:            "images should be pinned to a tag other than latest, or a digest",
+	RuleLivenessProbe:       "long-running containers should have a liveness probe",
+	RuleReadinessProbe:      "long-running containers should have a readiness probe",
+	RuleRBACWildcard:        "roles should not grant access with wildcards",
+}
@@ -0,0 +1,49 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/lint/support"
+)
+
+func TestRuleIDs(t *testing.T) {
+	dirs, err := os.ReadDir("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() || dir.Name() == "plugins" {
+			continue
+		}
+		linter := support.Linter{ChartDir: filepath.Join("testdata", dir.Name())}
+		Chartfile(&linter)
+		ValuesWithOverrides(&linter, nil)
+		Templates(&linter, nil, "default", false)
+		Dependencies(&linter)
+		Crds(&linter)
+
+		for _, msg := range linter.Messages {
+			if _, ok := RuleDescriptions[msg.RuleID]; !ok {
+				t.Errorf("%s: message %q has no known rule identifier: %q", dir.Name(), msg, msg.RuleID)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules // import "helm.sh/helm/v4/pkg/lint/rules"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"helm.sh/helm/v4/internal/plugin"
+	"helm.sh/helm/v4/internal/plugin/schema"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/lint/support"
+)
+
+// lintPluginType is the type of the plugins providing lint rules.
+const lintPluginType = "lint/v1"
+
+// lintPluginTimeout is the time a lint plugin is given to lint a chart.
+const lintPluginTimeout = time.Minute
+
+// Plugins runs the lint/v1 plugins installed in the plugin directories
+// against the metadata of a chart and its rendered templates, and adds the
+// messages they return to the linter.
+func Plugins(linter *support.Linter, c *chart.Chart, rendered map[string]string, pluginDirs []string) {
+	fpath := "templates/"
+	if len(pluginDirs) == 0 {
+		return
+	}
+	plugins, err := plugin.FindPlugins(pluginDirs, plugin.Descriptor{Type: lintPluginType})
+	if !linter.RunLinterRuleWithID(RuleLintPlugin, support.ErrorSev, fpath, err) {
+		return
+	}
+	if len(plugins) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(rendered))
+	for name := range rendered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	input := schema.InputMessageLintV1{
+		Chart:     c.Metadata,
+		Manifests: make([]schema.LintManifestV1, 0, len(names)),
+	}
+	for _, name := range names {
+		input.Manifests = append(input.Manifests, schema.LintManifestV1{
+			Path:    strings.TrimPrefix(name, c.Name()+"/"),
+			Content: rendered[name],
+		})
+	}
+
+	for _, p := range plugins {
+		name := p.Metadata().Name
+		ctx, cancel := context.WithTimeout(context.Background(), lintPluginTimeout)
+		output, err := p.Invoke(ctx, &plugin.Input{Message: input})
+		cancel()
+		if err != nil {
+			linter.RunLinterRuleWithID(RuleLintPlugin, support.ErrorSev, fpath, fmt.Errorf("lint plugin %q failed: %w", name, err))
+			continue
+		}
+		msg, ok := output.Message.(schema.OutputMessageLintV1)
+		if !ok {
+			linter.RunLinterRuleWithID(RuleLintPlugin, support.ErrorSev, fpath, fmt.Errorf("lint plugin %q returned an invalid output type: %T", name, output.Message))
+			continue
+		}
+		for _, m := range msg.Messages {
+			severity, err := support.ParseSeverity(m.Severity)
+			if err != nil {
+				linter.RunLinterRuleWithID(RuleLintPlugin, support.ErrorSev, fpath, fmt.Errorf("lint plugin %q returned a message with %w", name, err))
+				continue
+			}
+			path := m.Path
+			if path == "" {
+				path = fpath
+			} else if m.Line > 0 {
+				path = fmt.Sprintf("%s:%d", path, m.Line)
+				if m.Column > 0 {
+					path = fmt.Sprintf("%s:%d", path, m.Column)
+				}
+			}
+			ruleID := name
+			if m.Rule != "" {
+				ruleID = name + "/" + m.Rule
+			}
+			linter.RunLinterRuleWithID(ruleID, severity, path, errors.New(m.Message))
+		}
+	}
+}
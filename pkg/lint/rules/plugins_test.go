@@ -0,0 +1,70 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/lint/support"
+)
+
+func TestPlugins(t *testing.T) {
+	c := &chart.Chart{Metadata: &chart.Metadata{Name: "web", Version: "0.1.0"}}
+	rendered := map[string]string{
+		"web/templates/deployment.yaml": "kind: Deployment\nspec:\n  template:\n    spec:\n      containers:\n        - image: nginx:latest\n",
+	}
+
+	linter := support.Linter{}
+	Plugins(&linter, c, rendered, []string{"testdata/plugins"})
+
+	assert.Equal(t, []support.Message{
+		support.NewMessage(support.ErrorSev, "templates/", assert.AnError),
+		support.NewMessage(support.InfoSev, "templates/", assert.AnError),
+		support.NewMessage(support.WarningSev, "templates/deployment.yaml:6:18", assert.AnError),
+	}, withoutErrors(linter.Messages))
+	assert.EqualError(t, linter.Messages[0].Err, `lint plugin "broken" returned a message with invalid severity "fatal": must be one of info, warning or error`)
+	assert.EqualError(t, linter.Messages[1].Err, "linted chart web")
+	assert.EqualError(t, linter.Messages[2].Err, "images must not use the latest tag")
+	assert.Equal(t, support.WarningSev+1, linter.HighestSeverity)
+	assert.Equal(t, RuleLintPlugin, linter.Messages[0].RuleID)
+	assert.Equal(t, "latest-images", linter.Messages[1].RuleID)
+	assert.Equal(t, "latest-images/latest-tag", linter.Messages[2].RuleID)
+
+	rendered["web/templates/deployment.yaml"] = "kind: Deployment\n"
+	linter = support.Linter{}
+	Plugins(&linter, c, rendered, nil)
+	assert.Empty(t, linter.Messages)
+
+	// The errors of a failing plugin give what it wrote to stderr.
+	linter = support.Linter{}
+	Plugins(&linter, c, rendered, []string{"testdata/failing-plugins"})
+	assert.Len(t, linter.Messages, 1)
+	assert.EqualError(t, linter.Messages[0].Err, `lint plugin "failing" failed: plugin "failing" exited with error: failing cannot lint this chart`)
+}
+
+// withoutErrors replaces the errors of the messages, to compare them without
+// their errors.
+func withoutErrors(msgs []support.Message) []support.Message {
+	out := make([]support.Message, 0, len(msgs))
+	for _, m := range msgs {
+		out = append(out, support.NewMessage(m.Severity, m.Path, assert.AnError))
+	}
+	return out
+}
@@ -0,0 +1,60 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules // import "helm.sh/helm/v4/pkg/lint/rules"
+
+import (
+	"errors"
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/kube/openapi"
+	"helm.sh/helm/v4/pkg/lint/support"
+)
+
+// withChartCRDs returns the validator with the schemas of the CRDs of the
+// chart and its subcharts. CRD files which cannot be loaded are left to the
+// CRD rules.
+func withChartCRDs(schemas *openapi.Validator, c *chart.Chart) *openapi.Validator {
+	for _, crd := range c.CRDObjects() {
+		if v, err := schemas.WithCRDs(crd.File.Data); err == nil {
+			schemas = v
+		}
+	}
+	return schemas
+}
+
+// validateManifestSchema validates a rendered resource against the schema of
+// its kind, reporting each invalid field at the template line it comes from.
+// Resources of kinds without a schema are not validated.
+func validateManifestSchema(linter *support.Linter, src templateSource, schemas *openapi.Validator) {
+	var obj map[string]interface{}
+	if err := yaml.Unmarshal([]byte(src.doc.Content), &obj); err != nil || obj == nil {
+		return
+	}
+	errs, err := schemas.Validate(obj)
+	if errors.Is(err, openapi.ErrNoSchema) {
+		return
+	}
+	kind, _ := obj["kind"].(string)
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	for _, e := range errs {
+		linter.RunLinterRuleWithID(RuleManifestSchema, support.ErrorSev, src.path(e.Field), fmt.Errorf("%s %q: %w", kind, name, e))
+	}
+}
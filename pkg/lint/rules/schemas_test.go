@@ -0,0 +1,54 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/kube/openapi"
+	"helm.sh/helm/v4/pkg/lint/support"
+)
+
+func TestManifestSchemas(t *testing.T) {
+	schemas, err := openapi.New(openapi.Options{})
+	require.NoError(t, err)
+
+	linter := support.Linter{ChartDir: "testdata/manifest-schemas"}
+	TemplatesWithOptions(&linter, values, namespace, TemplateOptions{ManifestSchemas: schemas})
+
+	var got []string
+	for _, msg := range linter.Messages {
+		got = append(got, fmt.Sprintf("%s %s %s", msg.RuleID, msg.Path, msg.Err))
+	}
+	assert.Equal(t, []string{
+		`manifest-schema templates/crontab.yaml:7 CronTab "test-release-backup": .spec.replicas: expected numeric (int or float), got string`,
+		`manifest-schema templates/deployment.yaml:6 Deployment "test-release": .spec.replicas: expected numeric (int or float), got string`,
+		`manifest-schema templates/deployment.yaml:20 Deployment "test-release": .spec.template.spec.containers[name="web"].ports[containerPort=80,protocol="TCP"].hostPrt: field not declared in schema`,
+	}, got)
+	assert.Equal(t, support.ErrorSev, linter.HighestSeverity)
+
+	_, err = schemas.Validate(map[string]interface{}{"apiVersion": "stable.example.com/v1", "kind": "CronTab"})
+	assert.ErrorIs(t, err, openapi.ErrNoSchema, "the CRDs of the chart must not be added to the given validator")
+
+	linter = support.Linter{ChartDir: "testdata/manifest-schemas"}
+	TemplatesWithOptions(&linter, values, namespace, TemplateOptions{})
+	assert.Empty(t, linter.Messages, "the manifests must only be validated against schemas when enabled")
+}
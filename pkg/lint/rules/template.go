@@ -25,7 +25,9 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/api/validation"
@@ -33,10 +35,12 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/util/yaml"
 
-	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	"helm.sh/helm/v4/pkg/chart/loader"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/engine"
+	"helm.sh/helm/v4/pkg/kube/openapi"
 	"helm.sh/helm/v4/pkg/lint/support"
+	releaseutil "helm.sh/helm/v4/pkg/release/util"
 )
 
 // Templates lints the templates in the Linter.
@@ -51,16 +55,47 @@ func TemplatesWithKubeVersion(linter *support.Linter, values map[string]interfac
 
 // TemplatesWithSkipSchemaValidation lints the templates in the Linter, allowing to specify the kubernetes version and if schema validation is enabled or not.
 func TemplatesWithSkipSchemaValidation(linter *support.Linter, values map[string]interface{}, namespace string, kubeVersion *chartutil.KubeVersion, skipSchemaValidation bool) {
+	TemplatesWithOptions(linter, values, namespace, TemplateOptions{KubeVersion: kubeVersion, SkipSchemaValidation: skipSchemaValidation})
+}
+
+// TemplateOptions configures how the templates are linted.
+type TemplateOptions struct {
+	// KubeVersion is the Kubernetes version the templates are rendered for,
+	// the default one when nil.
+	KubeVersion *chartutil.KubeVersion
+	// SkipSchemaValidation skips validating the values against the schema of
+	// the chart.
+	SkipSchemaValidation bool
+	// LookupProvider, when set, serves the 'lookup' template function, e.g.
+	// from fixtures.
+	LookupProvider engine.ClientProvider
+	// PluginDirs are the directories the template function plugins required
+	// by the chart, and the lint plugins, are loaded from.
+	PluginDirs []string
+	// RenderConcurrency is the maximum number of templates rendered at the
+	// same time, see engine.Engine.Concurrency.
+	RenderConcurrency int
+	// WorkloadRules checks the rendered workloads for common risks.
+	WorkloadRules bool
+	// ManifestSchemas, when set, validates the rendered resources against
+	// the OpenAPI schemas of their kinds, to which the CRDs of the chart are
+	// added.
+	ManifestSchemas *openapi.Validator
+}
+
+// TemplatesWithOptions lints the templates in the Linter with the given options.
+func TemplatesWithOptions(linter *support.Linter, values map[string]interface{}, namespace string, opts TemplateOptions) {
+	kubeVersion, schemas := opts.KubeVersion, opts.ManifestSchemas
 	fpath := "templates/"
 	templatesPath := filepath.Join(linter.ChartDir, fpath)
 
 	// Templates directory is optional for now
-	templatesDirExists := linter.RunLinterRule(support.WarningSev, fpath, templatesDirExists(templatesPath))
+	templatesDirExists := linter.RunLinterRuleWithID(RuleTemplatesDirMissing, support.WarningSev, fpath, templatesDirExists(templatesPath))
 	if !templatesDirExists {
 		return
 	}
 
-	validTemplatesDir := linter.RunLinterRule(support.ErrorSev, fpath, validateTemplatesDir(templatesPath))
+	validTemplatesDir := linter.RunLinterRuleWithID(RuleTemplatesDirInvalid, support.ErrorSev, fpath, validateTemplatesDir(templatesPath))
 	if !validTemplatesDir {
 		return
 	}
@@ -68,7 +103,7 @@ func TemplatesWithSkipSchemaValidation(linter *support.Linter, values map[string
 	// Load chart and parse templates
 	chart, err := loader.Load(linter.ChartDir)
 
-	chartLoaded := linter.RunLinterRule(support.ErrorSev, fpath, err)
+	chartLoaded := linter.RunLinterRuleWithID(RuleChartLoad, support.ErrorSev, fpath, err)
 
 	if !chartLoaded {
 		return
@@ -95,21 +130,33 @@ func TemplatesWithSkipSchemaValidation(linter *support.Linter, values map[string
 		return
 	}
 
-	valuesToRender, err := chartutil.ToRenderValuesWithSchemaValidation(chart, cvals, options, caps, skipSchemaValidation)
+	valuesToRender, err := chartutil.ToRenderValuesWithSchemaValidation(chart, cvals, options, caps, opts.SkipSchemaValidation)
 	if err != nil {
-		linter.RunLinterRule(support.ErrorSev, fpath, err)
+		linter.RunLinterRuleWithID(RuleValuesSchema, support.ErrorSev, fpath, err)
 		return
 	}
 	var e engine.Engine
+	if opts.LookupProvider != nil {
+		e = engine.NewWithClientProvider(opts.LookupProvider)
+	}
 	e.LintMode = true
+	e.SourceMap = engine.SourceMap{}
+	e.PluginDirs = opts.PluginDirs
+	e.Concurrency = opts.RenderConcurrency
 	renderedContentMap, err := e.Render(chart, valuesToRender)
 
-	renderOk := linter.RunLinterRule(support.ErrorSev, fpath, err)
+	renderOk := linter.RunLinterRuleWithID(RuleTemplateRender, support.ErrorSev, fpath, err)
 
 	if !renderOk {
 		return
 	}
 
+	Plugins(linter, chart, renderedContentMap, opts.PluginDirs)
+
+	if schemas != nil {
+		schemas = withChartCRDs(schemas, chart)
+	}
+
 	/* Iterate over all the templates to check:
 	- It is a .yaml file
 	- All the values in the template file is defined
@@ -121,7 +168,7 @@ func TemplatesWithSkipSchemaValidation(linter *support.Linter, values map[string
 		fileName := template.Name
 		fpath = fileName
 
-		linter.RunLinterRule(support.ErrorSev, fpath, validateAllowedExtension(fileName))
+		linter.RunLinterRuleWithID(RuleTemplateExtension, support.ErrorSev, fpath, validateAllowedExtension(fileName))
 
 		// We only apply the following lint rules to yaml files
 		if filepath.Ext(fileName) != ".yaml" || filepath.Ext(fileName) == ".yml" {
@@ -135,42 +182,95 @@ func TemplatesWithSkipSchemaValidation(linter *support.Linter, values map[string
 		// NOTE: disabled for now, Refs https://github.com/helm/helm/issues/1037
 		// linter.RunLinterRule(support.WarningSev, fpath, validateQuotes(string(preExecutedTemplate)))
 
-		renderedContent := renderedContentMap[path.Join(chart.Name(), fileName)]
+		renderedName := path.Join(chart.Name(), fileName)
+		renderedContent := renderedContentMap[renderedName]
 		if strings.TrimSpace(renderedContent) != "" {
-			linter.RunLinterRule(support.WarningSev, fpath, validateTopIndentLevel(renderedContent))
+			linter.RunLinterRuleWithID(RuleTemplateIndent, support.WarningSev, fpath, validateTopIndentLevel(renderedContent))
 
-			decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(renderedContent), 4096)
+			src := templateSource{prefix: chart.Name() + "/", fpath: fpath, name: renderedName, sourceMap: e.SourceMap}
 
 			// Lint all resources if the file contains multiple documents separated by ---
-			for {
+			for _, doc := range releaseutil.SplitDocuments(renderedContent) {
 				// Even though k8sYamlStruct only defines a few fields, an error in any other
 				// key will be raised as well
 				var yamlStruct *k8sYamlStruct
 
-				err := decoder.Decode(&yamlStruct)
+				err := yaml.NewYAMLOrJSONDecoder(strings.NewReader(doc.Content), 4096).Decode(&yamlStruct)
 				if err == io.EOF {
-					break
+					continue
 				}
+				src.doc = doc
 
 				//  If YAML linting fails here, it will always fail in the next block as well, so we should return here.
 				// fix https://github.com/helm/helm/issues/11391
-				if !linter.RunLinterRule(support.ErrorSev, fpath, validateYamlContent(err)) {
+				if !linter.RunLinterRuleWithID(RuleYAMLSyntax, support.ErrorSev, src.errorPath(err), validateYamlContent(err)) {
 					return
 				}
 				if yamlStruct != nil {
 					// NOTE: set to warnings to allow users to support out-of-date kubernetes
 					// Refs https://github.com/helm/helm/issues/8596
-					linter.RunLinterRule(support.WarningSev, fpath, validateMetadataName(yamlStruct))
-					linter.RunLinterRule(support.WarningSev, fpath, validateNoDeprecations(yamlStruct, kubeVersion))
-
-					linter.RunLinterRule(support.ErrorSev, fpath, validateMatchSelector(yamlStruct, renderedContent))
-					linter.RunLinterRule(support.ErrorSev, fpath, validateListAnnotations(yamlStruct, renderedContent))
+					linter.RunLinterRuleWithID(RuleMetadataName, support.WarningSev, src.path("metadata.name"), validateMetadataName(yamlStruct))
+					linter.RunLinterRuleWithID(RuleDeprecatedAPI, support.WarningSev, src.path("apiVersion"), validateNoDeprecations(yamlStruct, kubeVersion))
+
+					linter.RunLinterRuleWithID(RuleSelectorMissing, support.ErrorSev, src.path("spec.selector"), validateMatchSelector(yamlStruct, renderedContent))
+					linter.RunLinterRuleWithID(RuleListResourcePolicy, support.ErrorSev, src.path(""), validateListAnnotations(yamlStruct, renderedContent))
+					if opts.WorkloadRules {
+						validateWorkload(linter, src, yamlStruct)
+					}
+					if schemas != nil {
+						validateManifestSchema(linter, src, schemas)
+					}
 				}
 			}
 		}
 	}
 }
 
+// templateSource locates the template lines the fields of a rendered document
+// come from.
+type templateSource struct {
+	// prefix is the prefix of the rendered names trimmed from the paths.
+	prefix    string
+	fpath     string
+	name      string
+	doc       releaseutil.Document
+	sourceMap engine.SourceMap
+}
+
+// path returns the path of the template line a field of the document comes
+// from, e.g. "templates/deployment.yaml:12", or the path of the template when
+// it is unknown.
+func (s templateSource) path(field string) string {
+	return s.pathAt(releaseutil.FieldLine(s.doc.Content, field))
+}
+
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// errorPath returns the path of the template line a YAML error of the
+// document is about.
+func (s templateSource) errorPath(err error) string {
+	if err == nil {
+		return s.fpath
+	}
+	m := yamlErrorLine.FindStringSubmatch(err.Error())
+	if m == nil {
+		return s.path("")
+	}
+	line, _ := strconv.Atoi(m[1])
+	return s.pathAt(line)
+}
+
+func (s templateSource) pathAt(line int) string {
+	if s.doc.Line == 0 {
+		return s.fpath
+	}
+	loc, ok := s.sourceMap.Lookup(s.name, s.doc.Line+max(line, 1)-1)
+	if !ok {
+		return s.fpath
+	}
+	return strings.TrimPrefix(loc.String(), s.prefix)
+}
+
 // validateTopIndentLevel checks that the content does not start with an indent level > 0.
 //
 // This error can occur when a template accidentally inserts space. It can cause
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -221,6 +222,48 @@ func TestDeprecatedAPIFails(t *testing.T) {
 	}
 }
 
+func TestTemplateMessagePaths(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "paths",
+			Version:    "0.1.0",
+			Icon:       "satisfy-the-linting-gods.gif",
+		},
+		Templates: []*chart.File{
+			{
+				Name: "templates/_helpers.tpl",
+				Data: []byte("{{- define \"paths.name\" -}}\nBad_Name\n{{- end }}\n"),
+			},
+			{
+				Name: "templates/deployment.yaml",
+				Data: []byte("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: good\nspec:\n  replicas: 1\n---\n# comment\nkind: ConfigMap\napiVersion: v1\nmetadata:\n  name: {{ template \"paths.name\" . }}\n"),
+			},
+		},
+	}
+	tmpdir := t.TempDir()
+
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Templates(&linter, values, namespace, strict)
+	var paths []string
+	for _, msg := range linter.Messages {
+		paths = append(paths, msg.Path)
+	}
+	// The missing selector points at spec, the invalid name at the line of the
+	// second document it is written on.
+	expected := []string{"templates/deployment.yaml:5", "templates/deployment.yaml:12"}
+	if !slices.Equal(paths, expected) {
+		for i, msg := range linter.Messages {
+			t.Logf("Message %d: %s", i, msg)
+		}
+		t.Errorf("Expected paths %v, got %v", expected, paths)
+	}
+}
+
 const manifest = `apiVersion: v1
 kind: ConfigMap
 metadata:
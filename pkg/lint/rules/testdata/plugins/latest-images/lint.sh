@@ -0,0 +1,10 @@
+#!/bin/sh
+# Reports the images using the latest tag, and the name of the chart.
+input=$(cat)
+chart=$(echo "$input" | sed -n 's/.*"chart":{[^}]*"name":"\([^"]*\)".*/\1/p')
+echo '{"messages":['
+echo "{\"severity\":\"info\",\"message\":\"linted chart $chart\"}"
+if echo "$input" | grep -q ':latest'; then
+  echo ',{"severity":"warning","rule":"latest-tag","path":"templates/deployment.yaml","line":6,"column":18,"message":"images must not use the latest tag"}'
+fi
+echo ']}'
@@ -17,9 +17,13 @@ limitations under the License.
 package rules
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/lint/support"
@@ -31,16 +35,22 @@ import (
 // they are only tested for well-formedness.
 //
 // If additional values are supplied, they are coalesced into the values in values.yaml.
+//
+// A warning is raised for every key of values.yaml the schema does not describe
+// and for every property of the schema values.yaml has no default for.
 func ValuesWithOverrides(linter *support.Linter, valueOverrides map[string]interface{}) {
 	file := "values.yaml"
 	vf := filepath.Join(linter.ChartDir, file)
-	fileExists := linter.RunLinterRule(support.InfoSev, file, validateValuesFileExistence(vf))
+	fileExists := linter.RunLinterRuleWithID(RuleValuesMissing, support.InfoSev, file, validateValuesFileExistence(vf))
 
 	if !fileExists {
 		return
 	}
 
-	linter.RunLinterRule(support.ErrorSev, file, validateValuesFile(vf, valueOverrides))
+	linter.RunLinterRuleWithID(RuleValuesInvalid, support.ErrorSev, file, validateValuesFile(vf, valueOverrides))
+	for _, err := range validateSchemaDrift(linter.ChartDir, vf) {
+		linter.RunLinterRuleWithID(RuleValuesSchemaDrift, support.WarningSev, "values.schema.json", err)
+	}
 }
 
 func validateValuesFileExistence(valuesPath string) error {
@@ -76,3 +86,109 @@ func validateValuesFile(valuesPath string, overrides map[string]interface{}) err
 	}
 	return chartutil.ValidateAgainstSingleSchema(coalescedValues, schema)
 }
+
+// validateSchemaDrift compares the keys of the values file with the properties
+// described by its schema, and reports the required properties missing from
+// the values file. The values of the subcharts are left to their own schemas,
+// as are objects whose schema allows additional properties.
+func validateSchemaDrift(chartDir, valuesPath string) []error {
+	ext := filepath.Ext(valuesPath)
+	schemaJSON, err := os.ReadFile(valuesPath[:len(valuesPath)-len(ext)] + ".schema.json")
+	if err != nil || len(schemaJSON) == 0 {
+		return nil
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(schemaJSON, &schema); err != nil {
+		// An invalid schema is reported by the schema validation.
+		return nil
+	}
+	values, err := chartutil.ReadValuesFile(valuesPath)
+	if err != nil {
+		return nil
+	}
+
+	var subcharts []string
+	if chartFile, err := chartutil.LoadChartfile(filepath.Join(chartDir, "Chart.yaml")); err == nil {
+		for _, dep := range chartFile.Dependencies {
+			if dep.Alias != "" {
+				subcharts = append(subcharts, dep.Alias)
+			} else {
+				subcharts = append(subcharts, dep.Name)
+			}
+		}
+	}
+
+	var errs []error
+	compareSchema("", values, schema, subcharts, &errs)
+	return errs
+}
+
+func compareSchema(prefix string, values, schema map[string]interface{}, skip []string, errs *[]error) {
+	props, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	additional, ok := schema["additionalProperties"]
+	open := ok && additional != false
+	var patterns []*regexp.Regexp
+	if pp, ok := schema["patternProperties"].(map[string]interface{}); ok {
+		for pattern := range pp {
+			if re, err := regexp.Compile(pattern); err == nil {
+				patterns = append(patterns, re)
+			}
+		}
+	}
+	described := func(k string) bool {
+		if _, ok := props[k]; ok || open {
+			return true
+		}
+		return slices.ContainsFunc(patterns, func(re *regexp.Regexp) bool { return re.MatchString(k) })
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if slices.Contains(skip, k) {
+			continue
+		}
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if !described(k) {
+			*errs = append(*errs, fmt.Errorf("value %q is not described by the schema", path))
+			continue
+		}
+		vm, ok1 := values[k].(map[string]interface{})
+		pm, ok2 := props[k].(map[string]interface{})
+		if ok1 && ok2 {
+			compareSchema(path, vm, pm, nil, errs)
+		}
+	}
+
+	// Only required properties need a default, the others can be left out
+	// of values.yaml.
+	required, _ := schema["required"].([]interface{})
+	names := make([]string, 0, len(required))
+	for _, k := range required {
+		if name, ok := k.(string); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		if slices.Contains(skip, k) {
+			continue
+		}
+		if _, ok := values[k]; !ok {
+			path := k
+			if prefix != "" {
+				path = prefix + "." + k
+			}
+			*errs = append(*errs, fmt.Errorf("required schema property %q has no default in values.yaml", path))
+		}
+	}
+}
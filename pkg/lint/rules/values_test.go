@@ -167,3 +167,46 @@ func createTestingSchema(t *testing.T, dir string) string {
 	}
 	return schemafile
 }
+
+func TestValidateSchemaDrift(t *testing.T) {
+	yaml := "username: admin\npassword: swordfish\nextra:\n  enabled: true\nmysql:\n  port: 3306\nlabels:\n  app: web\nstrict:\n  name: web\n  nme: typo\nenv:\n  HOME: /root\n  path: /bin\n"
+	schema := `{
+  "type": "object",
+  "required": ["username", "email"],
+  "properties": {
+    "username": {"type": "string"},
+    "email": {"type": "string"},
+    "optional": {"type": "string"},
+    "extra": {"type": "object", "required": ["size"], "properties": {"size": {"type": "integer"}}},
+    "labels": {"type": "object", "properties": {}, "additionalProperties": {"type": "string"}},
+    "strict": {"type": "object", "properties": {"name": {"type": "string"}}, "additionalProperties": false},
+    "env": {"type": "object", "properties": {}, "patternProperties": {"^[A-Z]+$": {"type": "string"}}}
+  }
+}`
+	tmpdir := ensure.TempFile(t, "values.yaml", []byte(yaml))
+	if err := os.WriteFile(filepath.Join(tmpdir, "values.schema.json"), []byte(schema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	chartfile := "apiVersion: v2\nname: drift\nversion: 0.1.0\ndependencies:\n- name: mysql\n  version: 1.0.0\n"
+	if err := os.WriteFile(filepath.Join(tmpdir, "Chart.yaml"), []byte(chartfile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []string
+	for _, err := range validateSchemaDrift(tmpdir, filepath.Join(tmpdir, "values.yaml")) {
+		messages = append(messages, err.Error())
+	}
+	assert.Equal(t, []string{
+		`value "env.path" is not described by the schema`,
+		`value "extra.enabled" is not described by the schema`,
+		`required schema property "extra.size" has no default in values.yaml`,
+		`value "password" is not described by the schema`,
+		`value "strict.nme" is not described by the schema`,
+		`required schema property "email" has no default in values.yaml`,
+	}, messages)
+}
+
+func TestValidateSchemaDriftWithoutSchema(t *testing.T) {
+	tmpdir := ensure.TempFile(t, "values.yaml", []byte("username: admin\n"))
+	assert.Empty(t, validateSchemaDrift(tmpdir, filepath.Join(tmpdir, "values.yaml")))
+}
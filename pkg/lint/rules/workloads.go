@@ -0,0 +1,258 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules // import "helm.sh/helm/v4/pkg/lint/rules"
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/lint/support"
+)
+
+// WorkloadIgnoreAnnotation is the annotation of a resource listing the
+// workload rules not to apply to it, separated by commas, e.g.
+// "host-network,run-as-root".
+const WorkloadIgnoreAnnotation = "helm.sh/lint-ignore"
+
+// workloadFinding is a risk found in a rendered resource.
+type workloadFinding struct {
+	rule     string
+	severity int
+	// field is the path of the field the finding is about, e.g.
+	// "spec.template.spec.hostNetwork".
+	field string
+	err   error
+}
+
+// validateWorkload runs the workload rules against a rendered resource, and
+// adds the findings not ignored by its annotation to the linter.
+func validateWorkload(linter *support.Linter, src templateSource, obj *k8sYamlStruct) {
+	if src.doc.Content == "" {
+		return
+	}
+	var meta struct {
+		Metadata struct {
+			Annotations map[string]string `json:"annotations"`
+		} `json:"metadata"`
+	}
+	if err := yaml.Unmarshal([]byte(src.doc.Content), &meta); err != nil {
+		return
+	}
+	var ignored []string
+	for rule := range strings.SplitSeq(meta.Metadata.Annotations[WorkloadIgnoreAnnotation], ",") {
+		ignored = append(ignored, strings.TrimSpace(rule))
+	}
+
+	for _, f := range workloadFindings(obj.Kind, src.doc.Content) {
+		if slices.Contains(ignored, f.rule) {
+			continue
+		}
+		linter.RunLinterRuleWithID(f.rule, f.severity, src.path(f.field), f.err)
+	}
+}
+
+// workloadFindings returns the risks found in a rendered resource. Resources
+// which cannot be decoded are left to the other rules.
+func workloadFindings(kind, content string) []workloadFinding {
+	switch kind {
+	case "Role", "ClusterRole":
+		var role rbacv1.ClusterRole
+		if err := yaml.Unmarshal([]byte(content), &role); err != nil {
+			return nil
+		}
+		return rbacFindings(role.Rules)
+	}
+
+	spec, prefix, err := podSpec(kind, content)
+	if err != nil || spec == nil {
+		return nil
+	}
+	var findings []workloadFinding
+	add := func(rule string, severity int, field string, format string, args ...interface{}) {
+		findings = append(findings, workloadFinding{rule: rule, severity: severity, field: prefix + "." + field, err: fmt.Errorf(format, args...)})
+	}
+
+	if spec.HostNetwork {
+		add(RuleHostNetwork, support.WarningSev, "hostNetwork", "the pod uses the network namespace of the host")
+	}
+	if spec.HostPID {
+		add(RuleHostPID, support.WarningSev, "hostPID", "the pod uses the process namespace of the host")
+	}
+	for i, v := range spec.Volumes {
+		if v.HostPath != nil {
+			add(RuleHostPath, support.WarningSev, fmt.Sprintf("volumes[%d].hostPath", i), "volume %q mounts the host path %s", v.Name, v.HostPath.Path)
+		}
+	}
+
+	containers := []struct {
+		field string
+		list  []corev1.Container
+	}{{"initContainers", spec.InitContainers}, {"containers", spec.Containers}}
+	for _, cs := range containers {
+		for i, c := range cs.list {
+			field := fmt.Sprintf("%s[%d]", cs.field, i)
+			sc := c.SecurityContext
+			if sc == nil {
+				sc = &corev1.SecurityContext{}
+			}
+
+			if sc.Privileged != nil && *sc.Privileged {
+				add(RulePrivilegedContainer, support.ErrorSev, field+".securityContext.privileged", "container %q is privileged", c.Name)
+			}
+
+			runAsUser, runAsNonRoot := sc.RunAsUser, sc.RunAsNonRoot
+			if psc := spec.SecurityContext; psc != nil {
+				if runAsUser == nil {
+					runAsUser = psc.RunAsUser
+				}
+				if runAsNonRoot == nil {
+					runAsNonRoot = psc.RunAsNonRoot
+				}
+			}
+			switch {
+			case runAsUser != nil && *runAsUser == 0:
+				add(RuleRunAsRoot, support.WarningSev, field+".securityContext.runAsUser", "container %q runs as root", c.Name)
+			case runAsUser == nil && (runAsNonRoot == nil || !*runAsNonRoot):
+				add(RuleRunAsRoot, support.WarningSev, field, "container %q may run as root: set runAsNonRoot to true or a non-zero runAsUser", c.Name)
+			}
+
+			if missing := missingResources(c.Resources.Requests); len(missing) > 0 {
+				add(RuleResourceRequests, support.WarningSev, field+".resources", "container %q has no %s requests", c.Name, strings.Join(missing, " and "))
+			}
+			if missing := missingResources(c.Resources.Limits); len(missing) > 0 {
+				add(RuleResourceLimits, support.WarningSev, field+".resources", "container %q has no %s limits", c.Name, strings.Join(missing, " and "))
+			}
+
+			switch tag := imageTag(c.Image); {
+			case c.Image == "":
+			case tag == "":
+				add(RuleImageTag, support.WarningSev, field+".image", "container %q uses the image %q without a tag or digest", c.Name, c.Image)
+			case tag == "latest":
+				add(RuleImageTag, support.WarningSev, field+".image", "container %q uses the image %q with the latest tag", c.Name, c.Image)
+			}
+
+			// Init containers, jobs and bare pods run to completion, they
+			// are not probed.
+			if cs.field == "initContainers" || !isLongRunning(kind) {
+				continue
+			}
+			if c.LivenessProbe == nil {
+				add(RuleLivenessProbe, support.InfoSev, field, "container %q has no liveness probe", c.Name)
+			}
+			if c.ReadinessProbe == nil {
+				add(RuleReadinessProbe, support.InfoSev, field, "container %q has no readiness probe", c.Name)
+			}
+		}
+	}
+	return findings
+}
+
+// podSpec returns the pod spec of a workload, and the path of its field.
+func podSpec(kind, content string) (*corev1.PodSpec, string, error) {
+	switch kind {
+	case "Pod":
+		var pod corev1.Pod
+		err := yaml.Unmarshal([]byte(content), &pod)
+		return &pod.Spec, "spec", err
+	case "Deployment", "ReplicaSet", "StatefulSet", "DaemonSet", "Job", "ReplicationController":
+		var workload struct {
+			Spec struct {
+				Template corev1.PodTemplateSpec `json:"template"`
+			} `json:"spec"`
+		}
+		err := yaml.Unmarshal([]byte(content), &workload)
+		return &workload.Spec.Template.Spec, "spec.template.spec", err
+	case "CronJob":
+		var cronJob struct {
+			Spec struct {
+				JobTemplate struct {
+					Spec struct {
+						Template corev1.PodTemplateSpec `json:"template"`
+					} `json:"spec"`
+				} `json:"jobTemplate"`
+			} `json:"spec"`
+		}
+		err := yaml.Unmarshal([]byte(content), &cronJob)
+		return &cronJob.Spec.JobTemplate.Spec.Template.Spec, "spec.jobTemplate.spec.template.spec", err
+	}
+	return nil, "", nil
+}
+
+// isLongRunning reports whether the pods of a kind of workload are expected
+// to keep running.
+func isLongRunning(kind string) bool {
+	switch kind {
+	case "Deployment", "ReplicaSet", "StatefulSet", "DaemonSet", "ReplicationController":
+		return true
+	}
+	return false
+}
+
+// missingResources returns which of cpu and memory a list of resources lacks.
+func missingResources(resources corev1.ResourceList) []string {
+	var missing []string
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if _, ok := resources[name]; !ok {
+			missing = append(missing, string(name))
+		}
+	}
+	return missing
+}
+
+// imageTag returns the tag of an image reference. Images referenced by digest
+// are considered pinned, and have the tag "@".
+func imageTag(image string) string {
+	if strings.Contains(image, "@") {
+		return "@"
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
+
+// rbacFindings returns the rules of a role granting access with wildcards.
+func rbacFindings(rules []rbacv1.PolicyRule) []workloadFinding {
+	var findings []workloadFinding
+	for i, r := range rules {
+		var wildcards []string
+		if slices.Contains(r.APIGroups, "*") {
+			wildcards = append(wildcards, "apiGroups")
+		}
+		if slices.Contains(r.Resources, "*") {
+			wildcards = append(wildcards, "resources")
+		}
+		if slices.Contains(r.Verbs, "*") {
+			wildcards = append(wildcards, "verbs")
+		}
+		if len(wildcards) > 0 {
+			findings = append(findings, workloadFinding{
+				rule:     RuleRBACWildcard,
+				severity: support.WarningSev,
+				field:    fmt.Sprintf("rules[%d]", i),
+				err:      fmt.Errorf("rule %d grants access to all %s", i, strings.Join(wildcards, ", ")),
+			})
+		}
+	}
+	return findings
+}
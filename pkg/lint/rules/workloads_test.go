@@ -0,0 +1,69 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v4/pkg/lint/support"
+)
+
+func TestWorkloadRules(t *testing.T) {
+	linter := support.Linter{ChartDir: "testdata/workloads"}
+	TemplatesWithOptions(&linter, values, namespace, TemplateOptions{WorkloadRules: true})
+
+	var got []string
+	for _, msg := range linter.Messages {
+		got = append(got, fmt.Sprintf("%s %s %s", msg.RuleID, msg.Path, msg.Err))
+	}
+	assert.Equal(t, []string{
+		"image-tag templates/ignored.yaml:16 container \"job\" uses the image \"registry.example.com:5000/tools/job\" without a tag or digest",
+		"host-network templates/risky.yaml:14 the pod uses the network namespace of the host",
+		"host-pid templates/risky.yaml:15 the pod uses the process namespace of the host",
+		"host-path templates/risky.yaml:18 volume \"docker\" mounts the host path /var/run/docker.sock",
+		"privileged-container templates/risky.yaml:24 container \"web\" is privileged",
+		"run-as-root templates/risky.yaml:25 container \"web\" runs as root",
+		"resource-requests templates/risky.yaml:21 container \"web\" has no cpu and memory requests",
+		"resource-limits templates/risky.yaml:21 container \"web\" has no cpu and memory limits",
+		"image-tag templates/risky.yaml:22 container \"web\" uses the image \"nginx:latest\" with the latest tag",
+		"liveness-probe templates/risky.yaml:21 container \"web\" has no liveness probe",
+		"readiness-probe templates/risky.yaml:21 container \"web\" has no readiness probe",
+		"rbac-wildcard templates/role.yaml:9 rule 1 grants access to all apiGroups, resources",
+	}, got)
+	assert.Equal(t, support.ErrorSev, linter.HighestSeverity)
+
+	linter = support.Linter{ChartDir: "testdata/workloads"}
+	TemplatesWithOptions(&linter, values, namespace, TemplateOptions{})
+	assert.Empty(t, linter.Messages, "the workload rules must be opt-in")
+}
+
+func TestImageTag(t *testing.T) {
+	for image, tag := range map[string]string{
+		"nginx":                           "",
+		"nginx:1.27":                      "1.27",
+		"nginx:latest":                    "latest",
+		"localhost:5000/nginx":            "",
+		"localhost:5000/nginx:1.27":       "1.27",
+		"nginx@sha256:0123456789abcdef":   "@",
+		"nginx:1.27@sha256:0123456789abc": "@",
+	} {
+		assert.Equal(t, tag, imageTag(image), image)
+	}
+}
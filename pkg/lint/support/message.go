@@ -16,7 +16,12 @@ limitations under the License.
 
 package support
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
 
 // Severity indicates the severity of a Message.
 const (
@@ -33,6 +38,25 @@ const (
 // sev matches the *Sev states.
 var sev = []string{"UNKNOWN", "INFO", "WARNING", "ERROR"}
 
+// ParseSeverity returns the severity with the given name, e.g. "warning",
+// ignoring case.
+func ParseSeverity(name string) (int, error) {
+	for i, s := range sev {
+		if i != UnknownSev && strings.EqualFold(s, name) {
+			return i, nil
+		}
+	}
+	return UnknownSev, fmt.Errorf("invalid severity %q: must be one of info, warning or error", name)
+}
+
+// SeverityName returns the lowercase name of a severity, e.g. "warning".
+func SeverityName(severity int) string {
+	if severity < 0 || severity >= len(sev) {
+		return strings.ToLower(sev[UnknownSev])
+	}
+	return strings.ToLower(sev[severity])
+}
+
 // Linter encapsulates a linting run of a particular chart.
 type Linter struct {
 	Messages []Message
@@ -47,6 +71,9 @@ type Message struct {
 	Severity int
 	Path     string
 	Err      error
+	// RuleID is the stable identifier of the lint rule that raised the
+	// message, e.g. "icon-missing". It is empty when the rule has none.
+	RuleID string
 }
 
 func (m Message) Error() string {
@@ -58,15 +85,39 @@ func NewMessage(severity int, path string, err error) Message {
 	return Message{Severity: severity, Path: path, Err: err}
 }
 
+// pathLine matches the line and column of a path, e.g.
+// "templates/service.yaml:12" or "templates/service.yaml:12:5".
+var pathLine = regexp.MustCompile(`^(.+?):(\d+)(?::(\d+))?$`)
+
+// Location returns the file of the message, and the line and column in that
+// file when they are known, or 0.
+func (m Message) Location() (file string, line, column int) {
+	sm := pathLine.FindStringSubmatch(m.Path)
+	if sm == nil {
+		return m.Path, 0, 0
+	}
+	line, _ = strconv.Atoi(sm[2])
+	if sm[3] != "" {
+		column, _ = strconv.Atoi(sm[3])
+	}
+	return sm[1], line, column
+}
+
 // RunLinterRule returns true if the validation passed
 func (l *Linter) RunLinterRule(severity int, path string, err error) bool {
+	return l.RunLinterRuleWithID("", severity, path, err)
+}
+
+// RunLinterRuleWithID returns true if the validation passed. The messages of
+// a failed validation carry the given rule identifier.
+func (l *Linter) RunLinterRuleWithID(ruleID string, severity int, path string, err error) bool {
 	// severity is out of bound
 	if severity < 0 || severity >= len(sev) {
 		return false
 	}
 
 	if err != nil {
-		l.Messages = append(l.Messages, NewMessage(severity, path, err))
+		l.Messages = append(l.Messages, Message{Severity: severity, Path: path, Err: err, RuleID: ruleID})
 
 		if severity > l.HighestSeverity {
 			l.HighestSeverity = severity
@@ -62,18 +62,67 @@ func TestRunLinterRule(t *testing.T) {
 }
 
 func TestMessage(t *testing.T) {
-	m := Message{ErrorSev, "Chart.yaml", errors.New("Foo")}
+	m := Message{Severity: ErrorSev, Path: "Chart.yaml", Err: errors.New("Foo")}
 	if m.Error() != "[ERROR] Chart.yaml: Foo" {
 		t.Errorf("Unexpected output: %s", m.Error())
 	}
 
-	m = Message{WarningSev, "templates/", errors.New("Bar")}
+	m = Message{Severity: WarningSev, Path: "templates/", Err: errors.New("Bar")}
 	if m.Error() != "[WARNING] templates/: Bar" {
 		t.Errorf("Unexpected output: %s", m.Error())
 	}
 
-	m = Message{InfoSev, "templates/rc.yaml", errors.New("FooBar")}
+	m = Message{Severity: InfoSev, Path: "templates/rc.yaml", Err: errors.New("FooBar")}
 	if m.Error() != "[INFO] templates/rc.yaml: FooBar" {
 		t.Errorf("Unexpected output: %s", m.Error())
 	}
 }
+
+func TestParseSeverity(t *testing.T) {
+	for name, want := range map[string]int{"info": InfoSev, "WARNING": WarningSev, "Error": ErrorSev} {
+		got, err := ParseSeverity(name)
+		if err != nil {
+			t.Errorf("ParseSeverity(%q) failed: %s", name, err)
+		}
+		if got != want {
+			t.Errorf("ParseSeverity(%q) = %d, want %d", name, got, want)
+		}
+	}
+	for _, name := range []string{"", "unknown", "fatal"} {
+		if _, err := ParseSeverity(name); err == nil {
+			t.Errorf("ParseSeverity(%q) should fail", name)
+		}
+	}
+}
+
+func TestMessageLocation(t *testing.T) {
+	tests := []struct {
+		path         string
+		file         string
+		line, column int
+	}{
+		{"Chart.yaml", "Chart.yaml", 0, 0},
+		{"templates/", "templates/", 0, 0},
+		{"templates/service.yaml:12", "templates/service.yaml", 12, 0},
+		{"templates/service.yaml:12:5", "templates/service.yaml", 12, 5},
+		{"/tmp/chart", "/tmp/chart", 0, 0},
+	}
+	for _, tt := range tests {
+		file, line, column := Message{Path: tt.path}.Location()
+		if file != tt.file || line != tt.line || column != tt.column {
+			t.Errorf("Location() of %q = %q, %d, %d, want %q, %d, %d", tt.path, file, line, column, tt.file, tt.line, tt.column)
+		}
+	}
+}
+
+func TestRunLinterRuleWithID(t *testing.T) {
+	linter := Linter{}
+	linter.RunLinterRuleWithID("icon-missing", InfoSev, "Chart.yaml", errors.New("icon is recommended"))
+	linter.RunLinterRuleWithID("icon-url", ErrorSev, "Chart.yaml", nil)
+	if len(linter.Messages) != 1 || linter.Messages[0].RuleID != "icon-missing" {
+		t.Errorf("unexpected messages: %v", linter.Messages)
+	}
+	if SeverityName(linter.Messages[0].Severity) != "info" {
+		t.Errorf("unexpected severity name %q", SeverityName(linter.Messages[0].Severity))
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Document is a YAML document of a stream of manifests.
+type Document struct {
+	// Line is the line of the stream the document starts at, starting at 1.
+	Line int
+	// Content is the document, without its separator.
+	Content string
+}
+
+// SplitDocuments splits a stream of YAML documents on the "---" separator
+// lines the way the Kubernetes YAML reader does, keeping the line each
+// document starts at. Empty documents are skipped.
+func SplitDocuments(stream string) []Document {
+	var docs []Document
+	var b strings.Builder
+	start := 1
+	flush := func() {
+		if b.Len() > 0 {
+			docs = append(docs, Document{Line: start, Content: b.String()})
+			b.Reset()
+		}
+	}
+	lines := strings.SplitAfter(stream, "\n")
+	for i, line := range lines {
+		if isSeparator(line) {
+			flush()
+			start = i + 2
+			continue
+		}
+		b.WriteString(line)
+	}
+	flush()
+	return docs
+}
+
+// isSeparator reports whether a line separates two documents: "---" followed
+// only by spaces or a comment, as the Kubernetes YAML reader requires.
+func isSeparator(line string) bool {
+	after, ok := strings.CutPrefix(line, "---")
+	if !ok {
+		return false
+	}
+	after = strings.TrimSpace(after)
+	return after == "" || strings.HasPrefix(after, "#")
+}
+
+var fieldIndex = regexp.MustCompile(`^(.*)\[(\d+)\]$`)
+
+// FieldLine returns the line of a field of a YAML document, starting at 1,
+// given its path, e.g. "spec.template.spec.containers[0].image". When the
+// field is missing, the line of the closest parent is returned, and 0 when the
+// document cannot be parsed.
+func FieldLine(doc, path string) int {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(doc), &root); err != nil || len(root.Content) == 0 {
+		return 0
+	}
+	node := root.Content[0]
+	line := node.Line
+	segments := strings.Split(path, ".")
+	for len(segments) > 0 && node != nil {
+		var next *yaml.Node
+		var used int
+		if node.Kind == yaml.MappingNode {
+			// Keys may contain dots, e.g. annotations, prefer the longest key.
+			for n := len(segments); n > 0 && next == nil; n-- {
+				key := strings.Join(segments[:n], ".")
+				index := -1
+				if m := fieldIndex.FindStringSubmatch(key); m != nil {
+					key = m[1]
+					index, _ = strconv.Atoi(m[2])
+				}
+				for i := 0; i+1 < len(node.Content); i += 2 {
+					if node.Content[i].Value != key {
+						continue
+					}
+					next, used = node.Content[i+1], n
+					line = node.Content[i].Line
+					if index >= 0 && next.Kind == yaml.SequenceNode && index < len(next.Content) {
+						next = next.Content[index]
+						line = next.Line
+					}
+					break
+				}
+			}
+		}
+		if next == nil {
+			break
+		}
+		node, segments = next, segments[used:]
+	}
+	return line
+}
@@ -0,0 +1,55 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util // import "helm.sh/helm/v4/pkg/release/util"
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitDocuments(t *testing.T) {
+	docs := SplitDocuments(mockManifestFile + "--- # second\nkind: ConfigMap\n---foo: bar\n---\n")
+	expected := []Document{
+		{Line: 1, Content: "\n\n"},
+		{Line: 4, Content: mockManifestFile[6:]},
+		{Line: 16, Content: "kind: ConfigMap\n---foo: bar\n"},
+	}
+	if !reflect.DeepEqual(docs, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, docs)
+	}
+}
+
+func TestFieldLine(t *testing.T) {
+	doc := mockManifestFile[6:]
+	for path, line := range map[string]int{
+		"":                                  1,
+		"kind":                              2,
+		"metadata.name":                     4,
+		"metadata.annotations.helm.sh/hook": 6,
+		"spec.containers[0].image":          10,
+		"spec.containers[0].missing":        9,
+		"spec.containers[3].image":          8,
+		"status.phase":                      1,
+	} {
+		if got := FieldLine(doc, path); got != line {
+			t.Errorf("FieldLine(%q): expected %d, got %d", path, line, got)
+		}
+	}
+	if got := FieldLine("a: [", "a"); got != 0 {
+		t.Errorf("Expected 0 for an invalid document, got %d", got)
+	}
+}
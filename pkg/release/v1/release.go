@@ -36,6 +36,9 @@ type Release struct {
 	// Config is the set of extra Values added to the chart.
 	// These values override the default values inside of the chart.
 	Config map[string]interface{} `json:"config,omitempty"`
+	// ValuesFrom are the references to the Secrets and ConfigMaps part of
+	// Config was read from, e.g. "secret/db:values.yaml".
+	ValuesFrom []string `json:"values_from,omitempty"`
 	// Manifest is the string representation of the rendered template.
 	Manifest string `json:"manifest,omitempty"`
 	// Hooks are all of the hooks declared for this release.
@@ -50,6 +53,9 @@ type Release struct {
 	// ApplyMethod stores whether server-side or client-side apply was used for the release
 	// Unset (empty string) should be treated as the default of client-side apply
 	ApplyMethod string `json:"apply_method,omitempty"` // "ssa" | "csa"
+	// ChartAPIVersion is the apiVersion of the chart that was released.
+	// Unset (empty string) for releases recorded before it was stored.
+	ChartAPIVersion string `json:"chart_api_version,omitempty"`
 }
 
 // SetStatus is a helper for setting the status on a release.
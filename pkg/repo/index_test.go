@@ -28,8 +28,11 @@ import (
 	"strings"
 	"testing"
 
+	"helm.sh/helm/v4/pkg/chart/loader"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/gates"
 	"helm.sh/helm/v4/pkg/getter"
 	"helm.sh/helm/v4/pkg/helmpath"
 )
@@ -508,6 +511,30 @@ func TestIndexDirectory(t *testing.T) {
 	}
 }
 
+func TestIndexDirectoryV3(t *testing.T) {
+	t.Setenv(string(gates.ChartV3), "1")
+	c, err := loader.Load("../chart/loader/testdata/albatross")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if _, err := chartutil.Save(c, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := IndexDirectory(dir, "http://localhost:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	albatross, ok := index.Entries["albatross"]
+	if !ok {
+		t.Fatal("Could not read chart albatross")
+	}
+	if v := albatross[0].APIVersion; v != "v3" {
+		t.Errorf("Expected apiVersion v3, got %q", v)
+	}
+}
+
 func TestIndexAdd(t *testing.T) {
 	i := NewIndexFile()
 
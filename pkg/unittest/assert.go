@@ -0,0 +1,403 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Assertion is an assertion on the templates rendered by a test. Exactly one
+// of the kinds of assertion must be set.
+type Assertion struct {
+	// Template restricts the assertion to one of the templates of the test.
+	Template string `json:"template,omitempty"`
+	// DocumentIndex restricts the assertion to one of the documents rendered
+	// from the templates, starting at 0.
+	DocumentIndex *int `json:"documentIndex,omitempty"`
+	// Not negates the assertion.
+	Not bool `json:"not,omitempty"`
+
+	// Equal asserts that the value at a path of the documents is equal to
+	// the given value.
+	Equal *PathValue `json:"equal,omitempty"`
+	// MatchRegex asserts that the string at a path of the documents matches
+	// the given regular expression.
+	MatchRegex *PathPattern `json:"matchRegex,omitempty"`
+	// HasDocuments asserts on the number of rendered documents. A count of
+	// 0 asserts that the templates render nothing.
+	HasDocuments *DocumentCount `json:"hasDocuments,omitempty"`
+	// IsKind asserts on the kind of the documents.
+	IsKind *TypeOf `json:"isKind,omitempty"`
+	// IsAPIVersion asserts on the apiVersion of the documents.
+	IsAPIVersion *TypeOf `json:"isAPIVersion,omitempty"`
+	// FailedTemplate asserts that rendering fails with the given message.
+	FailedTemplate *FailedTemplate `json:"failedTemplate,omitempty"`
+}
+
+// PathValue is a value expected at a path, e.g. "spec.containers[0].image"
+// or 'metadata.labels["app.kubernetes.io/name"]'.
+type PathValue struct {
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// PathPattern is a regular expression the string at a path is expected to
+// match.
+type PathPattern struct {
+	Path    string `json:"path"`
+	Pattern string `json:"pattern"`
+}
+
+// DocumentCount is the expected number of rendered documents.
+type DocumentCount struct {
+	Count int `json:"count"`
+}
+
+// TypeOf is the expected kind or apiVersion of the documents.
+type TypeOf struct {
+	Of string `json:"of"`
+}
+
+// FailedTemplate is the error rendering the templates of the test is expected
+// to fail with. The error must contain ErrorMessage, or match ErrorPattern.
+type FailedTemplate struct {
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	ErrorPattern string `json:"errorPattern,omitempty"`
+}
+
+// document is a document rendered from a template.
+type document struct {
+	template string
+	content  interface{}
+}
+
+func (a *Assertion) validate() error {
+	var kinds []string
+	if a.Equal != nil {
+		kinds = append(kinds, "equal")
+		if a.Equal.Path == "" {
+			return errors.New("equal: path is required")
+		}
+		if _, err := parsePath(a.Equal.Path); err != nil {
+			return fmt.Errorf("equal: %w", err)
+		}
+	}
+	if a.MatchRegex != nil {
+		kinds = append(kinds, "matchRegex")
+		if a.MatchRegex.Path == "" {
+			return errors.New("matchRegex: path is required")
+		}
+		if _, err := parsePath(a.MatchRegex.Path); err != nil {
+			return fmt.Errorf("matchRegex: %w", err)
+		}
+		if _, err := regexp.Compile(a.MatchRegex.Pattern); err != nil {
+			return fmt.Errorf("matchRegex: %w", err)
+		}
+	}
+	if a.HasDocuments != nil {
+		kinds = append(kinds, "hasDocuments")
+		if a.HasDocuments.Count < 0 {
+			return errors.New("hasDocuments: count must not be negative")
+		}
+	}
+	if a.IsKind != nil {
+		kinds = append(kinds, "isKind")
+		if a.IsKind.Of == "" {
+			return errors.New("isKind: of is required")
+		}
+	}
+	if a.IsAPIVersion != nil {
+		kinds = append(kinds, "isAPIVersion")
+		if a.IsAPIVersion.Of == "" {
+			return errors.New("isAPIVersion: of is required")
+		}
+	}
+	if a.FailedTemplate != nil {
+		kinds = append(kinds, "failedTemplate")
+		if _, err := regexp.Compile(a.FailedTemplate.ErrorPattern); err != nil {
+			return fmt.Errorf("failedTemplate: %w", err)
+		}
+	}
+	switch len(kinds) {
+	case 0:
+		return errors.New("no assertion")
+	case 1:
+		return nil
+	default:
+		return fmt.Errorf("more than one assertion: %s", strings.Join(kinds, ", "))
+	}
+}
+
+// kind returns the name of the kind of assertion.
+func (a *Assertion) kind() string {
+	switch {
+	case a.Equal != nil:
+		return "equal"
+	case a.MatchRegex != nil:
+		return "matchRegex"
+	case a.HasDocuments != nil:
+		return "hasDocuments"
+	case a.IsKind != nil:
+		return "isKind"
+	case a.IsAPIVersion != nil:
+		return "isAPIVersion"
+	default:
+		return "failedTemplate"
+	}
+}
+
+// checkError asserts on the error rendering failed with, or on the absence of
+// one.
+func (a *Assertion) checkError(renderErr error) error {
+	if renderErr == nil {
+		if a.Not {
+			return nil
+		}
+		return errors.New("expected rendering to fail, but it succeeded")
+	}
+	msg := renderErr.Error()
+	matched := strings.Contains(msg, a.FailedTemplate.ErrorMessage)
+	if a.FailedTemplate.ErrorPattern != "" {
+		matched = matched && regexp.MustCompile(a.FailedTemplate.ErrorPattern).MatchString(msg)
+	}
+	switch {
+	case a.Not:
+		return fmt.Errorf("expected rendering not to fail, but it failed with: %s", msg)
+	case !matched:
+		return fmt.Errorf("expected rendering to fail with %q, but it failed with: %s", a.expectedError(), msg)
+	}
+	return nil
+}
+
+func (a *Assertion) expectedError() string {
+	if a.FailedTemplate.ErrorPattern != "" {
+		return a.FailedTemplate.ErrorPattern
+	}
+	return a.FailedTemplate.ErrorMessage
+}
+
+// check asserts on the documents rendered from the selected templates.
+func (a *Assertion) check(docs []document) error {
+	if a.DocumentIndex != nil {
+		i := *a.DocumentIndex
+		if i < 0 || i >= len(docs) {
+			return fmt.Errorf("document %d does not exist, %d document(s) rendered", i, len(docs))
+		}
+		docs = docs[i : i+1]
+	}
+
+	if a.HasDocuments != nil {
+		equal := len(docs) == a.HasDocuments.Count
+		if equal == a.Not {
+			return fmt.Errorf("expected %s%d document(s), got %d", negation(a.Not), a.HasDocuments.Count, len(docs))
+		}
+		return nil
+	}
+
+	if len(docs) == 0 {
+		return errors.New("no documents rendered")
+	}
+	for i, doc := range docs {
+		if err := a.checkDocument(doc); err != nil {
+			index := i
+			if a.DocumentIndex != nil {
+				index = *a.DocumentIndex
+			}
+			return fmt.Errorf("document %d of %s: %w", index, doc.template, err)
+		}
+	}
+	return nil
+}
+
+func (a *Assertion) checkDocument(doc document) error {
+	switch {
+	case a.Equal != nil:
+		actual, found := lookupPath(doc.content, a.Equal.Path)
+		equal := found && reflect.DeepEqual(normalize(a.Equal.Value), actual)
+		if equal == a.Not {
+			return fmt.Errorf("expected %s to %sequal %s, got %s", a.Equal.Path, negation(a.Not), format(a.Equal.Value), formatFound(actual, found))
+		}
+	case a.MatchRegex != nil:
+		actual, found := lookupPath(doc.content, a.MatchRegex.Path)
+		s, ok := actual.(string)
+		if !ok {
+			return fmt.Errorf("expected %s to be a string, got %s", a.MatchRegex.Path, formatFound(actual, found))
+		}
+		matched := regexp.MustCompile(a.MatchRegex.Pattern).MatchString(s)
+		if matched == a.Not {
+			return fmt.Errorf("expected %s to %smatch %q, got %q", a.MatchRegex.Path, negation(a.Not), a.MatchRegex.Pattern, s)
+		}
+	case a.IsKind != nil:
+		return checkField(doc, "kind", a.IsKind.Of, a.Not)
+	case a.IsAPIVersion != nil:
+		return checkField(doc, "apiVersion", a.IsAPIVersion.Of, a.Not)
+	}
+	return nil
+}
+
+func checkField(doc document, field, expected string, not bool) error {
+	actual, found := lookupPath(doc.content, field)
+	equal := found && actual == expected
+	if equal == not {
+		return fmt.Errorf("expected %s %sto be %q, got %s", field, negation(not), expected, formatFound(actual, found))
+	}
+	return nil
+}
+
+func negation(not bool) string {
+	if not {
+		return "not "
+	}
+	return ""
+}
+
+// normalize converts a value to the types documents are decoded to.
+func normalize(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+func format(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func formatFound(v interface{}, found bool) string {
+	if !found {
+		return "nothing"
+	}
+	return format(v)
+}
+
+// pathSegment is a key of a map, or an index of a list.
+type pathSegment struct {
+	key   string
+	index int
+	isKey bool
+}
+
+// parsePath parses a path like 'spec.containers[0].env' or
+// 'metadata.labels["app.kubernetes.io/name"]'.
+func parsePath(p string) ([]pathSegment, error) {
+	var segments []pathSegment
+	rest := p
+	for rest != "" {
+		switch {
+		case rest[0] == '.':
+			if len(segments) == 0 || len(rest) == 1 {
+				return nil, fmt.Errorf("invalid path %q", p)
+			}
+			rest = rest[1:]
+			if rest[0] == '.' || rest[0] == '[' {
+				return nil, fmt.Errorf("invalid path %q", p)
+			}
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: missing ]", p)
+			}
+			inner := rest[1:end]
+			if q, err := strconv.Unquote(inner); err == nil {
+				segments = append(segments, pathSegment{key: q, isKey: true})
+			} else if i, err := strconv.Atoi(inner); err == nil && i >= 0 {
+				segments = append(segments, pathSegment{index: i})
+			} else {
+				return nil, fmt.Errorf("invalid path %q: %q is neither an index nor a quoted key", p, inner)
+			}
+			rest = rest[end+1:]
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			segments = append(segments, pathSegment{key: rest[:end], isKey: true})
+			rest = rest[end:]
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid path %q", p)
+	}
+	return segments, nil
+}
+
+// lookupPath returns the value at a path of a document.
+func lookupPath(v interface{}, p string) (interface{}, bool) {
+	segments, err := parsePath(p)
+	if err != nil {
+		return nil, false
+	}
+	for _, s := range segments {
+		if s.isKey {
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if v, ok = m[s.key]; !ok {
+				return nil, false
+			}
+			continue
+		}
+		l, ok := v.([]interface{})
+		if !ok || s.index >= len(l) {
+			return nil, false
+		}
+		v = l[s.index]
+	}
+	return v, true
+}
+
+// setPath sets the value at a path of keys, e.g. "image.tag", creating the
+// maps on the way.
+func setPath(values map[string]interface{}, p string, value interface{}) error {
+	segments, err := parsePath(p)
+	if err != nil {
+		return err
+	}
+	m := values
+	for i, s := range segments {
+		if !s.isKey {
+			return fmt.Errorf("cannot set %q: indexes are not supported", p)
+		}
+		if i == len(segments)-1 {
+			m[s.key] = value
+			return nil
+		}
+		next, ok := m[s.key].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[s.key] = next
+		}
+		m = next
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupPath(t *testing.T) {
+	doc := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{"app.kubernetes.io/name": "web"},
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"image": "nginx"},
+			},
+		},
+	}
+	tests := []struct {
+		path  string
+		value interface{}
+		found bool
+	}{
+		{`metadata.labels["app.kubernetes.io/name"]`, "web", true},
+		{`spec.containers[0].image`, "nginx", true},
+		{`spec.containers[1].image`, nil, false},
+		{`spec.containers.image`, nil, false},
+		{`metadata.missing`, nil, false},
+		{`spec..containers`, nil, false},
+	}
+	for _, tt := range tests {
+		value, found := lookupPath(doc, tt.path)
+		assert.Equal(t, tt.found, found, tt.path)
+		assert.Equal(t, tt.value, value, tt.path)
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	values := map[string]interface{}{"image": "nginx"}
+	require.NoError(t, setPath(values, "image.tag", "1.27"))
+	require.NoError(t, setPath(values, `labels["app.kubernetes.io/name"]`, "web"))
+	assert.Equal(t, map[string]interface{}{
+		"image":  map[string]interface{}{"tag": "1.27"},
+		"labels": map[string]interface{}{"app.kubernetes.io/name": "web"},
+	}, values)
+
+	assert.ErrorContains(t, setPath(values, "list[0]", 1), "indexes are not supported")
+}
+
+func TestValidateAssertion(t *testing.T) {
+	tests := []struct {
+		assertion Assertion
+		err       string
+	}{
+		{Assertion{}, "no assertion"},
+		{Assertion{IsKind: &TypeOf{Of: "Pod"}, IsAPIVersion: &TypeOf{Of: "v1"}}, "more than one assertion: isKind, isAPIVersion"},
+		{Assertion{Equal: &PathValue{}}, "equal: path is required"},
+		{Assertion{Equal: &PathValue{Path: "a[x]"}}, `"x" is neither an index nor a quoted key`},
+		{Assertion{MatchRegex: &PathPattern{Path: "a", Pattern: "("}}, "matchRegex: error parsing regexp"},
+		{Assertion{HasDocuments: &DocumentCount{Count: -1}}, "count must not be negative"},
+		{Assertion{IsKind: &TypeOf{}}, "isKind: of is required"},
+		{Assertion{FailedTemplate: &FailedTemplate{}}, ""},
+	}
+	for _, tt := range tests {
+		err := tt.assertion.validate()
+		if tt.err == "" {
+			assert.NoError(t, err)
+			continue
+		}
+		assert.ErrorContains(t, err, tt.err)
+	}
+}
+
+func TestLoadSuite(t *testing.T) {
+	s, err := LoadSuite("testdata/chart/tests/deployment_test.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "deployment", s.Name)
+	require.Len(t, s.Tests, 1)
+	assert.Equal(t, "metadata.name", s.Tests[0].Asserts[0].Equal.Path)
+}
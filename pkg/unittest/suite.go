@@ -0,0 +1,155 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// TestsDir is the directory of a chart the test suites are stored in.
+const TestsDir = "tests"
+
+// SuiteFileSuffix is the suffix of the names of test suite files.
+const SuiteFileSuffix = "_test.yaml"
+
+// Suite is a set of tests of the templates of a chart, stored in a file of
+// its tests/ directory.
+type Suite struct {
+	// Name is the name of the suite. It defaults to the name of its file.
+	Name string `json:"suite,omitempty"`
+	// Templates are the templates, e.g. "templates/deployment.yaml", the
+	// tests assert on. They default to all templates of the chart.
+	Templates []string `json:"templates,omitempty"`
+	// Values are values files, relative to the suite file, applied to all
+	// tests of the suite.
+	Values []string `json:"values,omitempty"`
+	// Set are values applied to all tests of the suite, keyed by their path,
+	// e.g. "image.tag".
+	Set map[string]interface{} `json:"set,omitempty"`
+	// Release is the release the templates are rendered for.
+	Release Release `json:"release,omitempty"`
+	// Capabilities are the capabilities the templates are rendered with.
+	Capabilities Capabilities `json:"capabilities,omitempty"`
+	// Tests are the tests of the suite.
+	Tests []*Test `json:"tests"`
+
+	// File is the path of the suite file.
+	File string `json:"-"`
+}
+
+// Release is the release info the templates of a suite are rendered for.
+type Release struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Revision  int    `json:"revision,omitempty"`
+	Upgrade   bool   `json:"upgrade,omitempty"`
+}
+
+// Capabilities are the capabilities the templates of a suite are rendered
+// with, in addition to the default ones.
+type Capabilities struct {
+	KubeVersion string   `json:"kubeVersion,omitempty"`
+	APIVersions []string `json:"apiVersions,omitempty"`
+}
+
+// Test renders the templates of a suite and asserts on the output.
+type Test struct {
+	// Name describes what is tested.
+	Name string `json:"it"`
+	// Template restricts the test to one of the templates of the suite.
+	Template string `json:"template,omitempty"`
+	// Values are values files, relative to the suite file, applied after
+	// those of the suite.
+	Values []string `json:"values,omitempty"`
+	// Set are values applied after those of the suite.
+	Set map[string]interface{} `json:"set,omitempty"`
+	// Asserts are the assertions on the rendered templates.
+	Asserts []*Assertion `json:"asserts"`
+}
+
+// LoadSuite loads a test suite from a file.
+func LoadSuite(filename string) (*Suite, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	s := &Suite{}
+	if err := yaml.UnmarshalStrict(data, s); err != nil {
+		return nil, fmt.Errorf("cannot parse test suite %s: %w", filename, err)
+	}
+	s.File = filename
+	if s.Name == "" {
+		s.Name = strings.TrimSuffix(filepath.Base(filename), SuiteFileSuffix)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid test suite %s: %w", filename, err)
+	}
+	return s, nil
+}
+
+// Validate checks that the suite and its tests are well formed.
+func (s *Suite) Validate() error {
+	if len(s.Tests) == 0 {
+		return errors.New("no tests")
+	}
+	for i, t := range s.Tests {
+		if t == nil {
+			return fmt.Errorf("tests[%d]: empty test", i)
+		}
+		if t.Name == "" {
+			return fmt.Errorf("tests[%d]: 'it' is required", i)
+		}
+		if len(t.Asserts) == 0 {
+			return fmt.Errorf("test %q: no asserts", t.Name)
+		}
+		for j, a := range t.Asserts {
+			if err := a.validate(); err != nil {
+				return fmt.Errorf("test %q: asserts[%d]: %w", t.Name, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+// FindSuites returns the test suite files in the tests/ directory of a chart,
+// in lexical order.
+func FindSuites(chartDir string) ([]string, error) {
+	var files []string
+	root := filepath.Join(chartDir, TestsDir)
+	err := filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && name == root {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(name, SuiteFileSuffix) {
+			files = append(files, name)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
@@ -0,0 +1,344 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package unittest runs the unit test suites stored in the tests/ directory of
+a chart.
+
+A suite renders templates of the chart with values, capabilities and release
+info, and asserts on the rendered documents:
+
+	suite: deployment
+	templates:
+	  - templates/deployment.yaml
+	set:
+	  replicaCount: 3
+	tests:
+	  - it: sets the number of replicas
+	    asserts:
+	      - isKind:
+	          of: Deployment
+	      - equal:
+	          path: spec.replicas
+	          value: 3
+*/
+package unittest // import "helm.sh/helm/v4/pkg/unittest"
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chart/loader"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	v2loader "helm.sh/helm/v4/pkg/chart/v2/loader"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/engine"
+	releaseutil "helm.sh/helm/v4/pkg/release/util"
+)
+
+// SuiteResult is the result of running a test suite.
+type SuiteResult struct {
+	// Name is the name of the suite.
+	Name string
+	// File is the path of the suite file.
+	File string
+	// Err is set when the suite or the chart could not be loaded.
+	Err error
+	// Tests are the results of the tests of the suite.
+	Tests []TestResult
+	// Duration is how long running the suite took.
+	Duration time.Duration
+}
+
+// Failed reports whether the suite could not be loaded, or any of its tests
+// failed.
+func (r *SuiteResult) Failed() bool {
+	if r.Err != nil {
+		return true
+	}
+	for _, t := range r.Tests {
+		if t.Failed() {
+			return true
+		}
+	}
+	return false
+}
+
+// TestResult is the result of running a test.
+type TestResult struct {
+	// Name is the name of the test.
+	Name string
+	// Failures describe the assertions that failed.
+	Failures []string
+	// Duration is how long running the test took.
+	Duration time.Duration
+}
+
+// Failed reports whether any assertion of the test failed.
+func (r TestResult) Failed() bool {
+	return len(r.Failures) > 0
+}
+
+// Runner runs test suites against the templates of a chart directory.
+type Runner struct {
+	// ChartDir is the directory of the chart.
+	ChartDir string
+	// KubeVersion is the Kubernetes version the templates are rendered for,
+	// unless a suite sets one.
+	KubeVersion *chartutil.KubeVersion
+
+	// cache keeps the parse trees of the templates for the next tests, which
+	// render the same chart.
+	cache *engine.ParseCache
+}
+
+// RunFile loads a test suite from a file and runs it.
+func (r *Runner) RunFile(filename string) *SuiteResult {
+	s, err := LoadSuite(filename)
+	if err != nil {
+		return &SuiteResult{
+			Name: strings.TrimSuffix(filepath.Base(filename), SuiteFileSuffix),
+			File: filename,
+			Err:  err,
+		}
+	}
+	return r.Run(s)
+}
+
+// Run runs a test suite.
+func (r *Runner) Run(s *Suite) *SuiteResult {
+	start := time.Now()
+	result := &SuiteResult{Name: s.Name, File: s.File}
+	// The files of the chart are read once for the suite. Rendering
+	// processes the dependencies of the chart in place, so each test builds
+	// its own chart from them.
+	files, err := v2loader.LoadDirFiles(r.ChartDir)
+	if err != nil {
+		result.Err = err
+		result.Duration = time.Since(start)
+		return result
+	}
+	for _, t := range s.Tests {
+		result.Tests = append(result.Tests, r.runTest(s, t, files))
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+func (r *Runner) runTest(s *Suite, t *Test, files []*v2loader.BufferedFile) TestResult {
+	start := time.Now()
+	result := TestResult{Name: t.Name}
+	fail := func(format string, args ...interface{}) {
+		result.Failures = append(result.Failures, fmt.Sprintf(format, args...))
+	}
+
+	rendered, chartName, renderErr := r.render(s, t, files)
+
+	if renderErr != nil && !slices.ContainsFunc(t.Asserts, func(a *Assertion) bool { return a.FailedTemplate != nil }) {
+		fail("rendering failed: %s", renderErr)
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	for i, a := range t.Asserts {
+		if a.FailedTemplate != nil {
+			if err := a.checkError(renderErr); err != nil {
+				fail("asserts[%d] %s: %s", i, a.kind(), err)
+			}
+			continue
+		}
+		if renderErr != nil {
+			fail("asserts[%d] %s: rendering failed: %s", i, a.kind(), renderErr)
+			continue
+		}
+		docs, err := documents(rendered, chartName, selectTemplates(s, t, a))
+		if err != nil {
+			fail("asserts[%d] %s: %s", i, a.kind(), err)
+			continue
+		}
+		if err := a.check(docs); err != nil {
+			fail("asserts[%d] %s: %s", i, a.kind(), err)
+		}
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// render renders the templates of the chart loaded from its files for a
+// test, returning the rendered templates, keyed by their path in the chart,
+// and the name of the chart.
+//
+// Only the templates the assertions of the test apply to are rendered, along
+// with the partials they may include, so that the other templates neither
+// fail the test nor the failedTemplate assertions.
+func (r *Runner) render(s *Suite, t *Test, files []*v2loader.BufferedFile) (map[string]string, string, error) {
+	chrt, err := loader.LoadFiles(files)
+	if err != nil {
+		return nil, "", err
+	}
+
+	vals := map[string]interface{}{}
+	for _, f := range append(slices.Clone(s.Values), t.Values...) {
+		if !filepath.IsAbs(f) {
+			f = filepath.Join(filepath.Dir(s.File), f)
+		}
+		fileVals, err := chartutil.ReadValuesFile(f)
+		if err != nil {
+			return nil, "", fmt.Errorf("cannot read values file %s: %w", f, err)
+		}
+		vals = v2loader.MergeMaps(vals, fileVals)
+	}
+	for _, set := range []map[string]interface{}{s.Set, t.Set} {
+		for _, k := range slices.Sorted(maps.Keys(set)) {
+			if err := setPath(vals, k, set[k]); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	caps := chartutil.DefaultCapabilities.Copy()
+	if r.KubeVersion != nil {
+		caps.KubeVersion = *r.KubeVersion
+	}
+	if s.Capabilities.KubeVersion != "" {
+		kubeVersion, err := chartutil.ParseKubeVersion(s.Capabilities.KubeVersion)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid kube version %q: %w", s.Capabilities.KubeVersion, err)
+		}
+		caps.KubeVersion = *kubeVersion
+	}
+	caps.APIVersions = append(slices.Clone(caps.APIVersions), s.Capabilities.APIVersions...)
+
+	options := chartutil.ReleaseOptions{
+		Name:      s.Release.Name,
+		Namespace: s.Release.Namespace,
+		Revision:  s.Release.Revision,
+		IsUpgrade: s.Release.Upgrade,
+		IsInstall: !s.Release.Upgrade,
+	}
+	if options.Name == "" {
+		options.Name = "release-name"
+	}
+	if options.Namespace == "" {
+		options.Namespace = "default"
+	}
+	if options.Revision == 0 {
+		options.Revision = 1
+	}
+
+	if err := chartutil.ProcessDependencies(chrt, vals); err != nil {
+		return nil, "", err
+	}
+	cvals, err := chartutil.CoalesceValues(chrt, vals)
+	if err != nil {
+		return nil, "", err
+	}
+	valuesToRender, err := chartutil.ToRenderValues(chrt, cvals, options, caps)
+	if err != nil {
+		return nil, "", err
+	}
+	if templates := testTemplates(s, t); templates != nil {
+		keep := map[string]bool{}
+		for _, tpl := range templates {
+			keep[path.Join(chrt.Name(), tpl)] = true
+		}
+		keepTemplates(chrt, keep)
+	}
+	if r.cache == nil {
+		r.cache = engine.NewParseCache()
+	}
+	rendered, err := engine.Engine{ParseCache: r.cache}.Render(chrt, valuesToRender)
+	return rendered, chrt.Name(), err
+}
+
+// selectTemplates returns the templates an assertion applies to: its own, the
+// one of its test, or those of its suite. None selects all templates.
+func selectTemplates(s *Suite, t *Test, a *Assertion) []string {
+	switch {
+	case a.Template != "":
+		return []string{a.Template}
+	case t.Template != "":
+		return []string{t.Template}
+	default:
+		return s.Templates
+	}
+}
+
+// testTemplates returns the templates the assertions of a test apply to, or
+// nil when one of them applies to all templates.
+func testTemplates(s *Suite, t *Test) []string {
+	var templates []string
+	for _, a := range t.Asserts {
+		selected := selectTemplates(s, t, a)
+		if len(selected) == 0 {
+			return nil
+		}
+		templates = append(templates, selected...)
+	}
+	return templates
+}
+
+// keepTemplates removes the templates of the chart and its dependencies
+// which are neither partials nor kept, by their path in the chart.
+func keepTemplates(c *chart.Chart, keep map[string]bool) {
+	c.Templates = slices.DeleteFunc(c.Templates, func(f *chart.File) bool {
+		return !strings.HasPrefix(path.Base(f.Name), "_") && !keep[path.Join(c.ChartFullPath(), f.Name)]
+	})
+	for _, dep := range c.Dependencies() {
+		keepTemplates(dep, keep)
+	}
+}
+
+// documents returns the documents rendered from the given templates.
+func documents(rendered map[string]string, chartName string, templates []string) ([]document, error) {
+	var names []string
+	if len(templates) == 0 {
+		for _, name := range slices.Sorted(maps.Keys(rendered)) {
+			if strings.HasSuffix(name, "NOTES.txt") {
+				continue
+			}
+			names = append(names, strings.TrimPrefix(name, chartName+"/"))
+		}
+	}
+	for _, tpl := range templates {
+		if _, ok := rendered[path.Join(chartName, tpl)]; !ok {
+			return nil, fmt.Errorf("template %s not found in chart %s", tpl, chartName)
+		}
+		names = append(names, tpl)
+	}
+
+	var docs []document
+	for _, name := range names {
+		for _, d := range releaseutil.SplitDocuments(rendered[path.Join(chartName, name)]) {
+			var content interface{}
+			if err := yaml.Unmarshal([]byte(d.Content), &content); err != nil {
+				return nil, fmt.Errorf("cannot parse document at line %d of %s: %w", d.Line, name, err)
+			}
+			if content == nil {
+				continue
+			}
+			docs = append(docs, document{template: name, content: content})
+		}
+	}
+	return docs, nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+const testChart = "testdata/chart"
+
+func TestFindSuites(t *testing.T) {
+	files, err := FindSuites(testChart)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(testChart, "tests", "deployment_test.yaml")}, files)
+
+	files, err = FindSuites(t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestRunFile(t *testing.T) {
+	r := &Runner{ChartDir: testChart}
+	result := r.RunFile(filepath.Join(testChart, "tests", "deployment_test.yaml"))
+	require.NoError(t, result.Err)
+	assert.Equal(t, "deployment", result.Name)
+	require.Len(t, result.Tests, 1)
+	assert.Empty(t, result.Tests[0].Failures)
+	assert.False(t, result.Failed())
+}
+
+func TestRun(t *testing.T) {
+	two := 2
+	kubeVersion, err := chartutil.ParseKubeVersion("v1.28.3")
+	require.NoError(t, err)
+
+	suite := &Suite{
+		Name:         "deployment",
+		File:         filepath.Join(testChart, "tests", "inline_test.yaml"),
+		Release:      Release{Name: "prod", Upgrade: true},
+		Capabilities: Capabilities{KubeVersion: "v1.31.0"},
+		Set:          map[string]interface{}{"replicas": 3},
+		Tests: []*Test{
+			{
+				Name: "uses the release, capabilities and values of the suite",
+				Asserts: []*Assertion{
+					{Equal: &PathValue{Path: "metadata.name", Value: "prod-web"}},
+					{Equal: &PathValue{Path: `metadata.labels["app.kubernetes.io/version"]`, Value: "v1.31.0"}},
+					{Equal: &PathValue{Path: "metadata.labels.upgrade", Value: "true"}},
+					{Equal: &PathValue{Path: "spec.replicas", Value: 3}},
+					{HasDocuments: &DocumentCount{Count: 1}},
+				},
+			},
+			{
+				Name: "sets values of the test after those of the suite",
+				Set:  map[string]interface{}{"replicas": 4, "name": "api"},
+				Asserts: []*Assertion{
+					{MatchRegex: &PathPattern{Path: "metadata.name", Pattern: "-api$"}},
+					{Equal: &PathValue{Path: "spec.replicas", Value: 4}},
+				},
+			},
+			{
+				Name: "fails",
+				Asserts: []*Assertion{
+					{Template: "templates/deployment.yaml", IsKind: &TypeOf{Of: "Service"}},
+					{IsAPIVersion: &TypeOf{Of: "apps/v1"}, Not: true},
+					{HasDocuments: &DocumentCount{Count: 1}, Not: true},
+					{Equal: &PathValue{Path: "spec.missing", Value: 1}},
+					{DocumentIndex: &two, IsKind: &TypeOf{Of: "Deployment"}},
+					{Template: "templates/missing.yaml", HasDocuments: &DocumentCount{Count: 0}},
+					{FailedTemplate: &FailedTemplate{ErrorMessage: "boom"}},
+				},
+			},
+		},
+	}
+	require.NoError(t, suite.Validate())
+
+	result := (&Runner{ChartDir: testChart, KubeVersion: kubeVersion}).Run(suite)
+	require.Len(t, result.Tests, 3)
+	assert.Empty(t, result.Tests[0].Failures)
+	assert.Empty(t, result.Tests[1].Failures)
+	assert.Equal(t, []string{
+		`asserts[0] isKind: document 0 of templates/deployment.yaml: expected kind to be "Service", got "Deployment"`,
+		`asserts[1] isAPIVersion: document 0 of templates/deployment.yaml: expected apiVersion not to be "apps/v1", got "apps/v1"`,
+		`asserts[2] hasDocuments: expected not 1 document(s), got 1`,
+		`asserts[3] equal: document 0 of templates/deployment.yaml: expected spec.missing to equal 1, got nothing`,
+		`asserts[4] isKind: document 2 does not exist, 1 document(s) rendered`,
+		`asserts[5] hasDocuments: template templates/missing.yaml not found in chart chart`,
+		`asserts[6] failedTemplate: expected rendering to fail, but it succeeded`,
+	}, result.Tests[2].Failures)
+	assert.True(t, result.Failed())
+}
+
+func TestRunFailedTemplate(t *testing.T) {
+	suite := &Suite{
+		Name: "failing",
+		File: filepath.Join(testChart, "tests", "inline_test.yaml"),
+		Tests: []*Test{
+			{
+				Name:    "fails to render",
+				Values:  []string{"missing.yaml"},
+				Asserts: []*Assertion{{FailedTemplate: &FailedTemplate{ErrorPattern: `missing\.yaml`}}},
+			},
+			{
+				Name:    "fails to render unexpectedly",
+				Values:  []string{"missing.yaml"},
+				Asserts: []*Assertion{{HasDocuments: &DocumentCount{Count: 1}}},
+			},
+		},
+	}
+	result := (&Runner{ChartDir: testChart}).Run(suite)
+	require.Len(t, result.Tests, 2)
+	assert.Empty(t, result.Tests[0].Failures)
+	require.Len(t, result.Tests[1].Failures, 1)
+	assert.Contains(t, result.Tests[1].Failures[0], "rendering failed: cannot read values file")
+}
+
+func TestRunSelectedTemplates(t *testing.T) {
+	suite := &Suite{
+		Name: "selected",
+		File: filepath.Join(testChart, "tests", "inline_test.yaml"),
+		Set:  map[string]interface{}{"configMap": map[string]interface{}{"enabled": true}},
+		Tests: []*Test{
+			{
+				Name:     "ignores the failing template",
+				Template: "templates/deployment.yaml",
+				Asserts:  []*Assertion{{IsKind: &TypeOf{Of: "Deployment"}}},
+			},
+			{
+				Name:     "ignores the error of the failing template",
+				Template: "templates/deployment.yaml",
+				Asserts:  []*Assertion{{FailedTemplate: &FailedTemplate{ErrorMessage: "configMap.data is required"}}},
+			},
+			{
+				Name: "fails with the selected template",
+				Asserts: []*Assertion{
+					{Template: "templates/configmap.yaml", FailedTemplate: &FailedTemplate{ErrorMessage: "configMap.data is required"}},
+				},
+			},
+			{
+				Name:    "fails with all templates",
+				Asserts: []*Assertion{{HasDocuments: &DocumentCount{Count: 1}}},
+			},
+		},
+	}
+	result := (&Runner{ChartDir: testChart}).Run(suite)
+	require.Len(t, result.Tests, 4)
+	assert.Empty(t, result.Tests[0].Failures)
+	assert.Equal(t, []string{"asserts[0] failedTemplate: expected rendering to fail, but it succeeded"}, result.Tests[1].Failures)
+	assert.Empty(t, result.Tests[2].Failures)
+	require.Len(t, result.Tests[3].Failures, 1)
+	assert.Contains(t, result.Tests[3].Failures[0], "configMap.data is required")
+}